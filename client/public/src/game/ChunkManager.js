@@ -47,47 +47,31 @@ export class ChunkManager {
     }
 
     /**
-     * Creates a Pixi sprite for an obstacle based on type.
-     * @param {Object} obsData - Obstacle data: {t: type, x, y}
+     * Creates a Pixi sprite for an obstacle.
+     * Sizes come from the server geometry registry (w, h) so rendering
+     * matches server-side collision.
+     * @param {Object} obsData - Obstacle data: {t: type, x, y, w, h}
      * @returns {PIXI.Graphics} The obstacle sprite
      */
     createObstacleSprite(obsData) {
         const sprite = new PIXI.Graphics();
 
-        // Different appearance based on obstacle type
-        switch (obsData.t) {
-            case 1: // Tall obstacle
-                sprite.beginFill(0xff003c); // Glitch Red
-                sprite.drawRect(0, 0, 40, 100);
-                sprite.endFill();
-                break;
-
-            case 2: // Low obstacle
-                sprite.beginFill(0xff003c); // Glitch Red
-                sprite.drawRect(0, 0, 60, 60);
-                sprite.endFill();
-                break;
-
-            case 3: // Spike obstacle
-                sprite.beginFill(0xff003c); // Glitch Red
-                sprite.drawRect(0, 0, 30, 80);
-                sprite.endFill();
-                break;
-
-            default:
-                // Unknown type - render as red box
-                sprite.beginFill(0xff003c);
-                sprite.drawRect(0, 0, 40, 80);
-                sprite.endFill();
-        }
+        // Fallback size for servers that don't send geometry
+        const width = obsData.w || 40;
+        const height = obsData.h || 80;
+
+        sprite.beginFill(0xff003c); // Glitch Red
+        sprite.drawRect(0, 0, width, height);
+        sprite.endFill();
 
         // Add cyan outline for neon effect
         sprite.lineStyle(2, 0x00f0ff); // Hyper-Cyan
-        sprite.drawRect(0, 0, sprite.width, sprite.height);
+        sprite.drawRect(0, 0, width, height);
 
         // Position obstacle at absolute world coordinates
-        // Y is measured from top, but obstacle needs to sit on ground (y=500)
-        sprite.position.set(obsData.x, obsData.y === 0 ? 500 - sprite.height : obsData.y);
+        // Y is the elevation above the ground surface (y=500), so the
+        // obstacle's bottom edge sits at 500 - y
+        sprite.position.set(obsData.x, 500 - obsData.y - height);
 
         return sprite;
     }
@@ -113,6 +97,17 @@ export class ChunkManager {
         return allObstacles;
     }
 
+    /**
+     * Drops chunks the server says the player has passed (chunk_unload).
+     * The server resends them if the player comes back.
+     * @param {number[]} chunkIDs - IDs of the chunks to drop
+     */
+    unloadChunks(chunkIDs) {
+        for (const chunkID of chunkIDs) {
+            this.removeChunk(chunkID);
+        }
+    }
+
     /**
      * Cleans up chunks that are far behind the camera.
      * @param {number} minX - Minimum X position to keep (usually camera X - buffer)
@@ -121,24 +116,37 @@ export class ChunkManager {
         const chunkSize = 5000;
         const minChunkID = Math.floor(minX / chunkSize) - 2; // Keep 2 chunks behind
 
-        for (const [chunkID, chunk] of this.chunks.entries()) {
+        for (const chunkID of [...this.chunks.keys()]) {
             if (chunkID < minChunkID) {
-                // Remove sprites from stage
-                for (const sprite of chunk.sprites) {
-                    this.app.stage.removeChild(sprite);
-
-                    // Remove from obstacleSprites array
-                    const index = this.obstacleSprites.indexOf(sprite);
-                    if (index > -1) {
-                        this.obstacleSprites.splice(index, 1);
-                    }
-                }
-
-                // Remove chunk from map
-                this.chunks.delete(chunkID);
-                console.log(`[ChunkManager] Cleaned up chunk ${chunkID}`);
+                this.removeChunk(chunkID);
+            }
+        }
+    }
+
+    /**
+     * Removes a chunk's sprites from the stage and forgets the chunk.
+     * @param {number} chunkID - ID of the chunk to remove (ignored if not loaded)
+     */
+    removeChunk(chunkID) {
+        const chunk = this.chunks.get(chunkID);
+        if (!chunk) {
+            return;
+        }
+
+        // Remove sprites from stage
+        for (const sprite of chunk.sprites) {
+            this.app.stage.removeChild(sprite);
+
+            // Remove from obstacleSprites array
+            const index = this.obstacleSprites.indexOf(sprite);
+            if (index > -1) {
+                this.obstacleSprites.splice(index, 1);
             }
         }
+
+        // Remove chunk from map
+        this.chunks.delete(chunkID);
+        console.log(`[ChunkManager] Cleaned up chunk ${chunkID}`);
     }
 
     /**
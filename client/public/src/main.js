@@ -126,6 +126,13 @@ function initializeNetwork() {
         }
     };
 
+    // Drop chunks the player has left behind
+    wsClient.onChunkUnload = (chunkIDs) => {
+        if (chunkManager) {
+            chunkManager.unloadChunks(chunkIDs);
+        }
+    };
+
     // Connect to server
     wsClient.connect();
     console.log('[Game] Connecting to server...');
@@ -1,15 +1,20 @@
 // WebSocketClient.js - Handles WebSocket connection to game server
 
+// Protocol version announced in the join message (see network-protocol.md)
+const PROTOCOL_VERSION = 1;
+
 /**
  * WebSocketClient manages the WebSocket connection to the Vibe Runner server.
  * It handles the join handshake, receives game state updates, and sends player actions.
  *
  * Message Protocol:
  * - All messages use format: { e: "event", d: data }
- * - Join: { e: "join", d: { n: playerName } }
+ * - Join: { e: "join", d: { n: playerName, v: protocolVersion } }
  * - Welcome: { e: "welcome", d: { id, seed, serverTime } }
- * - State: { e: "state", d: { t: timestamp, p: [players] } }
- * - Jump: { e: "jump", d: { t: timestamp } }
+ * - State: { e: "state", d: { t: timestamp, k: tick, a: lastAckedSeq, b: baselineTick, p: [players], s: [spawned], r: [despawnedIds] } }
+ * - Jump: { e: "jump", d: { t: timestamp, s: inputSeq } }
+ * - Ack: { e: "ack", d: { k: tick } } (received state, baseline for deltas)
+ * - Error: { e: "error", d: { c: code, e: event, m: message } } (rejected message)
  */
 export class WebSocketClient {
     /**
@@ -27,10 +32,20 @@ export class WebSocketClient {
         this.maxReconnectAttempts = 5;
         this.reconnectDelay = 1000; // ms
 
+        // Input sequencing for server reconciliation
+        this.inputSeq = 0; // Sequence number of the last input sent
+        this.lastAckedSeq = 0; // Last input the server has applied
+        this.serverTick = 0; // Tick number of the latest state update
+
+        // Recent full player lists by tick, the baselines of delta states
+        this.snapshots = new Map(); // tick -> Map(playerId -> { i, x, y })
+        this.maxSnapshots = 64;
+
         // Callbacks (set by main.js)
         this.onWelcome = null; // Called when welcome message received
         this.onStateUpdate = null; // Called when state message received
         this.onChunkReceived = null; // Called when chunk message received (Phase 4)
+        this.onChunkUnload = null; // Called with chunk IDs the player has passed
         this.onDisconnect = null; // Called when connection closes
     }
 
@@ -77,7 +92,8 @@ export class WebSocketClient {
         const joinMsg = {
             e: 'join',
             d: {
-                n: this.playerName
+                n: this.playerName,
+                v: PROTOCOL_VERSION
             }
         };
 
@@ -112,6 +128,12 @@ export class WebSocketClient {
                 case 'chunk':
                     this.handleChunk(message.d);
                     break;
+                case 'chunk_unload':
+                    this.handleChunkUnload(message.d);
+                    break;
+                case 'error':
+                    this.handleError(message.d);
+                    break;
                 default:
                     console.warn('[WebSocket] Unknown message type:', message.e);
             }
@@ -120,6 +142,17 @@ export class WebSocketClient {
         }
     }
 
+    /**
+     * Handles an error event from the server.
+     * Errors answering join are followed by the server closing the connection;
+     * any other error means one message was ignored.
+     *
+     * @param {Object} data - Error data { c: code, e: event, m: message }
+     */
+    handleError(data) {
+        console.error(`[WebSocket] Server error ${data.c}${data.e ? ` for ${data.e}` : ''}: ${data.m}`);
+    }
+
     /**
      * Handles the welcome message from the server.
      * This is received after a successful join.
@@ -144,13 +177,26 @@ export class WebSocketClient {
 
     /**
      * Handles state update messages from the server.
-     * These are sent at 20Hz and contain all player positions.
+     * These are sent at 20Hz. A full snapshot (no b) lists every player;
+     * a delta lists only changes against the snapshot of tick b.
+     * Every state is acknowledged so the server can send deltas against it.
      *
-     * @param {Object} data - State data { t: timestamp, p: [players] }
+     * @param {Object} data - State data { t: timestamp, k: tick, a: ack, b: baseline, p: [players], s: [spawned], r: [despawned] }
      */
     handleState(data) {
-        const timestamp = data.t;
-        const players = data.p;
+        const snapshot = this.applySnapshot(data);
+        if (!snapshot) {
+            // Baseline already discarded; wait for the next state
+            console.warn(`[WebSocket] Missing baseline ${data.b} for state ${data.k}`);
+            return;
+        }
+        this.send({ e: 'ack', d: { k: data.k } });
+
+        const players = Array.from(snapshot.values());
+
+        // Track the server tick and which of our inputs it has applied
+        this.serverTick = data.k;
+        this.lastAckedSeq = data.a;
 
         // Find this player's data
         const myPlayerData = players.find(p => p.i === this.playerId);
@@ -163,6 +209,43 @@ export class WebSocketClient {
         }
     }
 
+    /**
+     * Rebuilds the full player list of a state and remembers it as a baseline.
+     *
+     * @param {Object} data - State data (full snapshot or delta)
+     * @returns {Map|null} playerId -> { i, x, y }, or null if the baseline is unknown
+     */
+    applySnapshot(data) {
+        let snapshot;
+        if (data.b) {
+            const baseline = this.snapshots.get(data.b);
+            if (!baseline) {
+                return null;
+            }
+            snapshot = new Map(baseline);
+            for (const id of data.r || []) {
+                snapshot.delete(id);
+            }
+            for (const player of data.s || []) {
+                snapshot.set(player.i, player);
+            }
+        } else {
+            snapshot = new Map();
+        }
+        for (const player of data.p) {
+            snapshot.set(player.i, player);
+        }
+
+        this.snapshots.set(data.k, snapshot);
+        for (const tick of this.snapshots.keys()) {
+            if (this.snapshots.size <= this.maxSnapshots) {
+                break;
+            }
+            this.snapshots.delete(tick); // Oldest first (insertion order)
+        }
+        return snapshot;
+    }
+
     /**
      * Handles death message from the server.
      * Sent when the player collides with an obstacle.
@@ -190,6 +273,20 @@ export class WebSocketClient {
         }
     }
 
+    /**
+     * Handles chunk_unload messages from the server.
+     * Sent when the player has passed chunks, which can then be dropped.
+     *
+     * @param {Object} data - Unload data { ids: [chunkIDs] }
+     */
+    handleChunkUnload(data) {
+        console.log(`[WebSocket] Unloading chunks ${data.ids.join(', ')}`);
+
+        if (this.onChunkUnload) {
+            this.onChunkUnload(data.ids);
+        }
+    }
+
     /**
      * Handles WebSocket errors.
      *
@@ -251,10 +348,12 @@ export class WebSocketClient {
             return;
         }
 
+        this.inputSeq++;
         const jumpMsg = {
             e: 'jump',
             d: {
-                t: Date.now()
+                t: Date.now(),
+                s: this.inputSeq
             }
         };
 
@@ -0,0 +1,454 @@
+// Package config loads the server's settings from command-line flags,
+// environment variables and an optional YAML, JSON or TOML file.
+//
+// Later sources override earlier ones: defaults, then the config file, then
+// environment variables, then flags. The result is validated before use.
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"vibe-runner-server/game"
+	"vibe-runner-server/network"
+	"vibe-runner-server/room"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// EnvPrefix prefixes every environment variable the server reads.
+	EnvPrefix = "VIBE_RUNNER_"
+
+	// ConfigFileEnv names the config file when the -config flag is absent.
+	ConfigFileEnv = EnvPrefix + "CONFIG"
+
+	// DefaultPort is the HTTP listen port.
+	DefaultPort = 8080
+
+	// MaxChunksAhead is the largest allowed chunks-ahead window.
+	MaxChunksAhead = 10
+
+	// MaxSendBuffer is the largest allowed per-client send queue.
+	MaxSendBuffer = 1024
+
+	// DefaultLeaderboardPath is the leaderboard log, relative to the working directory.
+	DefaultLeaderboardPath = "leaderboard.log"
+)
+
+var (
+	// ErrInvalid is wrapped by every validation error.
+	ErrInvalid = errors.New("invalid config")
+
+	// ErrUnknownFormat is returned for config files that aren't .yaml, .yml, .json or .toml.
+	ErrUnknownFormat = errors.New("unknown config file format")
+)
+
+// Config holds every deployment-tunable server setting.
+// Keys in config files use the names in the struct tags.
+type Config struct {
+	// Port is the HTTP listen port.
+	Port int `json:"port" yaml:"port" toml:"port"`
+
+	// TickRate is the simulation frequency in Hz.
+	TickRate int `json:"tick_rate" yaml:"tick_rate" toml:"tick_rate"`
+
+	// PlayerSpeed is the horizontal player speed in pixels/second.
+	PlayerSpeed float64 `json:"player_speed" yaml:"player_speed" toml:"player_speed"`
+
+	// ChunksAhead is the number of chunks generated ahead of the leading player.
+	ChunksAhead int `json:"chunks_ahead" yaml:"chunks_ahead" toml:"chunks_ahead"`
+
+	// ChunkCleanupTicks is the interval, in ticks, between chunk cleanups.
+	ChunkCleanupTicks int `json:"chunk_cleanup_ticks" yaml:"chunk_cleanup_ticks" toml:"chunk_cleanup_ticks"`
+
+	// SendBuffer is the outgoing message queue size per client.
+	SendBuffer int `json:"send_buffer" yaml:"send_buffer" toml:"send_buffer"`
+
+	// InterestRadius is how far from its own player, in pixels, each client
+	// sees other players (0 sends everyone).
+	InterestRadius float64 `json:"interest_radius" yaml:"interest_radius" toml:"interest_radius"`
+
+	// PingIntervalMs is the time between heartbeat pings to each client in
+	// milliseconds (0 disables heartbeats).
+	PingIntervalMs int `json:"ping_interval_ms" yaml:"ping_interval_ms" toml:"ping_interval_ms"`
+
+	// MaxMissedPings is how many ping intervals a client may stay silent
+	// before it is disconnected.
+	MaxMissedPings int `json:"max_missed_pings" yaml:"max_missed_pings" toml:"max_missed_pings"`
+
+	// ResumeGraceMs is how long, in milliseconds, a disconnected client's
+	// player is held for the client to resume (0 removes it immediately).
+	ResumeGraceMs int `json:"resume_grace_ms" yaml:"resume_grace_ms" toml:"resume_grace_ms"`
+
+	// LeaderboardPath is the append-only log the leaderboard persists
+	// scores to. Relative paths are relative to the working directory.
+	LeaderboardPath string `json:"leaderboard_path" yaml:"leaderboard_path" toml:"leaderboard_path"`
+
+	// MinPlayers is the number of players a room needs to start a race.
+	MinPlayers int `json:"min_players" yaml:"min_players" toml:"min_players"`
+
+	// CountdownMs is the length of the countdown before each race in milliseconds.
+	CountdownMs int `json:"countdown_ms" yaml:"countdown_ms" toml:"countdown_ms"`
+
+	// ResultsMs is how long results are shown after each race in milliseconds.
+	ResultsMs int `json:"results_ms" yaml:"results_ms" toml:"results_ms"`
+
+	// FinishDistance is the race length in pixels (0 for endless races).
+	FinishDistance float64 `json:"finish_distance" yaml:"finish_distance" toml:"finish_distance"`
+
+	// RespawnMode is where dead players respawn: "frontier", "start" or "disabled".
+	RespawnMode string `json:"respawn_mode" yaml:"respawn_mode" toml:"respawn_mode"`
+}
+
+// Default returns the built-in settings the server used before it was configurable.
+//
+// Returns:
+//   - Config: Default configuration
+func Default() Config {
+	world := game.DefaultConfig()
+	return Config{
+		Port:              DefaultPort,
+		TickRate:          world.TickRate,
+		PlayerSpeed:       world.PlayerSpeed,
+		ChunksAhead:       world.ChunksAhead,
+		ChunkCleanupTicks: world.ChunkCleanupTicks,
+		SendBuffer:        network.DefaultSendBuffer,
+		InterestRadius:    network.DefaultInterestRadius,
+		PingIntervalMs:    int(network.DefaultPingInterval / time.Millisecond),
+		MaxMissedPings:    network.DefaultMaxMissedPings,
+		ResumeGraceMs:     int(network.DefaultResumeGrace / time.Millisecond),
+		LeaderboardPath:   DefaultLeaderboardPath,
+		MinPlayers:        world.MinPlayers,
+		CountdownMs:       int(world.Countdown / time.Millisecond),
+		ResultsMs:         int(world.Results / time.Millisecond),
+		FinishDistance:    world.FinishDistance,
+		RespawnMode:       world.Respawn.String(),
+	}
+}
+
+// setting describes one field's flag and environment variable.
+type setting struct {
+	// name is the flag name; the environment variable is derived from it.
+	name string
+
+	// usage is the flag's help text.
+	usage string
+
+	// set parses a string value into the field.
+	set func(c *Config, value string) error
+}
+
+// env returns the setting's environment variable, e.g. VIBE_RUNNER_TICK_RATE.
+func (s setting) env() string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(s.name, "-", "_"))
+}
+
+// settings lists every field settable by flag or environment variable.
+var settings = []setting{
+	{"port", "HTTP listen port", intSetter(func(c *Config) *int { return &c.Port })},
+	{"tick-rate", "simulation frequency in Hz", intSetter(func(c *Config) *int { return &c.TickRate })},
+	{"player-speed", "horizontal player speed in pixels/second", floatSetter(func(c *Config) *float64 { return &c.PlayerSpeed })},
+	{"chunks-ahead", "chunks generated ahead of the leading player", intSetter(func(c *Config) *int { return &c.ChunksAhead })},
+	{"chunk-cleanup-ticks", "ticks between cleanups of chunks behind every player", intSetter(func(c *Config) *int { return &c.ChunkCleanupTicks })},
+	{"send-buffer", "outgoing message queue size per client", intSetter(func(c *Config) *int { return &c.SendBuffer })},
+	{"interest-radius", "distance in pixels within which clients see other players (0 for everyone)", floatSetter(func(c *Config) *float64 { return &c.InterestRadius })},
+	{"ping-interval-ms", "milliseconds between heartbeat pings to each client (0 disables heartbeats)", intSetter(func(c *Config) *int { return &c.PingIntervalMs })},
+	{"max-missed-pings", "ping intervals a client may stay silent before it is disconnected", intSetter(func(c *Config) *int { return &c.MaxMissedPings })},
+	{"resume-grace-ms", "milliseconds a disconnected player is held for its client to resume (0 removes it immediately)", intSetter(func(c *Config) *int { return &c.ResumeGraceMs })},
+	{"leaderboard-path", "file the leaderboard persists scores to", stringSetter(func(c *Config) *string { return &c.LeaderboardPath })},
+	{"min-players", "players a room needs to start a race", intSetter(func(c *Config) *int { return &c.MinPlayers })},
+	{"countdown-ms", "milliseconds of countdown before each race", intSetter(func(c *Config) *int { return &c.CountdownMs })},
+	{"results-ms", "milliseconds results are shown after each race", intSetter(func(c *Config) *int { return &c.ResultsMs })},
+	{"finish-distance", "race length in pixels (0 for endless races)", floatSetter(func(c *Config) *float64 { return &c.FinishDistance })},
+	{"respawn-mode", "where dead players respawn: frontier, start or disabled", stringSetter(func(c *Config) *string { return &c.RespawnMode })},
+}
+
+// intSetter returns a setter that parses an integer into a field.
+func intSetter(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("%q is not an integer", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+// floatSetter returns a setter that parses a number into a field.
+func floatSetter(field func(c *Config) *float64) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", value)
+		}
+		*field(c) = f
+		return nil
+	}
+}
+
+// stringSetter returns a setter that stores text in a field.
+func stringSetter(field func(c *Config) *string) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+// Load builds the configuration from defaults, an optional config file,
+// environment variables and command-line flags, in increasing precedence.
+// The config file is named by the -config flag or the VIBE_RUNNER_CONFIG
+// environment variable; its format is chosen by extension.
+//
+// Parameters:
+//   - args: Command-line arguments without the program name (os.Args[1:])
+//   - getenv: Environment lookup (os.Getenv)
+//
+// Returns:
+//   - Config: The validated configuration
+//   - error: flag.ErrHelp for -h, or a parse, file or validation error
+func Load(args []string, getenv func(string) string) (Config, error) {
+	return load(args, getenv, os.Stderr)
+}
+
+// load implements Load, writing flag usage and errors to output.
+func load(args []string, getenv func(string) string, output io.Writer) (Config, error) {
+	flags := flag.NewFlagSet("vibe-runner-server", flag.ContinueOnError)
+	flags.SetOutput(output)
+	configPath := flags.String("config", "", "YAML, JSON or TOML config file (env "+ConfigFileEnv+")")
+
+	defaults := Default()
+	flagValues := make(map[string]string)
+	for _, s := range settings {
+		name := s.name
+		flags.Func(name, fmt.Sprintf("%s (env %s, default %s)", s.usage, s.env(), defaults.value(name)), func(value string) error {
+			flagValues[name] = value
+			return nil
+		})
+	}
+	if err := flags.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	config := defaults
+
+	path := *configPath
+	if path == "" {
+		path = getenv(ConfigFileEnv)
+	}
+	if path != "" {
+		if err := loadFile(path, &config); err != nil {
+			return Config{}, err
+		}
+	}
+
+	for _, s := range settings {
+		if value := getenv(s.env()); value != "" {
+			if err := s.set(&config, value); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", s.env(), err)
+			}
+		}
+	}
+
+	for _, s := range settings {
+		if value, ok := flagValues[s.name]; ok {
+			if err := s.set(&config, value); err != nil {
+				return Config{}, fmt.Errorf("-%s: %w", s.name, err)
+			}
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, err
+	}
+	return config, nil
+}
+
+// value returns a setting's current value as text, for flag help.
+func (c Config) value(name string) string {
+	switch name {
+	case "port":
+		return strconv.Itoa(c.Port)
+	case "tick-rate":
+		return strconv.Itoa(c.TickRate)
+	case "player-speed":
+		return strconv.FormatFloat(c.PlayerSpeed, 'g', -1, 64)
+	case "chunks-ahead":
+		return strconv.Itoa(c.ChunksAhead)
+	case "chunk-cleanup-ticks":
+		return strconv.Itoa(c.ChunkCleanupTicks)
+	case "send-buffer":
+		return strconv.Itoa(c.SendBuffer)
+	case "interest-radius":
+		return strconv.FormatFloat(c.InterestRadius, 'g', -1, 64)
+	case "ping-interval-ms":
+		return strconv.Itoa(c.PingIntervalMs)
+	case "max-missed-pings":
+		return strconv.Itoa(c.MaxMissedPings)
+	case "resume-grace-ms":
+		return strconv.Itoa(c.ResumeGraceMs)
+	case "leaderboard-path":
+		return c.LeaderboardPath
+	case "min-players":
+		return strconv.Itoa(c.MinPlayers)
+	case "countdown-ms":
+		return strconv.Itoa(c.CountdownMs)
+	case "results-ms":
+		return strconv.Itoa(c.ResultsMs)
+	case "finish-distance":
+		return strconv.FormatFloat(c.FinishDistance, 'g', -1, 64)
+	case "respawn-mode":
+		return c.RespawnMode
+	default:
+		return ""
+	}
+}
+
+// loadFile decodes a config file over the given config.
+// Keys missing from the file keep their current values; unknown keys are errors.
+func loadFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		err = decoder.Decode(config)
+
+	case ".yaml", ".yml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		err = decoder.Decode(config)
+		if errors.Is(err, io.EOF) {
+			err = nil // An empty file keeps every default
+		}
+
+	case ".toml":
+		var meta toml.MetaData
+		meta, err = toml.Decode(string(data), config)
+		if err == nil {
+			if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+				err = fmt.Errorf("unknown key %q", undecoded[0].String())
+			}
+		}
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFormat, path)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate checks every setting is within its supported range.
+//
+// Returns:
+//   - error: All problems found, each wrapping ErrInvalid (nil if valid)
+func (c Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%w: "+format, append([]interface{}{ErrInvalid}, args...)...))
+	}
+
+	if c.Port < 1 || c.Port > 65535 {
+		invalid("port %d out of range 1-65535", c.Port)
+	}
+	if c.TickRate < 1 || c.TickRate > game.MaxTickRate {
+		invalid("tick_rate %d out of range 1-%d", c.TickRate, game.MaxTickRate)
+	}
+	if c.PlayerSpeed <= 0 || math.IsInf(c.PlayerSpeed, 0) || math.IsNaN(c.PlayerSpeed) {
+		invalid("player_speed %g must be a positive number", c.PlayerSpeed)
+	}
+	if c.ChunksAhead < 1 || c.ChunksAhead > MaxChunksAhead {
+		invalid("chunks_ahead %d out of range 1-%d", c.ChunksAhead, MaxChunksAhead)
+	}
+	if c.ChunkCleanupTicks < 1 {
+		invalid("chunk_cleanup_ticks %d must be at least 1", c.ChunkCleanupTicks)
+	}
+	if c.SendBuffer < 1 || c.SendBuffer > MaxSendBuffer {
+		invalid("send_buffer %d out of range 1-%d", c.SendBuffer, MaxSendBuffer)
+	}
+	if c.InterestRadius < 0 || math.IsInf(c.InterestRadius, 0) || math.IsNaN(c.InterestRadius) {
+		invalid("interest_radius %g must be 0 or a positive number", c.InterestRadius)
+	}
+	if c.PingIntervalMs < 0 {
+		invalid("ping_interval_ms %d must be 0 or positive", c.PingIntervalMs)
+	}
+	if c.MaxMissedPings < 1 {
+		invalid("max_missed_pings %d must be at least 1", c.MaxMissedPings)
+	}
+	if c.ResumeGraceMs < 0 {
+		invalid("resume_grace_ms %d must be 0 or positive", c.ResumeGraceMs)
+	}
+	if strings.TrimSpace(c.LeaderboardPath) == "" {
+		invalid("leaderboard_path must not be empty")
+	}
+	if c.MinPlayers < 1 || c.MinPlayers > room.DefaultRoomCapacity {
+		invalid("min_players %d out of range 1-%d", c.MinPlayers, room.DefaultRoomCapacity)
+	}
+	if c.CountdownMs < 0 {
+		invalid("countdown_ms %d must be 0 or positive", c.CountdownMs)
+	}
+	if c.ResultsMs < 0 {
+		invalid("results_ms %d must be 0 or positive", c.ResultsMs)
+	}
+	if c.FinishDistance < 0 || math.IsInf(c.FinishDistance, 0) || math.IsNaN(c.FinishDistance) {
+		invalid("finish_distance %g must be 0 or a positive number", c.FinishDistance)
+	}
+	if _, err := game.ParseRespawnMode(c.RespawnMode); err != nil {
+		invalid("respawn_mode: %v", err)
+	}
+
+	return errors.Join(errs...)
+}
+
+// Addr returns the HTTP listen address, e.g. ":8080".
+func (c Config) Addr() string {
+	return fmt.Sprintf(":%d", c.Port)
+}
+
+// Game returns the simulation and race settings for each world.
+func (c Config) Game() game.Config {
+	// Validate rejects unknown respawn modes, so the error can't occur here
+	respawn, _ := game.ParseRespawnMode(c.RespawnMode)
+
+	return game.Config{
+		TickRate:          c.TickRate,
+		PlayerSpeed:       c.PlayerSpeed,
+		ChunksAhead:       c.ChunksAhead,
+		ChunkCleanupTicks: c.ChunkCleanupTicks,
+		MinPlayers:        c.MinPlayers,
+		Countdown:         time.Duration(c.CountdownMs) * time.Millisecond,
+		Results:           time.Duration(c.ResultsMs) * time.Millisecond,
+		FinishDistance:    c.FinishDistance,
+		Respawn:           respawn,
+	}
+}
+
+// Room returns the settings for each room's world and client hub.
+func (c Config) Room() room.Config {
+	return room.Config{
+		World:          c.Game(),
+		SendBuffer:     c.SendBuffer,
+		InterestRadius: c.InterestRadius,
+		PingInterval:   time.Duration(c.PingIntervalMs) * time.Millisecond,
+		MaxMissedPings: c.MaxMissedPings,
+		ResumeGrace:    time.Duration(c.ResumeGraceMs) * time.Millisecond,
+	}
+}
@@ -0,0 +1,230 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"vibe-runner-server/game"
+)
+
+// fakeEnv returns a getenv function backed by a map.
+func fakeEnv(vars map[string]string) func(string) string {
+	return func(key string) string { return vars[key] }
+}
+
+// writeConfigFile writes a config file into a temporary directory.
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("test setup: %v", err)
+	}
+	return path
+}
+
+// TestLoad_Defaults verifies no flags, environment or file yields the
+// built-in settings.
+func TestLoad_Defaults(t *testing.T) {
+	// Act
+	config, err := Load(nil, fakeEnv(nil))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if config != Default() {
+		t.Errorf("Load() = %+v, want %+v", config, Default())
+	}
+	if config.Addr() != ":8080" || config.TickRate != 20 || config.SendBuffer != 10 {
+		t.Errorf("Default() = %+v, want port 8080, 20Hz, buffer 10", config)
+	}
+}
+
+// TestLoad_FileFormats verifies each supported file format is decoded and
+// keys missing from the file keep their defaults.
+func TestLoad_FileFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"server.yaml", "tick_rate: 30\nplayer_speed: 350.5\n"},
+		{"server.yml", "tick_rate: 30\nplayer_speed: 350.5\n"},
+		{"server.json", `{"tick_rate": 30, "player_speed": 350.5}`},
+		{"server.toml", "tick_rate = 30\nplayer_speed = 350.5\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			path := writeConfigFile(t, tt.name, tt.content)
+
+			// Act
+			config, err := Load([]string{"-config", path}, fakeEnv(nil))
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if config.TickRate != 30 || config.PlayerSpeed != 350.5 {
+				t.Errorf("Load() = %+v, want tick_rate 30 and player_speed 350.5", config)
+			}
+			if config.ChunksAhead != Default().ChunksAhead {
+				t.Errorf("ChunksAhead = %d, want default %d", config.ChunksAhead, Default().ChunksAhead)
+			}
+		})
+	}
+}
+
+// TestLoad_Precedence verifies flags override the environment, which
+// overrides the config file.
+func TestLoad_Precedence(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "server.yaml", "port: 7000\ntick_rate: 30\nsend_buffer: 32\n")
+	env := fakeEnv(map[string]string{
+		ConfigFileEnv:                  path,
+		"VIBE_RUNNER_TICK_RATE":        "40",
+		"VIBE_RUNNER_PORT":             "7100",
+		"VIBE_RUNNER_CHUNKS_AHEAD":     "3",
+		"VIBE_RUNNER_LEADERBOARD_PATH": "/var/lib/vibe-runner/scores.log",
+	})
+
+	// Act
+	config, err := Load([]string{"-port", "9000"}, env)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	want := Default()
+	want.Port = 9000     // flag beats env and file
+	want.TickRate = 40   // env beats file
+	want.ChunksAhead = 3 // env only
+	want.SendBuffer = 32 // file only
+	want.LeaderboardPath = "/var/lib/vibe-runner/scores.log"
+	if config != want {
+		t.Errorf("Load() = %+v, want %+v", config, want)
+	}
+}
+
+// TestLoad_Errors verifies bad input is reported rather than ignored.
+func TestLoad_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     map[string]string
+		file    string
+		content string
+		wantErr error
+	}{
+		{name: "flag out of range", args: []string{"-tick-rate", "500"}, wantErr: ErrInvalid},
+		{name: "zero speed", args: []string{"-player-speed", "0"}, wantErr: ErrInvalid},
+		{name: "unknown respawn mode", env: map[string]string{"VIBE_RUNNER_RESPAWN_MODE": "anywhere"}, wantErr: ErrInvalid},
+		{name: "env not a number", env: map[string]string{"VIBE_RUNNER_SEND_BUFFER": "lots"}},
+		{name: "unknown file key", file: "server.json", content: `{"tickrate": 30}`},
+		{name: "unknown toml key", file: "server.toml", content: "speed = 30\n"},
+		{name: "unknown format", file: "server.ini", content: "port=1", wantErr: ErrUnknownFormat},
+		{name: "help", args: []string{"-h"}, wantErr: flag.ErrHelp},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			args := tt.args
+			if tt.file != "" {
+				args = append(args, "-config", writeConfigFile(t, tt.file, tt.content))
+			}
+
+			// Act
+			_, err := load(args, fakeEnv(tt.env), io.Discard)
+
+			// Assert
+			if err == nil {
+				t.Fatal("Load() error = nil, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("Load() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestValidate_ReportsEveryProblem verifies all invalid fields are listed.
+func TestValidate_ReportsEveryProblem(t *testing.T) {
+	// Arrange
+	config := Default()
+	config.Port = 0
+	config.SendBuffer = 0
+	config.InterestRadius = -1
+	config.LeaderboardPath = ""
+	config.MinPlayers = 0
+	config.FinishDistance = -1
+
+	// Act
+	err := config.Validate()
+
+	// Assert
+	if !errors.Is(err, ErrInvalid) {
+		t.Fatalf("Validate() error = %v, want ErrInvalid", err)
+	}
+	for _, field := range []string{"port", "send_buffer", "interest_radius", "leaderboard_path", "min_players", "finish_distance"} {
+		if !strings.Contains(err.Error(), field) {
+			t.Errorf("Validate() error %q doesn't mention %s", err, field)
+		}
+	}
+}
+
+// TestConfig_Room verifies the settings reach each room's world and hub.
+func TestConfig_Room(t *testing.T) {
+	config := Default()
+	config.TickRate = 30
+	config.ChunkCleanupTicks = 120
+	config.SendBuffer = 64
+	config.InterestRadius = 2000
+	config.PingIntervalMs = 2500
+	config.MaxMissedPings = 4
+	config.ResumeGraceMs = 20000
+
+	roomConfig := config.Room()
+
+	if roomConfig.World.TickRate != 30 || roomConfig.World.ChunkCleanupTicks != 120 || roomConfig.SendBuffer != 64 {
+		t.Errorf("Room() = %+v, want tick rate 30, cleanup 120, buffer 64", roomConfig)
+	}
+	if roomConfig.InterestRadius != 2000 {
+		t.Errorf("Room() InterestRadius = %g, want 2000", roomConfig.InterestRadius)
+	}
+	if roomConfig.PingInterval != 2500*time.Millisecond || roomConfig.MaxMissedPings != 4 {
+		t.Errorf("Room() heartbeat = %s x %d, want 2.5s x 4", roomConfig.PingInterval, roomConfig.MaxMissedPings)
+	}
+	if roomConfig.ResumeGrace != 20*time.Second {
+		t.Errorf("Room() ResumeGrace = %s, want 20s", roomConfig.ResumeGrace)
+	}
+}
+
+// TestConfig_Game verifies the race settings reach each world's match.
+func TestConfig_Game(t *testing.T) {
+	// Arrange
+	path := writeConfigFile(t, "server.yaml", "min_players: 2\ncountdown_ms: 5000\nfinish_distance: 6000\n")
+
+	// Act
+	config, err := Load([]string{"-config", path, "-respawn-mode", "start", "-results-ms", "2000"}, fakeEnv(nil))
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	world := config.Game()
+	if world.MinPlayers != 2 || world.FinishDistance != 6000 || world.Respawn != game.RespawnAtStart {
+		t.Errorf("Game() = %+v, want 2 players, 6000px finish, respawn at start", world)
+	}
+	if world.Countdown != 5*time.Second || world.Results != 2*time.Second {
+		t.Errorf("Game() phases = %s countdown, %s results, want 5s and 2s", world.Countdown, world.Results)
+	}
+	if match := world.MatchConfig(); match.FinishDistance != 6000 || match.CountdownTicks != 100 {
+		t.Errorf("MatchConfig() = %+v, want 6000px finish and 100 countdown ticks", match)
+	}
+}
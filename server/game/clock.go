@@ -0,0 +1,59 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock is a source of the current time for a world.
+// Production worlds use RealClock; tests and tools use a ManualClock so a
+// simulation stamps ticks and scores with predictable times.
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
+
+// RealClock is a Clock that reads the system time.
+type RealClock struct{}
+
+// Now returns time.Now().
+func (RealClock) Now() time.Time {
+	return time.Now()
+}
+
+// ManualClock is a Clock that only moves when told to.
+// World.Step advances it by TickDuration before every step.
+// Safe for concurrent use.
+type ManualClock struct {
+	// mu protects now.
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewManualClock creates a manual clock stopped at the given time.
+//
+// Parameters:
+//   - start: The clock's initial time
+//
+// Returns:
+//   - *ManualClock: Clock reading start until advanced
+func NewManualClock(start time.Time) *ManualClock {
+	return &ManualClock{now: start}
+}
+
+// Now returns the clock's current time.
+func (c *ManualClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward.
+//
+// Parameters:
+//   - d: How far to move the clock
+func (c *ManualClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
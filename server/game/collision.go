@@ -0,0 +1,106 @@
+package game
+
+import "vibe-runner-server/generation"
+
+// GroundSurfaceY is the Y coordinate of the ground surface (pixels from top).
+// Players stand with their top edge at GroundY, so their feet rest here.
+// Ground-level obstacles (Y=0) sit with their bottom edge on this line.
+const GroundSurfaceY = GroundY + PlayerHeight
+
+// Hitbox is an axis-aligned bounding box in world coordinates.
+// X and Y are the top-left corner (Y increases downward).
+type Hitbox struct {
+	X      float64
+	Y      float64
+	Width  float64
+	Height float64
+}
+
+// Intersects reports whether two hitboxes overlap.
+// Boxes that only touch at an edge are not considered overlapping.
+//
+// Parameters:
+//   - other: The hitbox to test against
+//
+// Returns:
+//   - bool: True if the boxes overlap
+func (h Hitbox) Intersects(other Hitbox) bool {
+	return h.X < other.X+other.Width &&
+		h.X+h.Width > other.X &&
+		h.Y < other.Y+other.Height &&
+		h.Y+h.Height > other.Y
+}
+
+// PlayerHitbox returns the player's current hitbox in world coordinates.
+//
+// Parameters:
+//   - player: The player to build a hitbox for
+//
+// Returns:
+//   - Hitbox: PlayerWidth x PlayerHeight box anchored at the player's position
+func PlayerHitbox(player *Player) Hitbox {
+	return Hitbox{
+		X:      player.X,
+		Y:      player.Y,
+		Width:  PlayerWidth,
+		Height: PlayerHeight,
+	}
+}
+
+// ObstacleHitboxes returns the hitboxes of an obstacle in world coordinates,
+// using the geometry registered in the generation package.
+// Obstacle Y is an elevation above the ground surface (0 = sitting on the ground).
+//
+// Parameters:
+//   - obstacle: The obstacle to build hitboxes for
+//
+// Returns:
+//   - []Hitbox: The obstacle's hitboxes
+//   - bool: False if the obstacle type is unknown
+func ObstacleHitboxes(obstacle generation.Obstacle) ([]Hitbox, bool) {
+	geometry, ok := generation.GeometryFor(obstacle.Type)
+	if !ok {
+		return nil, false
+	}
+
+	baseY := GroundSurfaceY - obstacle.Y
+	hitboxes := make([]Hitbox, len(geometry.Boxes))
+	for i, box := range geometry.Boxes {
+		hitboxes[i] = Hitbox{
+			X:      obstacle.X + box.OffsetX,
+			Y:      baseY - box.OffsetY - box.Height,
+			Width:  box.Width,
+			Height: box.Height,
+		}
+	}
+
+	return hitboxes, true
+}
+
+// checkObstacleCollision reports whether the player overlaps any obstacle
+// the chunk manager has generated near the player's position.
+//
+// Parameters:
+//   - player: The player to test
+//   - chunkManager: Source of generated obstacles
+//
+// Returns:
+//   - bool: True if the player collides with an obstacle
+func checkObstacleCollision(player *Player, chunkManager ChunkManager) bool {
+	playerBox := PlayerHitbox(player)
+
+	obstacles := chunkManager.ObstaclesInRange(player.X-generation.MaxObstacleWidth(), player.X+PlayerWidth)
+	for _, obstacle := range obstacles {
+		obstacleBoxes, ok := ObstacleHitboxes(obstacle)
+		if !ok {
+			continue
+		}
+		for _, obstacleBox := range obstacleBoxes {
+			if playerBox.Intersects(obstacleBox) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
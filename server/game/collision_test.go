@@ -0,0 +1,169 @@
+package game
+
+import (
+	"testing"
+	"vibe-runner-server/generation"
+)
+
+// fakeChunkManager is a ChunkManager stub that serves a fixed obstacle list.
+type fakeChunkManager struct {
+	obstacles []generation.Obstacle
+}
+
+func (f *fakeChunkManager) GenerateAheadForPlayer(playerX float64, chunksAhead int) {}
+
+func (f *fakeChunkManager) CleanupBehind(minPlayerX float64, keepBehind int) {}
+
+func (f *fakeChunkManager) GetOrGenerateChunkInterface(chunkID int) interface{} { return nil }
+
+func (f *fakeChunkManager) Seed() string { return "fake-seed" }
+
+func (f *fakeChunkManager) GeneratorVersion() int { return 1 }
+
+func (f *fakeChunkManager) ObstaclesInRange(startX, endX float64) []generation.Obstacle {
+	result := make([]generation.Obstacle, 0)
+	for _, obstacle := range f.obstacles {
+		if obstacle.X >= startX && obstacle.X <= endX {
+			result = append(result, obstacle)
+		}
+	}
+	return result
+}
+
+// fakeDeathNotifier records death events sent by the ticker.
+type fakeDeathNotifier struct {
+	deaths map[int]int
+}
+
+func (f *fakeDeathNotifier) BroadcastState(gameState *GameState) {}
+
+func (f *fakeDeathNotifier) SendDeath(playerID int, score int) {
+	f.deaths[playerID] = score
+}
+
+// TestHitbox_Intersects tests AABB overlap with a table of box pairs.
+func TestHitbox_Intersects(t *testing.T) {
+	base := Hitbox{X: 100, Y: 100, Width: 40, Height: 60}
+
+	tests := []struct {
+		name  string
+		other Hitbox
+		want  bool
+	}{
+		{name: "identical boxes", other: base, want: true},
+		{name: "partial overlap", other: Hitbox{X: 120, Y: 130, Width: 40, Height: 60}, want: true},
+		{name: "touching right edge", other: Hitbox{X: 140, Y: 100, Width: 40, Height: 60}, want: false},
+		{name: "touching bottom edge", other: Hitbox{X: 100, Y: 160, Width: 40, Height: 60}, want: false},
+		{name: "fully separated", other: Hitbox{X: 500, Y: 500, Width: 10, Height: 10}, want: false},
+		{name: "contained", other: Hitbox{X: 110, Y: 110, Width: 5, Height: 5}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := base.Intersects(tt.other); got != tt.want {
+				t.Errorf("Intersects() = %v, want %v", got, tt.want)
+			}
+			if got := tt.other.Intersects(base); got != tt.want {
+				t.Errorf("Intersects() reversed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestObstacleHitboxes_GroundObstacleSitsOnGround tests that ground-level
+// obstacles have their bottom edge on the ground surface.
+func TestObstacleHitboxes_GroundObstacleSitsOnGround(t *testing.T) {
+	obstacle := generation.Obstacle{Type: generation.ObstacleTypeTall, X: 1000, Y: 0}
+
+	boxes, ok := ObstacleHitboxes(obstacle)
+
+	if !ok {
+		t.Fatal("ObstacleHitboxes() returned ok=false for known type")
+	}
+	if len(boxes) != 1 {
+		t.Fatalf("ObstacleHitboxes() returned %d boxes, want 1", len(boxes))
+	}
+	box := boxes[0]
+	if box.Height != 100 {
+		t.Errorf("tall obstacle height = %.1f, want 100.0", box.Height)
+	}
+	if box.Y+box.Height != GroundSurfaceY {
+		t.Errorf("obstacle bottom = %.1f, want %.1f", box.Y+box.Height, GroundSurfaceY)
+	}
+	if box.X != 1000 {
+		t.Errorf("obstacle X = %.1f, want 1000.0", box.X)
+	}
+}
+
+// TestObstacleHitboxes_UnknownType tests that unknown types are rejected.
+func TestObstacleHitboxes_UnknownType(t *testing.T) {
+	_, ok := ObstacleHitboxes(generation.Obstacle{Type: 99, X: 1000})
+
+	if ok {
+		t.Error("ObstacleHitboxes() returned ok=true for unknown type")
+	}
+}
+
+// TestCheckObstacleCollision_GroundedPlayerHitsObstacle tests that a
+// grounded player overlapping an obstacle collides.
+func TestCheckObstacleCollision_GroundedPlayerHitsObstacle(t *testing.T) {
+	player := NewPlayer(1, "Runner")
+	player.X = 990
+	chunks := &fakeChunkManager{obstacles: []generation.Obstacle{
+		{Type: generation.ObstacleTypeLow, X: 1000, Y: 0},
+	}}
+
+	if !checkObstacleCollision(player, chunks) {
+		t.Error("checkObstacleCollision() = false, want true for overlapping obstacle")
+	}
+}
+
+// TestCheckObstacleCollision_JumpingPlayerClearsObstacle tests that a
+// player high enough above an obstacle does not collide.
+func TestCheckObstacleCollision_JumpingPlayerClearsObstacle(t *testing.T) {
+	player := NewPlayer(1, "Runner")
+	player.X = 1000
+	player.Y = GroundY - 120 // Feet well above a 100px tall obstacle
+	chunks := &fakeChunkManager{obstacles: []generation.Obstacle{
+		{Type: generation.ObstacleTypeTall, X: 1000, Y: 0},
+	}}
+
+	if checkObstacleCollision(player, chunks) {
+		t.Error("checkObstacleCollision() = true, want false when jumping over obstacle")
+	}
+}
+
+// TestCheckObstacleCollision_WideObstacleBehindPlayer tests that an
+// obstacle starting behind the player's X still collides if it overlaps.
+func TestCheckObstacleCollision_WideObstacleBehindPlayer(t *testing.T) {
+	player := NewPlayer(1, "Runner")
+	player.X = 1050
+	chunks := &fakeChunkManager{obstacles: []generation.Obstacle{
+		{Type: generation.ObstacleTypeLow, X: 1000, Y: 0}, // Spans 1000-1060
+	}}
+
+	if !checkObstacleCollision(player, chunks) {
+		t.Error("checkObstacleCollision() = false, want true for obstacle starting behind player")
+	}
+}
+
+// TestKillPlayer_KillsAndNotifies tests that killPlayer marks the player
+// dead and sends their server-computed score.
+func TestKillPlayer_KillsAndNotifies(t *testing.T) {
+	player := NewPlayer(7, "Runner")
+	player.X = 1600.5
+	notifier := &fakeDeathNotifier{deaths: make(map[int]int)}
+
+	killPlayer(player, notifier)
+
+	if player.IsAlive {
+		t.Error("killPlayer() left player alive")
+	}
+	score, sent := notifier.deaths[7]
+	if !sent {
+		t.Fatal("killPlayer() did not send death event")
+	}
+	if score != 1500 {
+		t.Errorf("death score = %d, want 1500", score)
+	}
+}
@@ -0,0 +1,108 @@
+package game
+
+import "time"
+
+const (
+	// MaxTickRate is the highest supported tick rate (Hz).
+	// Per-player history is sized for MaxRewind at this rate.
+	MaxTickRate = 60
+
+	// DefaultChunksAhead is how many chunks are generated ahead of the leader.
+	// Two chunks keep generation within two screen widths of the frontier.
+	DefaultChunksAhead = 2
+
+	// DefaultChunkCleanupTicks is how often chunks behind every player are
+	// dropped (80 ticks = 4 seconds at 20Hz).
+	DefaultChunkCleanupTicks = 80
+
+	// DefaultCountdown is how long the countdown before each race lasts.
+	DefaultCountdown = 3 * time.Second
+
+	// DefaultResults is how long results are shown after each race.
+	DefaultResults = 5 * time.Second
+)
+
+// Config holds the tunable simulation settings of a world.
+// The zero value is not usable; start from DefaultConfig.
+type Config struct {
+	// TickRate is the simulation frequency in Hz (1 to MaxTickRate).
+	TickRate int
+
+	// PlayerSpeed is the constant horizontal speed in pixels/second.
+	PlayerSpeed float64
+
+	// ChunksAhead is the number of chunks generated ahead of the leading player.
+	ChunksAhead int
+
+	// ChunkCleanupTicks is the interval, in ticks, between cleanups of
+	// chunks behind the trailing player.
+	ChunkCleanupTicks int
+
+	// MinPlayers is the number of players needed to start the countdown.
+	MinPlayers int
+
+	// Countdown is the length of the countdown before each race.
+	Countdown time.Duration
+
+	// Results is how long results are shown before the next race.
+	Results time.Duration
+
+	// FinishDistance is the race length in pixels from the start line.
+	// Zero means an endless race.
+	FinishDistance float64
+
+	// Respawn selects where dead players respawn while the race runs.
+	Respawn RespawnMode
+}
+
+// DefaultConfig returns the standard settings: 20Hz, 300 px/s, two chunks
+// ahead, a chunk cleanup every 4 seconds, and endless races for one or more
+// players with a 3 second countdown, 5 seconds of results and respawning
+// at the frontier.
+//
+// Returns:
+//   - Config: Default configuration
+func DefaultConfig() Config {
+	return Config{
+		TickRate:          TickRate,
+		PlayerSpeed:       PlayerSpeed,
+		ChunksAhead:       DefaultChunksAhead,
+		ChunkCleanupTicks: DefaultChunkCleanupTicks,
+		MinPlayers:        1,
+		Countdown:         DefaultCountdown,
+		Results:           DefaultResults,
+		FinishDistance:    0,
+		Respawn:           RespawnAtFrontier,
+	}
+}
+
+// TickDuration returns the real time covered by one tick.
+func (c Config) TickDuration() time.Duration {
+	return time.Second / time.Duration(c.TickRate)
+}
+
+// DeltaTime returns the physics time step in seconds.
+func (c Config) DeltaTime() float64 {
+	return 1.0 / float64(c.TickRate)
+}
+
+// MatchConfig returns the race lifecycle settings, with the countdown and
+// results lengths converted to ticks at this tick rate.
+//
+// Returns:
+//   - MatchConfig: Match settings at this tick rate
+func (c Config) MatchConfig() MatchConfig {
+	return MatchConfig{
+		MinPlayers:     c.MinPlayers,
+		CountdownTicks: c.ticks(c.Countdown),
+		ResultsTicks:   c.ticks(c.Results),
+		FinishDistance: c.FinishDistance,
+		Respawn:        c.Respawn,
+		TickRate:       c.TickRate,
+	}
+}
+
+// ticks returns the number of whole ticks in a duration.
+func (c Config) ticks(d time.Duration) int {
+	return int(d * time.Duration(c.TickRate) / time.Second)
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestConfig_MatchConfigKeepsRealTime verifies phase lengths scale with the
+// tick rate so a countdown lasts the same wall-clock time.
+func TestConfig_MatchConfigKeepsRealTime(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	config.TickRate = 40
+
+	// Act
+	match := NewMatch(config.MatchConfig())
+	match.Advance([]*Player{NewPlayer(1, "Runner")})
+	event := match.CurrentEvent()
+
+	// Assert
+	if event.Phase != PhaseCountdown {
+		t.Fatalf("phase = %s, want countdown", event.Phase)
+	}
+	if event.Remaining != 3*time.Second {
+		t.Errorf("countdown Remaining = %v, want 3s", event.Remaining)
+	}
+}
+
+// TestConfig_MatchConfigCarriesRaceSettings verifies configured race
+// settings reach the world's match.
+func TestConfig_MatchConfigCarriesRaceSettings(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	config.TickRate = 30
+	config.MinPlayers = 2
+	config.Countdown = 5 * time.Second
+	config.Results = 2 * time.Second
+	config.FinishDistance = 6000
+	config.Respawn = RespawnDisabled
+
+	// Act
+	world := NewWorldWithConfig(nil, config)
+	got := world.Match.Config()
+
+	// Assert
+	want := MatchConfig{
+		MinPlayers:     2,
+		CountdownTicks: 150,
+		ResultsTicks:   60,
+		FinishDistance: 6000,
+		Respawn:        RespawnDisabled,
+		TickRate:       30,
+	}
+	if got != want {
+		t.Errorf("match config = %+v, want %+v", got, want)
+	}
+}
+
+// TestUpdatePlayerPhysics_TickRateIndependentSpeed verifies a second of
+// simulation covers PlayerSpeed pixels at any tick rate.
+func TestUpdatePlayerPhysics_TickRateIndependentSpeed(t *testing.T) {
+	for _, tickRate := range []int{10, 20, 60} {
+		// Arrange
+		config := DefaultConfig()
+		config.TickRate = tickRate
+		config.PlayerSpeed = 450
+		player := NewPlayer(1, "Runner")
+		startX := player.X
+
+		// Act
+		for i := 0; i < tickRate; i++ {
+			updatePlayerPhysics(player, config)
+		}
+
+		// Assert
+		if moved := player.X - startX; math.Abs(moved-450) > 1e-6 {
+			t.Errorf("at %dHz moved %.3f px in one second, want 450", tickRate, moved)
+		}
+	}
+}
+
+// TestStep_UsesConfiguredTickRate verifies Step advances a manual clock by
+// the world's own tick duration.
+func TestStep_UsesConfiguredTickRate(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	config.TickRate = 50
+	world := NewWorldWithConfig(nil, config)
+	world.Clock = NewManualClock(stepTestStart)
+
+	// Act
+	world.Step(config.TickRate, nil)
+
+	// Assert
+	if elapsed := world.Clock.Now().Sub(stepTestStart); elapsed != time.Second {
+		t.Errorf("clock advanced %v after %d ticks, want 1s", elapsed, config.TickRate)
+	}
+}
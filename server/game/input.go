@@ -0,0 +1,243 @@
+package game
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+// InputKind is the type of a player input command.
+type InputKind int
+
+const (
+	// InputJump makes a grounded player jump.
+	InputJump InputKind = iota
+
+	// InputRespawn brings a dead player back into the running race.
+	InputRespawn
+
+	// InputDisconnect freezes a player whose client dropped, holding it for a resume.
+	InputDisconnect
+
+	// InputResume unfreezes a held player whose client reconnected.
+	InputResume
+)
+
+// String returns a readable name for the input kind.
+func (k InputKind) String() string {
+	switch k {
+	case InputJump:
+		return "jump"
+	case InputRespawn:
+		return "respawn"
+	case InputDisconnect:
+		return "disconnect"
+	case InputResume:
+		return "resume"
+	default:
+		return "unknown"
+	}
+}
+
+// MaxPendingInputs caps the number of commands waiting for the next tick.
+// At 20Hz this is far more than honest clients can send between ticks;
+// commands beyond it are dropped so a flood can't grow the queue unbounded.
+const MaxPendingInputs = 1024
+
+// InputCommand is a player input waiting to be applied by the game ticker.
+type InputCommand struct {
+	// PlayerID is the player the input belongs to.
+	PlayerID int
+
+	// Kind is what the player asked to do.
+	Kind InputKind
+
+	// Seq is the client's input sequence number (0 if the client doesn't send one).
+	// Sequence numbers increase by at least one with every input a client sends.
+	Seq uint32
+
+	// At is when the client pressed the input (zero if the client didn't say).
+	// Jumps are applied at this time, within MaxRewind, to compensate for latency.
+	At time.Time
+}
+
+// InputQueue collects input commands from client goroutines until the ticker
+// drains them. Only the ticker goroutine mutates players, so the simulation
+// never races with network input.
+type InputQueue struct {
+	// pending are the commands received since the last drain, in arrival order.
+	pending []InputCommand
+
+	// mu protects pending.
+	mu sync.Mutex
+}
+
+// NewInputQueue creates an empty input queue.
+//
+// Returns:
+//   - *InputQueue: Empty queue ready for use
+func NewInputQueue() *InputQueue {
+	return &InputQueue{
+		pending: make([]InputCommand, 0, 16),
+	}
+}
+
+// Enqueue adds a command to be applied on the next tick.
+// Safe to call from any goroutine.
+//
+// Parameters:
+//   - cmd: The input command
+//
+// Returns:
+//   - bool: False if the queue is full and the command was dropped
+func (q *InputQueue) Enqueue(cmd InputCommand) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) >= MaxPendingInputs {
+		return false
+	}
+	q.pending = append(q.pending, cmd)
+	return true
+}
+
+// Drain removes and returns every pending command in arrival order.
+//
+// Returns:
+//   - []InputCommand: The pending commands (nil if none)
+func (q *InputQueue) Drain() []InputCommand {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending) == 0 {
+		return nil
+	}
+	drained := q.pending
+	q.pending = make([]InputCommand, 0, cap(drained))
+	return drained
+}
+
+// SpawnNotifier is an interface for telling a single client where its player
+// respawned, or why it couldn't.
+// The ticker type-asserts its Broadcaster to this interface after handling a respawn.
+type SpawnNotifier interface {
+	// SendSpawn sends the new spawn position to one player's client
+	SendSpawn(playerID int, x, y float64)
+
+	// RejectRespawn tells one player's client its respawn request was refused
+	RejectRespawn(playerID int, reason error)
+}
+
+// applyInputs applies every queued command to the world.
+// Called by the ticker at the start of each tick, before physics.
+//
+// A sequenced command is acknowledged by recording its Seq on the player.
+// Commands whose Seq is not newer than the last acknowledged one are
+// duplicates or arrived out of order, and are dropped.
+//
+// Parameters:
+//   - world: The world to apply inputs to
+//   - broadcaster: The broadcaster used to answer respawns (may be nil)
+//   - now: The server time of the current tick
+//
+// Returns:
+//   - []*Player: Players whose rewound jump carried them over the finish
+//     line and whose final score was recorded
+func applyInputs(world *World, broadcaster Broadcaster, now time.Time) []*Player {
+	var recorded []*Player
+	for _, cmd := range world.Inputs.Drain() {
+		if cmd.Seq != 0 {
+			player := world.State.GetPlayer(cmd.PlayerID)
+			if player == nil {
+				continue
+			}
+			if cmd.Seq <= player.LastInputSeq {
+				log.Printf("Dropping stale %s input from player %d: seq %d <= %d",
+					cmd.Kind, cmd.PlayerID, cmd.Seq, player.LastInputSeq)
+				continue
+			}
+			player.LastInputSeq = cmd.Seq
+		}
+
+		switch cmd.Kind {
+		case InputJump:
+			player := world.State.GetPlayer(cmd.PlayerID)
+			if player == nil {
+				continue
+			}
+			if applyJump(world, player, cmd.At, now) {
+				recorded = append(recorded, player)
+			}
+
+		case InputRespawn:
+			player, err := world.RespawnPlayer(cmd.PlayerID)
+			if err != nil {
+				log.Printf("Respawn rejected for player %d: %v", cmd.PlayerID, err)
+				if notifier, ok := broadcaster.(SpawnNotifier); ok {
+					notifier.RejectRespawn(cmd.PlayerID, err)
+				}
+				continue
+			}
+			log.Printf("Player %d (%s) respawned at X=%.1f", player.ID, player.Name, player.X)
+			if notifier, ok := broadcaster.(SpawnNotifier); ok {
+				notifier.SendSpawn(player.ID, player.X, player.Y)
+			}
+
+		case InputDisconnect, InputResume:
+			player := world.State.GetPlayer(cmd.PlayerID)
+			if player == nil {
+				continue
+			}
+			player.Disconnected = cmd.Kind == InputDisconnect
+			// The frozen stretch isn't history a jump can rewind into
+			player.history.reset()
+
+		default:
+			log.Printf("Ignoring unknown input %d from player %d", cmd.Kind, cmd.PlayerID)
+		}
+	}
+	return recorded
+}
+
+// applyJump makes a player jump, lag-compensated while the race is running.
+// Jumps with a usable client timestamp are rewound to when they were pressed;
+// otherwise (no timestamp, outside the rewind window, or not grounded back
+// then) the jump applies to the current state as usual.
+//
+// A jump pressed before a pending death's collision can revive the player.
+// If the replay hits an obstacle instead, that death is pending in turn.
+// If it crosses the finish line, the player finishes and their score is
+// recorded as it would have been on time.
+//
+// Parameters:
+//   - world: The player's world
+//   - player: The jumping player
+//   - pressedAt: When the client pressed jump (zero if unknown)
+//   - now: The server time of the current tick
+//
+// Returns:
+//   - bool: True if the replay finished the player and their score was recorded
+func applyJump(world *World, player *Player, pressedAt, now time.Time) bool {
+	racing := player.IsAlive || player.DeathPending()
+	if pressedAt.IsZero() || !racing || player.Finished || world.Match.Phase() != PhaseRunning {
+		player.Jump()
+		return false
+	}
+
+	rewound, err := rewindJump(player, pressedAt, now, world.Chunks, world.Match, world.Config)
+	if err != nil {
+		if err == ErrRewindTooFar {
+			log.Printf("Player %d jump rewind rejected: %v (%s ago)", player.ID, err, now.Sub(pressedAt))
+		}
+		player.Jump()
+		return false
+	}
+	if rewound > 0 {
+		log.Printf("Player %d jump rewound %d ticks", player.ID, rewound)
+	}
+	if !player.Finished {
+		return false
+	}
+	log.Printf("Player %d (%s) finished, score=%d", player.ID, player.Name, player.Score())
+	return world.recordScore(player)
+}
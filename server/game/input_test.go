@@ -0,0 +1,251 @@
+package game
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestInputQueue_DrainsInArrivalOrder verifies commands come out in order and
+// the queue is empty after a drain.
+func TestInputQueue_DrainsInArrivalOrder(t *testing.T) {
+	// Arrange
+	queue := NewInputQueue()
+	queue.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump})
+	queue.Enqueue(InputCommand{PlayerID: 2, Kind: InputRespawn})
+	queue.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump})
+
+	// Act
+	drained := queue.Drain()
+
+	// Assert
+	want := []InputCommand{
+		{PlayerID: 1, Kind: InputJump},
+		{PlayerID: 2, Kind: InputRespawn},
+		{PlayerID: 1, Kind: InputJump},
+	}
+	if len(drained) != len(want) {
+		t.Fatalf("Drain() returned %d commands, want %d", len(drained), len(want))
+	}
+	for i := range want {
+		if drained[i] != want[i] {
+			t.Errorf("Drain()[%d] = %+v, want %+v", i, drained[i], want[i])
+		}
+	}
+	if again := queue.Drain(); again != nil {
+		t.Errorf("second Drain() = %+v, want nil", again)
+	}
+}
+
+// TestInputQueue_DropsWhenFull verifies the queue is bounded.
+func TestInputQueue_DropsWhenFull(t *testing.T) {
+	// Arrange
+	queue := NewInputQueue()
+	for i := 0; i < MaxPendingInputs; i++ {
+		if !queue.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump}) {
+			t.Fatalf("Enqueue() #%d rejected before the queue was full", i)
+		}
+	}
+
+	// Act
+	accepted := queue.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump})
+
+	// Assert
+	if accepted {
+		t.Error("Enqueue() on a full queue = true, want false")
+	}
+	if got := len(queue.Drain()); got != MaxPendingInputs {
+		t.Errorf("Drain() returned %d commands, want %d", got, MaxPendingInputs)
+	}
+}
+
+// fakeSpawnNotifier records spawn events sent by the ticker.
+type fakeSpawnNotifier struct {
+	spawns   map[int]float64
+	rejected map[int]error
+}
+
+func (f *fakeSpawnNotifier) BroadcastState(gameState *GameState) {}
+
+func (f *fakeSpawnNotifier) SendSpawn(playerID int, x, y float64) {
+	f.spawns[playerID] = x
+}
+
+func (f *fakeSpawnNotifier) RejectRespawn(playerID int, reason error) {
+	f.rejected[playerID] = reason
+}
+
+// TestApplyInputs_JumpAndRespawn verifies queued inputs change player state
+// only when applied, and that respawns are confirmed to the client.
+func TestApplyInputs_JumpAndRespawn(t *testing.T) {
+	// Arrange
+	runner := NewPlayer(1, "Runner")
+	fallen := NewPlayer(2, "Fallen")
+	world := runningWorld(t, DefaultMatchConfig(), runner, fallen)
+	fallen.Kill()
+	notifier := &fakeSpawnNotifier{spawns: make(map[int]float64), rejected: make(map[int]error)}
+
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump})
+	world.Inputs.Enqueue(InputCommand{PlayerID: 2, Kind: InputRespawn})
+	if runner.VelocityY != 0 || fallen.IsAlive {
+		t.Fatal("queued inputs were applied before the tick")
+	}
+
+	// Act
+	applyInputs(world, notifier, time.Now())
+
+	// Assert
+	if runner.VelocityY != JumpVelocity {
+		t.Errorf("runner VelocityY = %.1f, want %.1f", runner.VelocityY, JumpVelocity)
+	}
+	if !fallen.IsAlive {
+		t.Error("fallen player not respawned")
+	}
+	if _, ok := notifier.spawns[2]; !ok {
+		t.Error("respawn not confirmed with SendSpawn")
+	}
+}
+
+// TestApplyInputs_RejectedRespawnIsAnswered verifies a refused respawn is
+// reported to the client instead of being silently dropped.
+func TestApplyInputs_RejectedRespawnIsAnswered(t *testing.T) {
+	// Arrange
+	config := DefaultMatchConfig()
+	config.Respawn = RespawnDisabled
+	runner := NewPlayer(1, "Runner")
+	fallen := NewPlayer(2, "Fallen")
+	world := runningWorld(t, config, runner, fallen)
+	fallen.Kill()
+	notifier := &fakeSpawnNotifier{spawns: make(map[int]float64), rejected: make(map[int]error)}
+
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputRespawn})
+	world.Inputs.Enqueue(InputCommand{PlayerID: 2, Kind: InputRespawn})
+
+	// Act
+	applyInputs(world, notifier, time.Now())
+
+	// Assert
+	if err := notifier.rejected[1]; err != ErrPlayerAlive {
+		t.Errorf("living player's respawn rejected with %v, want %v", err, ErrPlayerAlive)
+	}
+	if err := notifier.rejected[2]; err != ErrRespawnUnavailable {
+		t.Errorf("disabled respawn rejected with %v, want %v", err, ErrRespawnUnavailable)
+	}
+	if len(notifier.spawns) != 0 || fallen.IsAlive {
+		t.Errorf("spawns = %v, want none", notifier.spawns)
+	}
+}
+
+// stateReader is a Broadcaster that reads every player field, like the
+// network hub does when building a state message.
+type stateReader struct {
+	reads int
+}
+
+func (s *stateReader) BroadcastState(gameState *GameState) {
+	for _, player := range gameState.GetAllPlayers() {
+		if player.IsAlive && player.X >= 0 && player.Y >= 0 {
+			s.reads++
+		}
+	}
+}
+
+// TestStartGameTicker_ConcurrentInputs drives the ticker while many goroutines
+// join, jump, respawn and leave. Run with -race to verify the simulation
+// only mutates players from the ticker goroutine.
+func TestStartGameTicker_ConcurrentInputs(t *testing.T) {
+	// Arrange
+	world := NewWorld(&fakeChunkManager{})
+	config := DefaultMatchConfig()
+	config.CountdownTicks = 1
+	world.Match = NewMatch(config)
+	stop := StartGameTicker(context.Background(), world, &stateReader{})
+	defer stop()
+
+	// Act
+	var wg sync.WaitGroup
+	deadline := time.Now().Add(300 * time.Millisecond)
+	for client := 1; client <= 8; client++ {
+		wg.Add(1)
+		go func(playerID int) {
+			defer wg.Done()
+			world.State.AddPlayer(NewPlayer(playerID, "Racer"))
+			for time.Now().Before(deadline) {
+				world.Inputs.Enqueue(InputCommand{PlayerID: playerID, Kind: InputJump})
+				world.Inputs.Enqueue(InputCommand{PlayerID: playerID, Kind: InputRespawn})
+				world.Match.CurrentEvent()
+				time.Sleep(time.Millisecond)
+			}
+			world.State.RemovePlayer(playerID)
+		}(client)
+	}
+	wg.Wait()
+
+	// Assert
+	if count := world.State.GetPlayerCount(); count != 0 {
+		t.Errorf("GetPlayerCount() after all clients left = %d, want 0", count)
+	}
+}
+
+// TestApplyInputs_AcknowledgesSequence verifies applied inputs record their
+// sequence number and stale or duplicate inputs are dropped.
+func TestApplyInputs_AcknowledgesSequence(t *testing.T) {
+	// Arrange
+	runner := NewPlayer(1, "Runner")
+	world := runningWorld(t, DefaultMatchConfig(), runner)
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump, Seq: 5})
+
+	// Act
+	applyInputs(world, nil, time.Now())
+
+	// Assert
+	if runner.LastInputSeq != 5 {
+		t.Errorf("LastInputSeq = %d, want 5", runner.LastInputSeq)
+	}
+	if runner.VelocityY != JumpVelocity {
+		t.Errorf("VelocityY = %.1f, want %.1f", runner.VelocityY, JumpVelocity)
+	}
+
+	// Arrange: land, then replay an old input
+	runner.VelocityY = 0
+	runner.IsGrounded = true
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump, Seq: 5})
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump, Seq: 3})
+
+	// Act
+	applyInputs(world, nil, time.Now())
+
+	// Assert
+	if runner.VelocityY != 0 {
+		t.Error("stale input was applied")
+	}
+	if runner.LastInputSeq != 5 {
+		t.Errorf("LastInputSeq after stale inputs = %d, want 5", runner.LastInputSeq)
+	}
+}
+
+// TestApplyInputs_DisconnectFreezesPlayer verifies a disconnected player
+// stops moving until its client resumes.
+func TestApplyInputs_DisconnectFreezesPlayer(t *testing.T) {
+	// Arrange
+	held := NewPlayer(1, "Held")
+	world := runningWorld(t, DefaultMatchConfig(), held, NewPlayer(2, "Runner"))
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputDisconnect})
+
+	// Act
+	world.Step(1, nil)
+	frozenX := held.X
+	world.Step(5, nil)
+	stillX := held.X
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputResume})
+	world.Step(1, nil)
+
+	// Assert
+	if !held.IsAlive || stillX != frozenX {
+		t.Errorf("disconnected player moved from X=%.1f to %.1f, want frozen", frozenX, stillX)
+	}
+	if held.Disconnected || held.X <= stillX {
+		t.Errorf("resumed player at X=%.1f (Disconnected=%v), want moving again", held.X, held.Disconnected)
+	}
+}
@@ -0,0 +1,210 @@
+package game
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// MaxRewind is the furthest back in time a jump may be applied.
+	// Inputs older than this are applied at the current tick instead, so a
+	// client can't fake timestamps to rewrite more than a few ticks of history.
+	MaxRewind = 150 * time.Millisecond
+
+	// HistorySize is the number of past ticks kept per player.
+	// It covers MaxRewind at MaxTickRate plus the tick the input arrives in.
+	HistorySize = int(MaxRewind/(time.Second/MaxTickRate)) + 2
+)
+
+var (
+	// ErrRewindTooFar is returned when an input is older than MaxRewind.
+	ErrRewindTooFar = errors.New("input older than rewind window")
+
+	// ErrNoHistory is returned when no recorded tick covers the input time,
+	// e.g. right after spawning.
+	ErrNoHistory = errors.New("no history for input time")
+
+	// ErrCannotJump is returned when the player wasn't grounded at the input time.
+	ErrCannotJump = errors.New("player could not jump at input time")
+)
+
+// PhysicsSnapshot is a player's physics state at the end of a tick.
+type PhysicsSnapshot struct {
+	// Tick is the tick number the state was recorded at.
+	Tick uint64
+
+	// At is the server time the tick ran.
+	At time.Time
+
+	// X, Y and VelocityY are the player's position and vertical velocity.
+	X         float64
+	Y         float64
+	VelocityY float64
+
+	// IsGrounded indicates the player was standing on the ground.
+	IsGrounded bool
+}
+
+// stateHistory is a fixed-size ring buffer of a player's recent snapshots.
+// Only the ticker goroutine touches it.
+type stateHistory struct {
+	// snapshots holds up to HistorySize snapshots; next is the slot to write.
+	snapshots [HistorySize]PhysicsSnapshot
+	next      int
+	count     int
+}
+
+// record appends a snapshot, overwriting the oldest once full.
+func (h *stateHistory) record(snapshot PhysicsSnapshot) {
+	h.snapshots[h.next] = snapshot
+	h.next = (h.next + 1) % HistorySize
+	if h.count < HistorySize {
+		h.count++
+	}
+}
+
+// at returns the i-th most recent snapshot (0 = newest).
+func (h *stateHistory) at(i int) *PhysicsSnapshot {
+	return &h.snapshots[(h.next-1-i+HistorySize)%HistorySize]
+}
+
+// latestAtOrBefore returns the index (0 = newest) of the most recent snapshot
+// recorded at or before t.
+func (h *stateHistory) latestAtOrBefore(t time.Time) (int, bool) {
+	for i := 0; i < h.count; i++ {
+		if !h.at(i).At.After(t) {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+// reset forgets all snapshots (after a respawn teleports the player).
+func (h *stateHistory) reset() {
+	h.next = 0
+	h.count = 0
+}
+
+// recordSnapshot stores the player's physics state at the end of a tick.
+//
+// Parameters:
+//   - tick: The tick that just ran
+//   - at: The server time the tick ran
+func (p *Player) recordSnapshot(tick uint64, at time.Time) {
+	p.history.record(PhysicsSnapshot{
+		Tick:       tick,
+		At:         at,
+		X:          p.X,
+		Y:          p.Y,
+		VelocityY:  p.VelocityY,
+		IsGrounded: p.IsGrounded,
+	})
+}
+
+// collide kills a player who hit an obstacle, leaving the death pending.
+// For MaxRewind a late jump may still undo it; after that the ticker makes
+// it final with confirmDeaths.
+//
+// Parameters:
+//   - tick: The tick the collision happened in
+//   - at: The server time of that tick
+func (p *Player) collide(tick uint64, at time.Time) {
+	p.IsAlive = false
+	p.diedAt = at
+	p.deathTick = tick
+}
+
+// DeathPending reports whether the player died in a collision that a late
+// jump may still undo. Until the death is final the player is still racing.
+func (p *Player) DeathPending() bool {
+	return !p.diedAt.IsZero()
+}
+
+// restoreSnapshot puts the player back into a recorded physics state.
+func (p *Player) restoreSnapshot(snapshot PhysicsSnapshot) {
+	p.X = snapshot.X
+	p.Y = snapshot.Y
+	p.VelocityY = snapshot.VelocityY
+	p.IsGrounded = snapshot.IsGrounded
+}
+
+// rewindJump applies a jump at the tick the client pressed it, then
+// re-simulates the player forward to the present.
+//
+// The newest snapshot recorded at or before pressedAt is the state the jump
+// should have started from. The player is restored to it, jumps, and replays
+// every tick since with the same per-tick checks as World.step (physics,
+// obstacle collisions, the finish line), overwriting the history along the
+// way. If the replay hits an obstacle the player is left dead at that point,
+// with the death pending from that tick; if it crosses the finish line the
+// player is finished there. Either way they stop moving, and the remaining
+// history holds where they stopped.
+//
+// A player whose death is pending is revived if the jump was pressed before
+// the collision, and the replay decides whether they survive.
+//
+// Must only be called from the ticker goroutine, for an alive player or
+// one whose death is pending.
+//
+// Parameters:
+//   - player: The jumping player
+//   - pressedAt: When the client pressed jump
+//   - now: The current server time
+//   - chunks: Obstacle source for collisions during the replay (may be nil)
+//   - match: The race whose finish line the replay checks (may be nil)
+//   - config: The world's settings used to replay physics
+//
+// Returns:
+//   - int: Number of ticks rewound (0 means the jump applied at the present)
+//   - error: ErrRewindTooFar, ErrNoHistory or ErrCannotJump; the jump was not applied
+func rewindJump(player *Player, pressedAt, now time.Time, chunks ChunkManager, match *Match, config Config) (int, error) {
+	if pressedAt.After(now) {
+		pressedAt = now
+	}
+	if now.Sub(pressedAt) > MaxRewind {
+		return 0, ErrRewindTooFar
+	}
+
+	index, ok := player.history.latestAtOrBefore(pressedAt)
+	if !ok {
+		return 0, ErrNoHistory
+	}
+
+	start := *player.history.at(index)
+	if !start.IsGrounded {
+		return 0, ErrCannotJump
+	}
+	if player.DeathPending() {
+		if start.Tick >= player.deathTick {
+			return 0, ErrCannotJump
+		}
+		player.IsAlive = true
+		player.diedAt = time.Time{}
+	}
+
+	// Jump from the past state, then replay the ticks since
+	player.restoreSnapshot(start)
+	player.Jump()
+	for i := index - 1; i >= 0; i-- {
+		slot := player.history.at(i)
+		if player.IsAlive && !player.Finished {
+			updatePlayerPhysics(player, config)
+			if chunks != nil && checkObstacleCollision(player, chunks) {
+				player.collide(slot.Tick, slot.At)
+			} else if match != nil {
+				match.CheckFinish(player)
+			}
+		}
+
+		*slot = PhysicsSnapshot{
+			Tick:       slot.Tick,
+			At:         slot.At,
+			X:          player.X,
+			Y:          player.Y,
+			VelocityY:  player.VelocityY,
+			IsGrounded: player.IsGrounded,
+		}
+	}
+
+	return index, nil
+}
@@ -0,0 +1,285 @@
+package game
+
+import (
+	"errors"
+	"testing"
+	"time"
+	"vibe-runner-server/generation"
+)
+
+// lagTestStart is the server time of tick 1 in lag compensation tests.
+var lagTestStart = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+// tickTime returns the server time tick n ran at.
+func tickTime(n uint64) time.Time {
+	return lagTestStart.Add(time.Duration(n-1) * TickDuration)
+}
+
+// simulateTicks runs ticks first..last for one player the way the ticker does:
+// physics, obstacle collision, then a history snapshot.
+func simulateTicks(player *Player, chunks ChunkManager, first, last uint64) {
+	for tick := first; tick <= last; tick++ {
+		if !player.IsAlive {
+			return
+		}
+		updatePlayerPhysics(player, DefaultConfig())
+		if chunks != nil && checkObstacleCollision(player, chunks) {
+			player.Kill()
+			return
+		}
+		player.recordSnapshot(tick, tickTime(tick))
+	}
+}
+
+// TestRewindJump_MatchesOnTimeJump verifies a late jump ends up exactly where
+// the same jump would be had it arrived on time.
+func TestRewindJump_MatchesOnTimeJump(t *testing.T) {
+	// Arrange: the on-time player jumps at the start of tick 4
+	onTime := NewPlayer(1, "OnTime")
+	simulateTicks(onTime, nil, 1, 3)
+	onTime.Jump()
+	simulateTicks(onTime, nil, 4, 5)
+
+	// Arrange: the late player's jump (pressed just after tick 3) arrives before tick 6
+	late := NewPlayer(2, "Late")
+	simulateTicks(late, nil, 1, 5)
+	pressedAt := tickTime(3).Add(10 * time.Millisecond)
+
+	// Act
+	rewound, err := rewindJump(late, pressedAt, tickTime(6), nil, nil, DefaultConfig())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("rewindJump() error = %v", err)
+	}
+	if rewound != 2 {
+		t.Errorf("rewindJump() rewound %d ticks, want 2", rewound)
+	}
+	if late.X != onTime.X || late.Y != onTime.Y || late.VelocityY != onTime.VelocityY {
+		t.Errorf("rewound state = (%.1f, %.1f, v=%.1f), want (%.1f, %.1f, v=%.1f)",
+			late.X, late.Y, late.VelocityY, onTime.X, onTime.Y, onTime.VelocityY)
+	}
+	if newest := late.history.at(0); newest.Y != late.Y || newest.Tick != 5 {
+		t.Errorf("history not rewritten: newest snapshot = %+v", *newest)
+	}
+}
+
+// TestRewindJump_RejectsBeyondWindow verifies old timestamps can't rewrite history.
+func TestRewindJump_RejectsBeyondWindow(t *testing.T) {
+	// Arrange
+	player := NewPlayer(1, "Cheater")
+	simulateTicks(player, nil, 1, 10)
+	before := *player
+
+	// Act
+	_, err := rewindJump(player, tickTime(2), tickTime(11), nil, nil, DefaultConfig())
+
+	// Assert
+	if !errors.Is(err, ErrRewindTooFar) {
+		t.Errorf("rewindJump() error = %v, want ErrRewindTooFar", err)
+	}
+	if player.Y != before.Y || player.VelocityY != before.VelocityY {
+		t.Error("rejected rewind changed the player's state")
+	}
+}
+
+// TestRewindJump_Errors verifies rewinds without usable history are refused.
+func TestRewindJump_Errors(t *testing.T) {
+	t.Run("no history", func(t *testing.T) {
+		player := NewPlayer(1, "Fresh")
+
+		if _, err := rewindJump(player, tickTime(1), tickTime(2), nil, nil, DefaultConfig()); !errors.Is(err, ErrNoHistory) {
+			t.Errorf("rewindJump() error = %v, want ErrNoHistory", err)
+		}
+	})
+
+	t.Run("airborne at press time", func(t *testing.T) {
+		player := NewPlayer(1, "Jumper")
+		simulateTicks(player, nil, 1, 2)
+		player.Jump()
+		simulateTicks(player, nil, 3, 4)
+
+		if _, err := rewindJump(player, tickTime(3), tickTime(5), nil, nil, DefaultConfig()); !errors.Is(err, ErrCannotJump) {
+			t.Errorf("rewindJump() error = %v, want ErrCannotJump", err)
+		}
+	})
+}
+
+// TestRewindJump_ReplayCollides verifies the re-simulation checks obstacles,
+// so an earlier jump into an elevated obstacle is fatal, and the history after
+// the collision holds where the player died rather than the old timeline.
+func TestRewindJump_ReplayCollides(t *testing.T) {
+	// Arrange: a block floating 100px up sits where the rewound jump peaks.
+	// A grounded player passes beneath it.
+	player := NewPlayer(1, "Unlucky")
+	obstacleX := player.X + 15*PlayerSpeed*DeltaTime // player X at tick 15
+	chunks := &fakeChunkManager{obstacles: []generation.Obstacle{
+		{Type: generation.ObstacleTypeLow, X: obstacleX, Y: 100},
+	}}
+	simulateTicks(player, chunks, 1, 14)
+	if !player.IsAlive {
+		t.Fatal("test setup: grounded player hit the floating obstacle")
+	}
+
+	// Act: jump pressed at tick 11 arrives before tick 15
+	_, err := rewindJump(player, tickTime(11), tickTime(14), chunks, nil, DefaultConfig())
+
+	// Assert
+	if err != nil {
+		t.Fatalf("rewindJump() error = %v", err)
+	}
+	if player.IsAlive || !player.DeathPending() {
+		t.Fatal("rewound jump passed through an obstacle")
+	}
+	if player.deathTick >= 14 {
+		t.Fatalf("test setup: replay collided at tick %d, want before the newest", player.deathTick)
+	}
+	for i := 0; player.history.at(i).Tick > player.deathTick; i++ {
+		if slot := player.history.at(i); slot.X != player.X || slot.Y != player.Y {
+			t.Errorf("tick %d history = (%.1f, %.1f), want the collision point (%.1f, %.1f)",
+				slot.Tick, slot.X, slot.Y, player.X, player.Y)
+		}
+	}
+}
+
+// TestRespawn_ClearsHistory verifies a respawn can't rewind to the old position.
+func TestRespawn_ClearsHistory(t *testing.T) {
+	player := NewPlayer(1, "Runner")
+	simulateTicks(player, nil, 1, 3)
+
+	player.Respawn(SpawnX)
+
+	if _, err := rewindJump(player, tickTime(3), tickTime(4), nil, nil, DefaultConfig()); !errors.Is(err, ErrNoHistory) {
+		t.Errorf("rewindJump() after respawn error = %v, want ErrNoHistory", err)
+	}
+}
+
+// TestApplyJump_FallsBackToPresent verifies jumps that can't be rewound still
+// apply to the current state.
+func TestApplyJump_FallsBackToPresent(t *testing.T) {
+	// Arrange
+	runner := NewPlayer(1, "Runner")
+	world := runningWorld(t, DefaultMatchConfig(), runner)
+	simulateTicks(runner, nil, 1, 10)
+
+	// Act: pressed far outside the rewind window
+	applyJump(world, runner, tickTime(1), tickTime(11))
+
+	// Assert
+	if runner.VelocityY != JumpVelocity {
+		t.Errorf("VelocityY = %.1f, want present-time jump %.1f", runner.VelocityY, JumpVelocity)
+	}
+}
+
+// runIntoObstacle steps a running world until its only player hits an
+// obstacle sticking 20px out of the ground, which any jump clears.
+// It returns the world, the player and the server time of every tick run.
+func runIntoObstacle(t *testing.T, broadcaster Broadcaster, scores ScoreRecorder) (*World, *Player, map[uint64]time.Time) {
+	t.Helper()
+
+	runner := NewPlayer(1, "Runner")
+	world := runningWorld(t, DefaultMatchConfig(), runner)
+	world.Clock = NewManualClock(stepTestStart)
+	world.Scores = scores
+	world.Chunks.(*fakeChunkManager).obstacles = []generation.Obstacle{
+		{Type: generation.ObstacleTypeLow, X: SpawnX + 300, Y: -40},
+	}
+
+	tickTimes := make(map[uint64]time.Time)
+	for runner.IsAlive {
+		tick := world.Step(1, broadcaster)
+		tickTimes[tick] = world.Clock.Now()
+		if tick > 100 {
+			t.Fatal("test setup: runner never reached the obstacle")
+		}
+	}
+	return world, runner, tickTimes
+}
+
+// TestStep_LateJumpRevivesPendingDeath verifies a jump pressed before the
+// fatal tick but received after the server's collision still saves the
+// player, without a death being sent or a score recorded.
+func TestStep_LateJumpRevivesPendingDeath(t *testing.T) {
+	// Arrange
+	notifier := &fakeDeathNotifier{deaths: make(map[int]int)}
+	scores := &fakeScoreRecorder{}
+	world, runner, tickTimes := runIntoObstacle(t, notifier, scores)
+	deathTick := world.State.Tick()
+
+	// Act: the jump was pressed 2 ticks before the collision
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump, At: tickTimes[deathTick-2].Add(10 * time.Millisecond)})
+	world.Step(20, notifier)
+
+	// Assert
+	if !runner.IsAlive {
+		t.Fatal("late jump did not revive the player")
+	}
+	if runner.X <= SpawnX+360 {
+		t.Errorf("player X = %.1f, want past the obstacle", runner.X)
+	}
+	if len(notifier.deaths) != 0 {
+		t.Errorf("deaths sent = %v, want none", notifier.deaths)
+	}
+	if len(scores.scores) != 0 {
+		t.Errorf("scores recorded = %v, want none", scores.scores)
+	}
+}
+
+// TestStep_ConfirmsPendingDeathAfterRewindWindow verifies a collision only
+// becomes a death event and a recorded score once MaxRewind has passed.
+func TestStep_ConfirmsPendingDeathAfterRewindWindow(t *testing.T) {
+	// Arrange
+	notifier := &fakeDeathNotifier{deaths: make(map[int]int)}
+	scores := &fakeScoreRecorder{}
+	world, runner, _ := runIntoObstacle(t, notifier, scores)
+	if len(notifier.deaths) != 0 || len(scores.scores) != 0 {
+		t.Fatal("death confirmed on the collision tick, want it pending")
+	}
+
+	// Act
+	windowTicks := int(MaxRewind / world.Config.TickDuration())
+	world.Step(windowTicks-1, notifier)
+	pendingDeaths := len(notifier.deaths)
+	world.Step(1, notifier)
+
+	// Assert
+	if pendingDeaths != 0 {
+		t.Error("death confirmed inside the rewind window")
+	}
+	if score, ok := notifier.deaths[1]; !ok || score != runner.Score() {
+		t.Errorf("death event = %d (sent %v), want score %d", score, ok, runner.Score())
+	}
+	if scores.scores["Runner"] != runner.Score() {
+		t.Errorf("recorded score = %d, want %d", scores.scores["Runner"], runner.Score())
+	}
+	if runner.IsAlive || runner.DeathPending() {
+		t.Error("player not dead after the rewind window")
+	}
+}
+
+// TestStep_RewoundJumpCrossesFinish verifies a revived player who reaches the
+// finish line during the replay finishes there, with their score recorded.
+func TestStep_RewoundJumpCrossesFinish(t *testing.T) {
+	// Arrange: the finish line lies one tick past the collision, so only
+	// the replay of the pending tick can cross it
+	scores := &fakeScoreRecorder{}
+	world, runner, tickTimes := runIntoObstacle(t, nil, scores)
+	deathTick, deathX := world.State.Tick(), runner.X
+	world.Match.config.FinishDistance = deathX - SpawnX + 10
+	world.Step(1, nil)
+
+	// Act: the jump was pressed just before the collision tick
+	world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump, At: tickTimes[deathTick-1].Add(10 * time.Millisecond)})
+	world.Step(1, nil)
+
+	// Assert
+	if !runner.IsAlive || !runner.Finished {
+		t.Fatalf("alive = %v, finished = %v, want a finished player", runner.IsAlive, runner.Finished)
+	}
+	if want := deathX + world.Config.PlayerSpeed*world.Config.DeltaTime(); runner.X != want {
+		t.Errorf("player X = %.1f, want stopped at the finish tick's %.1f", runner.X, want)
+	}
+	if scores.scores["Runner"] != runner.Score() {
+		t.Errorf("recorded score = %d, want %d", scores.scores["Runner"], runner.Score())
+	}
+}
@@ -0,0 +1,118 @@
+package game
+
+import (
+	"sync"
+	"time"
+)
+
+// MaxCatchUpSteps is the most fixed steps the game loop runs in one wake.
+// After a stall longer than this many ticks the extra time is dropped, so a
+// slow server degrades to slow motion instead of a death spiral where every
+// catch-up makes the next wake even later.
+const MaxCatchUpSteps = 5
+
+// fixedStepper converts real elapsed time into a whole number of fixed
+// steps, carrying the remainder over to the next wake.
+type fixedStepper struct {
+	// tick is the real time covered by one step.
+	tick time.Duration
+
+	// pending is real time not yet simulated (always < tick between wakes).
+	pending time.Duration
+}
+
+// plan adds elapsed real time and returns how many steps to run now.
+//
+// Parameters:
+//   - elapsed: Real time since the previous wake
+//
+// Returns:
+//   - int: Steps to run (at most MaxCatchUpSteps)
+//   - uint64: Whole ticks dropped because they exceeded the catch-up cap
+func (f *fixedStepper) plan(elapsed time.Duration) (int, uint64) {
+	if elapsed > 0 {
+		f.pending += elapsed
+	}
+
+	steps := int(f.pending / f.tick)
+	var dropped uint64
+	if steps > MaxCatchUpSteps {
+		dropped = uint64(steps - MaxCatchUpSteps)
+		steps = MaxCatchUpSteps
+	}
+	f.pending %= f.tick
+
+	return steps, dropped
+}
+
+// TickStats summarizes how well the game loop keeps up with real time.
+type TickStats struct {
+	// Ticks is the number of fixed steps simulated.
+	Ticks uint64
+
+	// CatchUpTicks counts steps run in addition to the first one in a wake,
+	// i.e. ticks that were late and had to be caught up.
+	CatchUpTicks uint64
+
+	// DroppedTicks counts ticks skipped because the catch-up cap was reached.
+	DroppedTicks uint64
+
+	// Overruns counts steps whose processing took longer than a tick.
+	Overruns uint64
+
+	// LastStepDuration is the processing time of the most recent step.
+	LastStepDuration time.Duration
+
+	// MaxStepDuration is the longest processing time of any step.
+	MaxStepDuration time.Duration
+}
+
+// tickStatsRecorder accumulates TickStats from the ticker goroutine while
+// other goroutines read them.
+type tickStatsRecorder struct {
+	// mu protects stats.
+	mu    sync.Mutex
+	stats TickStats
+}
+
+// recordStep records the processing time of one fixed step.
+func (r *tickStatsRecorder) recordStep(took, tick time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stats.Ticks++
+	r.stats.LastStepDuration = took
+	if took > r.stats.MaxStepDuration {
+		r.stats.MaxStepDuration = took
+	}
+	if took > tick {
+		r.stats.Overruns++
+	}
+}
+
+// recordWake records how many steps one wake ran and how many it dropped.
+func (r *tickStatsRecorder) recordWake(steps int, dropped uint64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if steps > 1 {
+		r.stats.CatchUpTicks += uint64(steps - 1)
+	}
+	r.stats.DroppedTicks += dropped
+}
+
+// snapshot returns a copy of the current stats.
+func (r *tickStatsRecorder) snapshot() TickStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// TickStats returns the world's game loop statistics so far.
+// Safe to call from any goroutine.
+//
+// Returns:
+//   - TickStats: Step counts, catch-ups, drops and overruns
+func (w *World) TickStats() TickStats {
+	return w.tickStats.snapshot()
+}
@@ -0,0 +1,180 @@
+package game
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestFixedStepper_Plan verifies real elapsed time is turned into whole
+// fixed steps, with the remainder carried over and a catch-up cap.
+func TestFixedStepper_Plan(t *testing.T) {
+	tests := []struct {
+		name        string
+		elapsed     []time.Duration
+		wantSteps   int
+		wantDropped uint64
+		wantPending time.Duration
+	}{
+		{"on schedule", []time.Duration{TickDuration}, 1, 0, 0},
+		{"early wake", []time.Duration{TickDuration / 2}, 0, 0, TickDuration / 2},
+		{"remainder carries over", []time.Duration{TickDuration * 3 / 5, TickDuration * 3 / 5}, 1, 0, TickDuration / 5},
+		{"late wake catches up", []time.Duration{3*TickDuration + time.Millisecond}, 3, 0, time.Millisecond},
+		{"stall beyond cap drops ticks", []time.Duration{(MaxCatchUpSteps + 4) * TickDuration}, MaxCatchUpSteps, 4, 0},
+		{"clock going backwards is ignored", []time.Duration{-time.Second}, 0, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			stepper := fixedStepper{tick: TickDuration}
+
+			// Act
+			var steps int
+			var dropped uint64
+			for _, elapsed := range tt.elapsed {
+				steps, dropped = stepper.plan(elapsed)
+			}
+
+			// Assert
+			if steps != tt.wantSteps || dropped != tt.wantDropped {
+				t.Errorf("plan() = (%d, %d), want (%d, %d)", steps, dropped, tt.wantSteps, tt.wantDropped)
+			}
+			if stepper.pending != tt.wantPending {
+				t.Errorf("pending = %v, want %v", stepper.pending, tt.wantPending)
+			}
+		})
+	}
+}
+
+// TestTickStats_RecordsOverrunsAndCatchUp verifies the recorder's counters.
+func TestTickStats_RecordsOverrunsAndCatchUp(t *testing.T) {
+	// Arrange
+	world := NewWorld(nil)
+
+	// Act: one wake that ran three steps, one of them too slow, and dropped two
+	world.tickStats.recordStep(time.Millisecond, TickDuration)
+	world.tickStats.recordStep(TickDuration+time.Millisecond, TickDuration)
+	world.tickStats.recordStep(2*time.Millisecond, TickDuration)
+	world.tickStats.recordWake(3, 2)
+
+	// Assert
+	want := TickStats{
+		Ticks:            3,
+		CatchUpTicks:     2,
+		DroppedTicks:     2,
+		Overruns:         1,
+		LastStepDuration: 2 * time.Millisecond,
+		MaxStepDuration:  TickDuration + time.Millisecond,
+	}
+	if got := world.TickStats(); got != want {
+		t.Errorf("TickStats() = %+v, want %+v", got, want)
+	}
+}
+
+// TestStartGameTicker_KeepsRealTime verifies the loop simulates about as many
+// ticks as real time calls for.
+func TestStartGameTicker_KeepsRealTime(t *testing.T) {
+	// Arrange
+	world := NewWorld(nil)
+	stop := StartGameTicker(context.Background(), world, &stateReader{})
+
+	// Act
+	time.Sleep(10 * TickDuration)
+	stop()
+
+	// Assert: allow for scheduling jitter, but not a stalled or runaway loop
+	if ticks := world.TickStats().Ticks; ticks < 5 || ticks > 12 {
+		t.Errorf("Ticks after 10 tick durations = %d, want about 10", ticks)
+	}
+}
+
+// stepTestStart is the manual clock's start time in Step tests.
+var stepTestStart = time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+// TestStep_SimulatesWholeRace runs a race from countdown to results without
+// real time passing, and checks the finish is scored at the simulated time.
+func TestStep_SimulatesWholeRace(t *testing.T) {
+	// Arrange: a 1500px race is 100 running ticks at 15px per tick
+	world := NewWorld(&fakeChunkManager{})
+	world.Clock = NewManualClock(stepTestStart)
+	world.Match = NewMatch(MatchConfig{MinPlayers: 1, CountdownTicks: 3, ResultsTicks: 2, FinishDistance: 1500})
+	scores := &fakeScoreRecorder{}
+	world.Scores = scores
+	runner := NewPlayer(1, "Runner")
+	world.State.AddPlayer(runner)
+
+	// Act
+	var tick uint64
+	for world.Match.Phase() != PhaseResults && tick < 1000 {
+		tick = world.Step(1, &stateReader{})
+	}
+
+	// Assert
+	if world.Match.Phase() != PhaseResults {
+		t.Fatalf("phase after %d ticks = %s, want results", tick, world.Match.Phase())
+	}
+	if !runner.Finished {
+		t.Error("runner did not finish")
+	}
+	finishedAt, ok := scores.times["Runner"]
+	if !ok {
+		t.Fatal("finish score not recorded")
+	}
+	if !finishedAt.After(stepTestStart) || finishedAt.After(world.Clock.Now()) {
+		t.Errorf("score recorded at %v, want simulated time within the race", finishedAt)
+	}
+	if elapsed := world.Clock.Now().Sub(stepTestStart); elapsed != time.Duration(tick)*TickDuration {
+		t.Errorf("clock advanced %v, want %v", elapsed, time.Duration(tick)*TickDuration)
+	}
+}
+
+// TestStep_IsDeterministic verifies identical worlds given identical inputs
+// end up in identical states.
+func TestStep_IsDeterministic(t *testing.T) {
+	run := func() Player {
+		world := NewWorld(&fakeChunkManager{})
+		world.Clock = NewManualClock(stepTestStart)
+		runner := NewPlayer(1, "Runner")
+		world.State.AddPlayer(runner)
+
+		world.Step(DefaultMatchConfig().CountdownTicks+5, nil)
+		world.Inputs.Enqueue(InputCommand{PlayerID: 1, Kind: InputJump, Seq: 1, At: world.Clock.Now()})
+		world.Step(7, nil)
+		return *runner
+	}
+
+	first, second := run(), run()
+
+	if first.X != second.X || first.Y != second.Y || first.VelocityY != second.VelocityY {
+		t.Errorf("runs diverged: (%.2f, %.2f, v=%.2f) vs (%.2f, %.2f, v=%.2f)",
+			first.X, first.Y, first.VelocityY, second.X, second.Y, second.VelocityY)
+	}
+	if first.Y >= GroundY {
+		t.Error("jump was not applied")
+	}
+}
+
+// TestStartGameTicker_StopsOnCancel verifies cancelling the context ends the loop.
+func TestStartGameTicker_StopsOnCancel(t *testing.T) {
+	// Arrange
+	ctx, cancel := context.WithCancel(context.Background())
+	world := NewWorld(nil)
+	stop := StartGameTicker(ctx, world, &stateReader{})
+	defer stop()
+	time.Sleep(3 * TickDuration)
+
+	// Act
+	cancel()
+	time.Sleep(2 * TickDuration)
+	stopped := world.State.Tick()
+	time.Sleep(3 * TickDuration)
+
+	// Assert
+	if stopped == 0 {
+		t.Fatal("ticker never ran")
+	}
+	if ticks := world.State.Tick(); ticks != stopped {
+		t.Errorf("Tick() after cancel = %d, want it to stay at %d", ticks, stopped)
+	}
+}
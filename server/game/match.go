@@ -0,0 +1,367 @@
+package game
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MatchPhase is a stage of the race lifecycle.
+type MatchPhase int
+
+const (
+	// PhaseWaiting waits until enough players are present to start.
+	PhaseWaiting MatchPhase = iota
+
+	// PhaseCountdown counts down to the start. Physics is frozen.
+	PhaseCountdown
+
+	// PhaseRunning is the race itself. Physics and collisions are active.
+	PhaseRunning
+
+	// PhaseResults shows the final standings before the next race.
+	PhaseResults
+)
+
+// String returns the protocol name of the phase.
+func (p MatchPhase) String() string {
+	switch p {
+	case PhaseWaiting:
+		return "waiting"
+	case PhaseCountdown:
+		return "countdown"
+	case PhaseRunning:
+		return "running"
+	case PhaseResults:
+		return "results"
+	default:
+		return "unknown"
+	}
+}
+
+// SpawnX is the X position of the start line.
+const SpawnX = 100.0
+
+// RespawnMode selects where dead players come back when they ask to respawn.
+type RespawnMode int
+
+const (
+	// RespawnAtFrontier respawns players next to the leading alive player.
+	// Suited to endless races where everyone shares one moving frontier.
+	RespawnAtFrontier RespawnMode = iota
+
+	// RespawnAtStart respawns players at the start line (X=100).
+	RespawnAtStart
+
+	// RespawnDisabled keeps dead players out until the next race.
+	RespawnDisabled
+)
+
+// String returns the config name of the respawn mode.
+func (m RespawnMode) String() string {
+	switch m {
+	case RespawnAtFrontier:
+		return "frontier"
+	case RespawnAtStart:
+		return "start"
+	case RespawnDisabled:
+		return "disabled"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseRespawnMode returns the respawn mode with the given config name.
+//
+// Parameters:
+//   - name: "frontier", "start" or "disabled"
+//
+// Returns:
+//   - RespawnMode: The named mode
+//   - error: Non-nil if the name isn't a respawn mode
+func ParseRespawnMode(name string) (RespawnMode, error) {
+	for _, mode := range []RespawnMode{RespawnAtFrontier, RespawnAtStart, RespawnDisabled} {
+		if name == mode.String() {
+			return mode, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown respawn mode %q (want frontier, start or disabled)", name)
+}
+
+// MatchConfig controls the race lifecycle timing and finish condition.
+type MatchConfig struct {
+	// MinPlayers is the number of players needed to start the countdown.
+	MinPlayers int
+
+	// CountdownTicks is the length of the countdown phase in ticks.
+	CountdownTicks int
+
+	// ResultsTicks is how long results are shown before the next race, in ticks.
+	ResultsTicks int
+
+	// FinishDistance is the distance from spawn (pixels) that finishes the race.
+	// Zero means an endless race that only ends when every player is dead,
+	// or when the world empties if players can respawn.
+	FinishDistance float64
+
+	// Respawn selects where dead players respawn during the running phase.
+	Respawn RespawnMode
+
+	// TickRate converts phase lengths in ticks to time (0 means TickRate).
+	TickRate int
+}
+
+// tickDuration returns the real time of one tick at the match's tick rate.
+func (c MatchConfig) tickDuration() time.Duration {
+	if c.TickRate <= 0 {
+		return TickDuration
+	}
+	return time.Second / time.Duration(c.TickRate)
+}
+
+// DefaultMatchConfig returns the standard endless-race configuration:
+// a 3 second countdown as soon as one player is present, respawning at the
+// frontier while the race runs, and 5 seconds of results.
+//
+// Returns:
+//   - MatchConfig: Default configuration
+func DefaultMatchConfig() MatchConfig {
+	return MatchConfig{
+		MinPlayers:     1,
+		CountdownTicks: 3 * TickRate,
+		ResultsTicks:   5 * TickRate,
+		FinishDistance: 0,
+		Respawn:        RespawnAtFrontier,
+	}
+}
+
+// MatchResult is one player's final standing in a race.
+type MatchResult struct {
+	// PlayerID is the player's ID.
+	PlayerID int
+
+	// Name is the player's display name.
+	Name string
+
+	// Score is the distance travelled in pixels.
+	Score int
+
+	// Finished indicates the player reached the finish distance.
+	Finished bool
+}
+
+// MatchEvent describes the phase a match is in, for broadcasting to clients.
+type MatchEvent struct {
+	// Phase is the current phase.
+	Phase MatchPhase
+
+	// Remaining is the time left in the phase (countdown and results only).
+	Remaining time.Duration
+
+	// FinishDistance is the race length in pixels (0 for endless races).
+	FinishDistance float64
+
+	// Results are the final standings, best first (results phase only).
+	Results []MatchResult
+}
+
+// Match is the race lifecycle state machine for one world.
+// It moves through waiting -> countdown -> running -> results -> waiting.
+//
+// Advance is called once per tick by the game ticker; CurrentEvent may be
+// called from client goroutines. All access is protected by a mutex.
+type Match struct {
+	// config is the lifecycle configuration.
+	config MatchConfig
+
+	// phase is the current phase.
+	phase MatchPhase
+
+	// phaseTicks counts ticks spent in the current phase.
+	phaseTicks int
+
+	// results are the standings of the last finished race.
+	results []MatchResult
+
+	// mu protects all fields.
+	mu sync.Mutex
+}
+
+// NewMatch creates a match in the waiting phase.
+//
+// Parameters:
+//   - config: Lifecycle timing and finish condition
+//
+// Returns:
+//   - *Match: New match waiting for players
+func NewMatch(config MatchConfig) *Match {
+	return &Match{
+		config: config,
+		phase:  PhaseWaiting,
+	}
+}
+
+// Phase returns the current phase.
+func (m *Match) Phase() MatchPhase {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.phase
+}
+
+// Config returns the match configuration.
+func (m *Match) Config() MatchConfig {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.config
+}
+
+// CurrentEvent returns the event describing the current phase.
+// Used to tell newly joined clients where the match is.
+//
+// Returns:
+//   - MatchEvent: The current phase with remaining time and results
+func (m *Match) CurrentEvent() MatchEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.eventLocked()
+}
+
+// Advance runs one tick of the state machine.
+// On the countdown -> running transition every player is reset to the spawn
+// point so all racers start together.
+//
+// Parameters:
+//   - players: All players in the world
+//
+// Returns:
+//   - *MatchEvent: The new phase if a transition happened, nil otherwise
+func (m *Match) Advance(players []*Player) *MatchEvent {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.phaseTicks++
+
+	switch m.phase {
+	case PhaseWaiting:
+		if len(players) >= m.config.MinPlayers && len(players) > 0 {
+			return m.enterLocked(PhaseCountdown)
+		}
+
+	case PhaseCountdown:
+		if len(players) < m.config.MinPlayers || len(players) == 0 {
+			return m.enterLocked(PhaseWaiting)
+		}
+		if m.phaseTicks >= m.config.CountdownTicks {
+			for _, player := range players {
+				player.Respawn(SpawnX)
+			}
+			return m.enterLocked(PhaseRunning)
+		}
+
+	case PhaseRunning:
+		if m.raceOverLocked(players) {
+			m.results = buildResults(players)
+			return m.enterLocked(PhaseResults)
+		}
+
+	case PhaseResults:
+		if m.phaseTicks >= m.config.ResultsTicks {
+			m.results = nil
+			return m.enterLocked(PhaseWaiting)
+		}
+	}
+
+	return nil
+}
+
+// CheckFinish marks a player as finished if they reached the finish distance.
+// Finished players stop moving and count as done for ending the race.
+//
+// Parameters:
+//   - player: The player to check
+//
+// Returns:
+//   - bool: True if the player finished on this call
+func (m *Match) CheckFinish(player *Player) bool {
+	m.mu.Lock()
+	finishDistance := m.config.FinishDistance
+	m.mu.Unlock()
+
+	if finishDistance <= 0 || player.Finished {
+		return false
+	}
+	// Measured from the start line, so respawning doesn't move the finish
+	if player.X-SpawnX >= finishDistance {
+		player.Finished = true
+		return true
+	}
+	return false
+}
+
+// enterLocked switches to a new phase and returns its event.
+// The caller must hold m.mu.
+func (m *Match) enterLocked(phase MatchPhase) *MatchEvent {
+	m.phase = phase
+	m.phaseTicks = 0
+	event := m.eventLocked()
+	return &event
+}
+
+// eventLocked builds the event for the current phase.
+// The caller must hold m.mu.
+func (m *Match) eventLocked() MatchEvent {
+	event := MatchEvent{
+		Phase:          m.phase,
+		FinishDistance: m.config.FinishDistance,
+	}
+
+	switch m.phase {
+	case PhaseCountdown:
+		event.Remaining = time.Duration(m.config.CountdownTicks-m.phaseTicks) * m.config.tickDuration()
+	case PhaseResults:
+		event.Remaining = time.Duration(m.config.ResultsTicks-m.phaseTicks) * m.config.tickDuration()
+		event.Results = m.results
+	}
+
+	return event
+}
+
+// raceOverLocked reports whether every player is dead or has finished.
+// An endless race with respawning only ends once the world is empty, since
+// dead players can always come back. An empty world also ends any race.
+// The caller must hold m.mu.
+func (m *Match) raceOverLocked(players []*Player) bool {
+	if m.config.FinishDistance <= 0 && m.config.Respawn != RespawnDisabled {
+		return len(players) == 0
+	}
+	for _, player := range players {
+		// A pending death may still be undone by a late jump
+		if (player.IsAlive || player.DeathPending()) && !player.Finished {
+			return false
+		}
+	}
+	return true
+}
+
+// buildResults ranks players by score, best first.
+func buildResults(players []*Player) []MatchResult {
+	results := make([]MatchResult, 0, len(players))
+	for _, player := range players {
+		results = append(results, MatchResult{
+			PlayerID: player.ID,
+			Name:     player.Name,
+			Score:    player.Score(),
+			Finished: player.Finished,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].PlayerID < results[j].PlayerID
+	})
+
+	return results
+}
@@ -0,0 +1,206 @@
+package game
+
+import "testing"
+
+// testMatchConfig returns a short lifecycle for tests.
+func testMatchConfig() MatchConfig {
+	return MatchConfig{
+		MinPlayers:     1,
+		CountdownTicks: 3,
+		ResultsTicks:   2,
+		FinishDistance: 0,
+	}
+}
+
+// advanceN calls Advance n times and returns the last non-nil event.
+func advanceN(match *Match, players []*Player, n int) *MatchEvent {
+	var last *MatchEvent
+	for i := 0; i < n; i++ {
+		if event := match.Advance(players); event != nil {
+			last = event
+		}
+	}
+	return last
+}
+
+// TestNewMatch_StartsWaiting verifies a new match waits for players.
+func TestNewMatch_StartsWaiting(t *testing.T) {
+	match := NewMatch(testMatchConfig())
+
+	if match.Phase() != PhaseWaiting {
+		t.Errorf("NewMatch() phase = %s, want waiting", match.Phase())
+	}
+	if event := match.Advance(nil); event != nil {
+		t.Errorf("Advance() with no players = %s, want no transition", event.Phase)
+	}
+}
+
+// TestMatch_FullLifecycle walks a match through every phase.
+func TestMatch_FullLifecycle(t *testing.T) {
+	// Arrange: without respawning, one death ends the race
+	config := testMatchConfig()
+	config.Respawn = RespawnDisabled
+	match := NewMatch(config)
+	player := NewPlayer(1, "Runner")
+	players := []*Player{player}
+
+	// Waiting -> countdown as soon as a player is present
+	event := match.Advance(players)
+	if event == nil || event.Phase != PhaseCountdown {
+		t.Fatalf("Advance() = %v, want countdown", event)
+	}
+	if event.Remaining != 3*TickDuration {
+		t.Errorf("countdown Remaining = %v, want %v", event.Remaining, 3*TickDuration)
+	}
+
+	// Countdown -> running after CountdownTicks, resetting players to the start
+	player.X = 900
+	player.VelocityY = -600
+	event = advanceN(match, players, 3)
+	if event == nil || event.Phase != PhaseRunning {
+		t.Fatalf("after countdown phase = %s, want running", match.Phase())
+	}
+	if player.X != 100.0 || player.VelocityY != 0 || !player.IsGrounded {
+		t.Errorf("race start did not reset player: X=%.1f VelocityY=%.1f", player.X, player.VelocityY)
+	}
+
+	// Running continues while a player is alive
+	if event := match.Advance(players); event != nil {
+		t.Errorf("Advance() while alive = %s, want no transition", event.Phase)
+	}
+
+	// Running -> results once every player is dead
+	player.X = 1100
+	player.Kill()
+	event = match.Advance(players)
+	if event == nil || event.Phase != PhaseResults {
+		t.Fatalf("after death phase = %s, want results", match.Phase())
+	}
+	if len(event.Results) != 1 || event.Results[0].Score != 1000 {
+		t.Errorf("results = %+v, want one entry with score 1000", event.Results)
+	}
+
+	// Results -> waiting after ResultsTicks
+	event = advanceN(match, players, 2)
+	if event == nil || event.Phase != PhaseWaiting {
+		t.Fatalf("after results phase = %s, want waiting", match.Phase())
+	}
+}
+
+// TestMatch_EndlessRespawnRaceOutlivesDeaths verifies an endless race with
+// respawning keeps running while every player is dead, so they can respawn,
+// and only ends once the world is empty.
+func TestMatch_EndlessRespawnRaceOutlivesDeaths(t *testing.T) {
+	// Arrange
+	match := NewMatch(testMatchConfig())
+	player := NewPlayer(1, "Runner")
+	advanceN(match, []*Player{player}, 4) // waiting -> countdown -> running
+
+	// Act
+	player.Kill()
+	afterDeath := match.Advance([]*Player{player})
+	afterLeave := match.Advance(nil)
+
+	// Assert
+	if afterDeath != nil {
+		t.Errorf("Advance() after the only player died = %s, want the race to keep running", afterDeath.Phase)
+	}
+	if afterLeave == nil || afterLeave.Phase != PhaseResults {
+		t.Errorf("Advance() with an empty world phase = %s, want results", match.Phase())
+	}
+}
+
+// TestMatch_CountdownAbortsWhenPlayersLeave verifies the countdown returns
+// to waiting if players drop below the minimum.
+func TestMatch_CountdownAbortsWhenPlayersLeave(t *testing.T) {
+	match := NewMatch(testMatchConfig())
+	match.Advance([]*Player{NewPlayer(1, "Runner")})
+
+	event := match.Advance(nil)
+
+	if event == nil || event.Phase != PhaseWaiting {
+		t.Errorf("countdown with no players phase = %s, want waiting", match.Phase())
+	}
+}
+
+// TestMatch_MinPlayers verifies the countdown waits for enough players.
+func TestMatch_MinPlayers(t *testing.T) {
+	config := testMatchConfig()
+	config.MinPlayers = 2
+	match := NewMatch(config)
+
+	if event := match.Advance([]*Player{NewPlayer(1, "A")}); event != nil {
+		t.Errorf("Advance() with 1/2 players = %s, want no transition", event.Phase)
+	}
+	event := match.Advance([]*Player{NewPlayer(1, "A"), NewPlayer(2, "B")})
+	if event == nil || event.Phase != PhaseCountdown {
+		t.Errorf("Advance() with 2/2 players phase = %s, want countdown", match.Phase())
+	}
+}
+
+// TestMatch_FinishDistanceEndsRace verifies finished players end the race
+// and are ranked ahead of players who died.
+func TestMatch_FinishDistanceEndsRace(t *testing.T) {
+	// Arrange
+	config := testMatchConfig()
+	config.FinishDistance = 2000
+	match := NewMatch(config)
+	winner := NewPlayer(1, "Winner")
+	loser := NewPlayer(2, "Loser")
+	players := []*Player{winner, loser}
+	advanceN(match, players, 4) // waiting -> countdown -> running
+
+	// Act
+	loser.X = 600
+	loser.Kill()
+	winner.X = 1500
+	if match.CheckFinish(winner) {
+		t.Fatal("CheckFinish() finished player short of the line")
+	}
+	winner.X = 2100
+	finished := match.CheckFinish(winner)
+	event := match.Advance(players)
+
+	// Assert
+	if !finished || !winner.Finished {
+		t.Fatal("CheckFinish() did not finish player past the line")
+	}
+	if event == nil || event.Phase != PhaseResults {
+		t.Fatalf("phase = %s, want results", match.Phase())
+	}
+	if event.Results[0].PlayerID != 1 || !event.Results[0].Finished {
+		t.Errorf("results[0] = %+v, want finished winner first", event.Results[0])
+	}
+}
+
+// TestMatch_EndlessRaceNeverFinishes verifies CheckFinish is a no-op
+// without a finish distance.
+func TestMatch_EndlessRaceNeverFinishes(t *testing.T) {
+	match := NewMatch(testMatchConfig())
+	player := NewPlayer(1, "Runner")
+	player.X = 1000000
+
+	if match.CheckFinish(player) || player.Finished {
+		t.Error("CheckFinish() finished player in endless race")
+	}
+}
+
+// TestMatchPhase_String verifies protocol names of the phases.
+func TestMatchPhase_String(t *testing.T) {
+	tests := []struct {
+		phase MatchPhase
+		want  string
+	}{
+		{PhaseWaiting, "waiting"},
+		{PhaseCountdown, "countdown"},
+		{PhaseRunning, "running"},
+		{PhaseResults, "results"},
+		{MatchPhase(99), "unknown"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.phase.String(); got != tt.want {
+			t.Errorf("MatchPhase(%d).String() = %q, want %q", tt.phase, got, tt.want)
+		}
+	}
+}
@@ -1,5 +1,7 @@
 package game
 
+import "time"
+
 // Player represents a single player in the game world.
 // Each player has a unique ID, position, velocity, and state flags.
 //
@@ -41,10 +43,41 @@ type Player struct {
 	// Set to false when player collides with an obstacle.
 	// Dead players are excluded from state broadcasts.
 	IsAlive bool
+
+	// Finished indicates the player crossed the race's finish distance.
+	// Finished players stop moving until the next race starts.
+	Finished bool
+
+	// Disconnected indicates the player's client dropped and the player is
+	// held for it to resume. Disconnected players stop moving until their
+	// client resumes or the hold expires and they are removed.
+	Disconnected bool
+
+	// SpawnX is the X position the player last spawned at.
+	// Scores are measured from here, so respawning at the frontier
+	// doesn't credit distance the player never ran.
+	SpawnX float64
+
+	// LastInputSeq is the sequence number of the last input the ticker applied.
+	// Echoed to the client in state updates so client-side prediction can
+	// discard acknowledged inputs and replay the rest.
+	LastInputSeq uint32
+
+	// history holds the player's recent physics states for lag-compensated jumps.
+	// Only the game ticker touches it.
+	history stateHistory
+
+	// diedAt is when the player hit an obstacle, while that death is still
+	// pending (zero otherwise). A jump pressed before the collision and
+	// received within MaxRewind can still save the player.
+	diedAt time.Time
+
+	// deathTick is the tick of the pending death's collision.
+	deathTick uint64
 }
 
 // NewPlayer creates a new player with default spawn values.
-// The player spawns at position (100, 440) on the ground,
+// The player spawns at the start line (SpawnX, GroundY) on the ground,
 // with zero velocity and alive state.
 //
 // Parameters:
@@ -57,11 +90,12 @@ func NewPlayer(id int, name string) *Player {
 	return &Player{
 		ID:         id,
 		Name:       name,
-		X:          100.0,  // Spawn position X
-		Y:          440.0,  // Spawn at ground level
-		VelocityY:  0.0,    // No initial vertical velocity
-		IsGrounded: true,   // Start on ground
-		IsAlive:    true,   // Start alive
+		X:          SpawnX,  // Spawn at the start line
+		Y:          GroundY, // Spawn at ground level
+		VelocityY:  0.0,     // No initial vertical velocity
+		IsGrounded: true,    // Start on ground
+		IsAlive:    true,    // Start alive
+		SpawnX:     SpawnX,  // Score measured from spawn
 	}
 }
 
@@ -82,9 +116,40 @@ func (p *Player) Jump() {
 	}
 }
 
-// Kill marks the player as dead.
+// Kill marks the player as dead, making any pending death final.
 // Dead players are excluded from state broadcasts and cannot perform actions.
-// This is called when the player collides with an obstacle.
 func (p *Player) Kill() {
 	p.IsAlive = false
+	p.diedAt = time.Time{}
+}
+
+// Respawn resets the player to a spawn point on the ground, alive and unfinished.
+// The score restarts from the new spawn position.
+//
+// Parameters:
+//   - x: The X position to spawn at
+func (p *Player) Respawn(x float64) {
+	p.X = x
+	p.Y = GroundY
+	p.VelocityY = 0.0
+	p.IsGrounded = true
+	p.IsAlive = true
+	p.Finished = false
+	p.SpawnX = x
+	p.diedAt = time.Time{}
+	p.history.reset()
+}
+
+// Score returns the player's distance-based score in pixels.
+// The score is the horizontal distance travelled since spawning at SpawnX,
+// computed from server-side position so clients cannot inflate it.
+//
+// Returns:
+//   - int: Distance travelled in whole pixels (never negative)
+func (p *Player) Score() int {
+	distance := p.X - p.SpawnX
+	if distance < 0 {
+		return 0
+	}
+	return int(distance)
 }
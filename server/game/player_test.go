@@ -191,3 +191,62 @@ func TestJump_TableDriven(t *testing.T) {
 		})
 	}
 }
+
+// TestScore_IsDistanceFromSpawn tests that Score() returns whole pixels
+// travelled since the spawn position and never goes negative.
+func TestScore_IsDistanceFromSpawn(t *testing.T) {
+	tests := []struct {
+		name string
+		x    float64
+		want int
+	}{
+		{name: "at spawn", x: 100.0, want: 0},
+		{name: "travelled", x: 1350.7, want: 1250},
+		{name: "behind spawn", x: 50.0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			player := NewPlayer(1, "TestPlayer")
+			player.X = tt.x
+
+			if got := player.Score(); got != tt.want {
+				t.Errorf("Score() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRespawn_ResetsPlayerAndScore tests that Respawn revives the player on
+// the ground and measures the score from the new spawn point.
+func TestRespawn_ResetsPlayerAndScore(t *testing.T) {
+	// Arrange
+	player := NewPlayer(1, "TestPlayer")
+	player.X = 5000.0
+	player.Y = 300.0
+	player.VelocityY = 250.0
+	player.IsGrounded = false
+	player.Finished = true
+	player.Kill()
+
+	// Act
+	player.Respawn(4000.0)
+
+	// Assert
+	if !player.IsAlive || !player.IsGrounded || player.Finished {
+		t.Errorf("Respawn() state alive=%v grounded=%v finished=%v, want alive, grounded, unfinished",
+			player.IsAlive, player.IsGrounded, player.Finished)
+	}
+	if player.X != 4000.0 || player.Y != 440.0 || player.VelocityY != 0.0 {
+		t.Errorf("Respawn() position = (%.1f, %.1f) v=%.1f, want (4000.0, 440.0) v=0.0",
+			player.X, player.Y, player.VelocityY)
+	}
+	if player.Score() != 0 {
+		t.Errorf("Score() after Respawn = %d, want 0", player.Score())
+	}
+
+	player.X = 4300.0
+	if player.Score() != 300 {
+		t.Errorf("Score() = %d, want 300 measured from spawn", player.Score())
+	}
+}
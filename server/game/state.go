@@ -2,6 +2,7 @@ package game
 
 import (
 	"sync"
+	"time"
 )
 
 // GameState holds the authoritative game state for all connected players.
@@ -22,6 +23,12 @@ type GameState struct {
 	// Only alive players remain in this map.
 	// Dead players are removed on disconnect.
 	players map[int]*Player
+
+	// tick is the number of simulation ticks run so far.
+	tick uint64
+
+	// tickAt is the server time the current tick represents (zero if unknown).
+	tickAt time.Time
 }
 
 // NewGameState creates a new game state with an empty player list.
@@ -124,3 +131,51 @@ func (g *GameState) GetPlayerCount() int {
 	defer g.mu.RUnlock()
 	return len(g.players)
 }
+
+// AdvanceTick increments the simulation tick counter.
+// This is called by the game ticker once at the start of every tick.
+//
+// Returns:
+//   - uint64: The new tick number (the first tick is 1)
+func (g *GameState) AdvanceTick() uint64 {
+	return g.AdvanceTickAt(time.Time{})
+}
+
+// AdvanceTickAt increments the simulation tick counter and records the
+// server time the new tick represents, for clock synchronization.
+//
+// Parameters:
+//   - at: The server time of the new tick
+//
+// Returns:
+//   - uint64: The new tick number (the first tick is 1)
+func (g *GameState) AdvanceTickAt(at time.Time) uint64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.tick++
+	g.tickAt = at
+	return g.tick
+}
+
+// Tick returns the number of the current simulation tick.
+// State broadcasts carry it so clients can match updates to inputs.
+//
+// Returns:
+//   - uint64: The current tick number (0 before the first tick)
+func (g *GameState) Tick() uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.tick
+}
+
+// TickTime returns the current tick number and the server time it represents.
+// Clients combine them with the tick rate to align their own ticks.
+//
+// Returns:
+//   - uint64: The current tick number (0 before the first tick)
+//   - time.Time: The tick's server time (zero if not recorded)
+func (g *GameState) TickTime() (uint64, time.Time) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.tick, g.tickAt
+}
@@ -3,6 +3,7 @@ package game
 import (
 	"sync"
 	"testing"
+	"time"
 )
 
 // TestNewGameState_CreatesEmptyState verifies that NewGameState
@@ -291,3 +292,34 @@ func TestGameState_GetAllPlayers_ReturnsSnapshot(t *testing.T) {
 			len(snapshot), initialLen)
 	}
 }
+
+// TestGameState_AdvanceTick verifies the tick counter starts at zero and
+// increments once per call.
+func TestGameState_AdvanceTick(t *testing.T) {
+	gs := NewGameState()
+	if tick := gs.Tick(); tick != 0 {
+		t.Errorf("Tick() before first tick = %d, want 0", tick)
+	}
+
+	gs.AdvanceTick()
+	if tick := gs.AdvanceTick(); tick != 2 {
+		t.Errorf("AdvanceTick() = %d, want 2", tick)
+	}
+	if tick := gs.Tick(); tick != 2 {
+		t.Errorf("Tick() = %d, want 2", tick)
+	}
+}
+
+// TestGameState_TickTime verifies the tick time is recorded with each tick.
+func TestGameState_TickTime(t *testing.T) {
+	gs := NewGameState()
+	at := time.UnixMilli(1700000000050)
+
+	gs.AdvanceTick()
+	gs.AdvanceTickAt(at)
+
+	tick, tickAt := gs.TickTime()
+	if tick != 2 || !tickAt.Equal(at) {
+		t.Errorf("TickTime() = %d, %v, want 2, %v", tick, tickAt, at)
+	}
+}
@@ -1,8 +1,11 @@
 package game
 
 import (
+	"context"
 	"log"
+	"sync"
 	"time"
+	"vibe-runner-server/generation"
 )
 
 // Broadcaster is an interface for broadcasting game state to clients.
@@ -11,13 +14,41 @@ type Broadcaster interface {
 	BroadcastState(gameState *GameState)
 }
 
-// ChunkBroadcaster is an interface for broadcasting chunk data to clients.
+// ChunkBroadcaster is an interface for delivering level chunks to clients.
 // This extends Broadcaster to support procedural level generation in Phase 4.
+// The ticker calls it every tick; the implementation tracks which chunks
+// each client has, so late joiners and stragglers get the chunks around
+// their own player rather than the leader's.
 type ChunkBroadcaster interface {
 	Broadcaster
-	// BroadcastChunk sends a chunk to all connected clients
-	// The obstacles parameter uses the network.ObstacleData type
-	BroadcastChunk(chunkID int, obstacles interface{})
+	// SyncChunks sends every client the missing chunks covering its own
+	// player's position plus chunksAhead, and unload hints for chunks it passed
+	SyncChunks(gameState *GameState, chunks ChunkManager, chunksAhead int)
+}
+
+// DeathNotifier is an interface for notifying a single client that their player died.
+// The ticker type-asserts its Broadcaster to this interface when a collision occurs.
+type DeathNotifier interface {
+	// SendDeath sends a death event with the final score to one player's client
+	SendDeath(playerID int, score int)
+}
+
+// PhaseBroadcaster is an interface for broadcasting race phase transitions.
+// The ticker type-asserts its Broadcaster to this interface when the match changes phase.
+type PhaseBroadcaster interface {
+	// BroadcastPhase sends the new match phase to all connected clients
+	BroadcastPhase(event MatchEvent)
+}
+
+// LeaderboardNotifier is an interface for pushing the live leaderboard to clients.
+// The ticker type-asserts its Broadcaster to this interface after recording scores.
+type LeaderboardNotifier interface {
+	// RefreshLeaderboard pushes the leaderboard to all clients if the top entries
+	// changed, reporting whether it did
+	RefreshLeaderboard() bool
+
+	// SendLeaderboard pushes the leaderboard to one player's client
+	SendLeaderboard(playerID int, playerName string)
 }
 
 // ChunkManager is an interface for procedural chunk generation.
@@ -31,6 +62,15 @@ type ChunkManager interface {
 
 	// GetOrGenerateChunkInterface retrieves or generates a chunk by ID
 	GetOrGenerateChunkInterface(chunkID int) interface{}
+
+	// ObstaclesInRange returns already-generated obstacles with X in [startX, endX]
+	ObstaclesInRange(startX, endX float64) []generation.Obstacle
+
+	// Seed returns the master seed all chunks are derived from
+	Seed() string
+
+	// GeneratorVersion returns the version of the generation algorithm
+	GeneratorVersion() int
 }
 
 // Physics constants matching the Phase 1 client implementation.
@@ -52,130 +92,285 @@ const (
 	// PlayerHeight is the player's hitbox height in pixels
 	PlayerHeight = 60.0
 
-	// TickRate is the server update frequency (Hz)
+	// TickRate is the default server update frequency (Hz).
+	// A world's actual rate is its Config.TickRate.
 	TickRate = 20
 
-	// TickDuration is the time between ticks (50ms for 20Hz)
+	// TickDuration is the time between ticks at the default rate (50ms for 20Hz)
 	TickDuration = time.Second / TickRate
 
-	// DeltaTime is the time step for physics calculations (seconds)
+	// DeltaTime is the physics time step at the default rate (seconds)
 	// This is 0.05 seconds (50ms) for 20Hz
 	DeltaTime = 1.0 / float64(TickRate)
 
-	// PlayerSpeed is the constant horizontal movement speed (pixels/second)
+	// PlayerSpeed is the default horizontal movement speed (pixels/second)
 	// Phase 5: Players automatically move right at this speed
 	PlayerSpeed = 300.0
 )
 
 // StartGameTicker launches the main game loop in a goroutine.
-// The game loop runs at 20Hz (50ms per tick) and updates all player physics,
-// manages chunk generation/broadcasting, then broadcasts the updated state to all clients.
+// The game loop runs a fixed-timestep simulation at the world's tick rate
+// (20Hz by default, one Config.DeltaTime per step),
+// advances the race lifecycle, updates all player physics while the race is
+// running, manages chunk generation/broadcasting, then broadcasts the updated
+// state to all clients.
 //
-// The ticker performs these operations each tick:
-//  1. Gets all active players from game state
-//  2. Applies gravity to each player
-//  3. Updates vertical velocity and position
-//  4. Checks for ground collision
-//  5. Updates grounded state
-//  6. Generates chunks ahead of leading player
-//  7. Broadcasts new chunks to clients
-//  8. Cleans up old chunks behind all players
-//  9. Broadcasts state to all connected clients
-//
-// This function does not block. It launches a goroutine that runs indefinitely.
-// To stop the ticker, cancel the returned stop function (future enhancement).
+// The loop wakes on a time.Ticker but doesn't trust it to fire exactly every
+// TickDuration. Elapsed time on the world's Clock is added to an accumulator, and as many
+// fixed steps run as the accumulator holds, so a stall (GC, a slow broadcast)
+// is caught up instead of silently slowing the simulation. At most
+// MaxCatchUpSteps run per wake; time beyond that is dropped and counted in
+// the world's TickStats. State is broadcast once per wake, after catching up.
+//
+// Tests that need deterministic timing should call World.Step instead, and
+// never both on the same world.
+//
+// The ticker is the only goroutine that mutates players. Client goroutines
+// queue input commands on world.Inputs instead of touching players directly.
+//
+// This function does not block. It launches a goroutine that runs until ctx
+// is cancelled or the returned stop function is called. Calling stop more
+// than once is safe.
 //
 // Parameters:
-//   - gameState: The shared game state containing all players
-//   - broadcaster: The broadcaster for sending state and chunk updates to clients
-//   - chunkManager: The chunk manager for procedural level generation (nil to disable)
+//   - ctx: Stops the game loop when cancelled (e.g. on server shutdown)
+//   - world: The world (players, chunk manager, match) to simulate
+//   - broadcaster: The broadcaster for sending state, chunk and phase updates to clients
+//
+// Returns:
+//   - func(): Stops the game loop goroutine and waits for it to exit, so the
+//     caller may touch players afterwards
 //
 // The function logs tick rate information on startup.
-// In production, consider adding a context parameter for graceful shutdown.
-func StartGameTicker(gameState *GameState, broadcaster Broadcaster, chunkManager ChunkManager) {
-	log.Printf("Game ticker starting at %d Hz (%.1f ms per tick)", TickRate, float64(TickDuration.Milliseconds()))
+func StartGameTicker(ctx context.Context, world *World, broadcaster Broadcaster) func() {
+	tickDuration := world.Config.TickDuration()
+	log.Printf("Game ticker starting at %d Hz (%.1f ms per tick)", world.Config.TickRate, float64(tickDuration.Milliseconds()))
+
+	done := make(chan struct{})
+	exited := make(chan struct{})
+	var stopOnce sync.Once
+	stop := func() {
+		stopOnce.Do(func() { close(done) })
+		<-exited
+	}
 
 	// Launch ticker in separate goroutine
 	go func() {
-		// Create ticker for 20Hz updates (50ms intervals)
-		ticker := time.NewTicker(TickDuration)
-		defer ticker.Stop()
+		defer close(exited)
 
-		tickCount := 0
-		lastBroadcastedChunk := -1
+		// Wake every tick (50ms at 20Hz); the accumulator decides how many steps to run
+		ticker := time.NewTicker(tickDuration)
+		defer ticker.Stop()
 
-		// Main game loop - runs indefinitely
-		for range ticker.C {
-			tickCount++
+		stepper := fixedStepper{tick: tickDuration}
+		lastWake := world.Clock.Now()
+
+		// Main game loop - runs until stopped
+		for {
+			select {
+			case <-done:
+				log.Printf("Game ticker stopped after %d ticks", world.State.Tick())
+				return
+			case <-ctx.Done():
+				log.Printf("Game ticker cancelled after %d ticks: %v", world.State.Tick(), ctx.Err())
+				return
+			case <-ticker.C:
+			}
 
-			// Get all active players
-			players := gameState.GetAllPlayers()
+			now := world.Clock.Now()
+			steps, dropped := stepper.plan(now.Sub(lastWake))
+			lastWake = now
 
-			// Track player positions for chunk management
-			var maxPlayerX, minPlayerX float64
-			if len(players) > 0 {
-				maxPlayerX = players[0].X
-				minPlayerX = players[0].X
+			// Too far behind to catch up: drop the backlog rather than spiral
+			if dropped > 0 {
+				log.Printf("[Tick %d] Game loop fell behind, dropped %d ticks", world.State.Tick(), dropped)
 			}
 
-			// Update physics for each player
-			for _, player := range players {
-				// Only update alive players
-				if !player.IsAlive {
-					continue
-				}
-
-				// Apply physics update
-				updatePlayerPhysics(player)
-
-				// Track leading and trailing player positions
-				if player.X > maxPlayerX {
-					maxPlayerX = player.X
-				}
-				if player.X < minPlayerX {
-					minPlayerX = player.X
-				}
+			// Run every fixed step real time calls for, oldest first.
+			// Each step is stamped with the time it should have run at.
+			for i := 0; i < steps; i++ {
+				stepTime := now.Add(-stepper.pending - time.Duration(steps-1-i)*tickDuration)
+				stepStart := time.Now()
+				world.step(broadcaster, stepTime)
+				world.tickStats.recordStep(time.Since(stepStart), tickDuration)
 			}
+			world.tickStats.recordWake(steps, dropped)
 
-			// Phase 4: Chunk management (if chunk manager provided)
-			if chunkManager != nil && len(players) > 0 {
-				// Generate chunks ahead of leading player
-				// Generate 2 chunks ahead (within 2 screen widths as per spec)
-				chunkManager.GenerateAheadForPlayer(maxPlayerX, 2)
-
-				// Broadcast new chunks to clients
-				// Determine which chunk the leading player is approaching
-				leadingChunkID := int(maxPlayerX / 5000.0)
-
-				// Broadcast next chunk if we haven't sent it yet
-				nextChunkID := leadingChunkID + 1
-				if nextChunkID > lastBroadcastedChunk {
-					chunk := chunkManager.GetOrGenerateChunkInterface(nextChunkID)
-					if chunk != nil && broadcaster != nil {
-						// Type assert to ChunkBroadcaster if supported
-						if chunkBroadcaster, ok := broadcaster.(ChunkBroadcaster); ok {
-							chunkBroadcaster.BroadcastChunk(nextChunkID, chunk)
-							lastBroadcastedChunk = nextChunkID
-						}
-					}
-				}
-
-				// Cleanup old chunks (every 4 seconds = 80 ticks)
-				if tickCount%80 == 0 {
-					// Keep 1 chunk behind trailing player for safety
-					chunkManager.CleanupBehind(minPlayerX, 1)
-				}
+			// Broadcast state to all clients once per wake (20Hz when on schedule)
+			if steps > 0 {
+				broadcaster.BroadcastState(world.State)
 			}
+		}
+	}()
+
+	return stop
+}
+
+// Step advances the world by n fixed ticks synchronously, broadcasting state
+// after each one. It lets tests and tools run a simulation deterministically,
+// e.g. a whole race in milliseconds, and inspect the world between calls.
+// If the world's Clock is a ManualClock it is advanced by one tick before
+// every tick, so tick and score timestamps are reproducible.
+//
+// Step must not be used on a world driven by StartGameTicker.
+//
+// Parameters:
+//   - n: Number of ticks to run
+//   - broadcaster: The broadcaster for all outgoing events (may be nil)
+//
+// Returns:
+//   - uint64: The tick number after the last step
+func (w *World) Step(n int, broadcaster Broadcaster) uint64 {
+	manual, _ := w.Clock.(*ManualClock)
+
+	for i := 0; i < n; i++ {
+		if manual != nil {
+			manual.Advance(w.Config.TickDuration())
+		}
+		w.step(broadcaster, w.Clock.Now())
+		if broadcaster != nil {
+			broadcaster.BroadcastState(w.State)
+		}
+	}
+
+	return w.State.Tick()
+}
+
+// step advances the world by exactly one fixed tick of Config.DeltaTime.
+// It does not broadcast state; the caller does that after catching up.
+//
+// Each step performs these operations:
+//  0. Drains and applies queued input commands (jumps, respawns), rewinding
+//     jumps to when they were pressed (see rewindJump), then confirms deaths
+//     pending for MaxRewind, sending the death and recording the score
+//  1. Gets all active players from game state
+//  2. Advances the match and broadcasts any phase transition
+//  3. If the race is running, for each alive, unfinished player:
+//     applies gravity, updates velocity and position, checks ground collision,
+//     checks obstacle collisions (leaving players that hit one with a pending
+//     death) and the finish line, submitting final scores to the world's
+//     score recorder, and records the player's state in their history
+//  4. Pushes the live leaderboard after scores are recorded (and once a second)
+//  5. Generates chunks ahead of leading player
+//  6. Broadcasts new chunks to clients
+//  7. Cleans up old chunks behind all players
+//
+// Must only be called from the goroutine driving the world (the ticker, or
+// a test calling Step).
+//
+// Parameters:
+//   - broadcaster: The broadcaster for phase, chunk, death and leaderboard events (may be nil)
+//   - tickTime: The server time this tick represents
+func (w *World) step(broadcaster Broadcaster, tickTime time.Time) {
+	gameState, chunkManager, match := w.State, w.Chunks, w.Match
+
+	tickCount := gameState.AdvanceTickAt(tickTime)
+
+	// Apply inputs received since the last tick, in arrival order.
+	// A late jump may still save a player whose death is pending.
+	// Players whose final score is recorded this tick are collected
+	// for the leaderboard push.
+	recorded := applyInputs(w, broadcaster, tickTime)
+
+	// Deaths no late jump can undo any more become final.
+	recorded = append(recorded, w.confirmDeaths(broadcaster, tickTime)...)
 
-			// Broadcast state to all clients at 20Hz
-			broadcaster.BroadcastState(gameState)
+	// Get all active players
+	players := gameState.GetAllPlayers()
+
+	// Advance the race lifecycle and announce transitions
+	if event := match.Advance(players); event != nil {
+		log.Printf("[Tick %d] Match phase: %s", tickCount, event.Phase)
+
+		if phaseBroadcaster, ok := broadcaster.(PhaseBroadcaster); ok {
+			phaseBroadcaster.BroadcastPhase(*event)
+		}
+	}
+	running := match.Phase() == PhaseRunning
+
+	// Track player positions for chunk management
+	var maxPlayerX, minPlayerX float64
+	if len(players) > 0 {
+		maxPlayerX = players[0].X
+		minPlayerX = players[0].X
+	}
 
-			// Log debug info every 2 seconds (20 ticks/sec * 2 = 40 ticks)
-			if tickCount%40 == 0 {
-				log.Printf("[Tick %d] Active players: %d", tickCount, len(players))
+	// Update physics for each player (frozen outside the running phase)
+	for _, player := range players {
+		// A pending death keeps recording history, so a late jump can
+		// replay it up to the present
+		if running && player.DeathPending() {
+			player.recordSnapshot(tickCount, tickTime)
+			continue
+		}
+
+		// Only update alive, unfinished, connected players while racing
+		if !running || !player.IsAlive || player.Finished || player.Disconnected {
+			continue
+		}
+
+		// Apply physics update
+		updatePlayerPhysics(player, w.Config)
+
+		// Server-authoritative collision against generated obstacles.
+		// The death stays pending in case a late jump arrives.
+		if chunkManager != nil && checkObstacleCollision(player, chunkManager) {
+			player.collide(tickCount, tickTime)
+			player.recordSnapshot(tickCount, tickTime)
+			continue
+		}
+
+		// Finished players stop at the finish line
+		if match.CheckFinish(player) {
+			log.Printf("Player %d (%s) finished, score=%d", player.ID, player.Name, player.Score())
+			if w.recordScore(player) {
+				recorded = append(recorded, player)
 			}
 		}
-	}()
+
+		// Remember this tick for lag-compensated jumps
+		player.recordSnapshot(tickCount, tickTime)
+
+		// Track leading and trailing player positions
+		if player.X > maxPlayerX {
+			maxPlayerX = player.X
+		}
+		if player.X < minPlayerX {
+			minPlayerX = player.X
+		}
+	}
+
+	// Push the live leaderboard when scores change
+	// (and re-check once a second for scores recorded in other rooms)
+	if len(recorded) > 0 || tickCount%uint64(w.Config.TickRate) == 0 {
+		pushLeaderboard(broadcaster, recorded)
+	}
+
+	// Phase 4: Chunk management (if chunk manager provided)
+	if chunkManager != nil && len(players) > 0 {
+		// Generate chunks ahead of leading player
+		// (2 chunks by default, within 2 screen widths as per spec)
+		chunkManager.GenerateAheadForPlayer(maxPlayerX, w.Config.ChunksAhead)
+
+		// Send each client the chunks around its own player that it lacks
+		// (a new race moves everyone back to chunk 0, which is resent if unloaded)
+		if chunkBroadcaster, ok := broadcaster.(ChunkBroadcaster); ok {
+			chunkBroadcaster.SyncChunks(gameState, chunkManager, w.Config.ChunksAhead)
+		}
+
+		// Cleanup old chunks (every 4 seconds = 80 ticks by default)
+		if tickCount%uint64(w.Config.ChunkCleanupTicks) == 0 {
+			// Keep 1 chunk behind trailing player for safety
+			chunkManager.CleanupBehind(minPlayerX, 1)
+		}
+	}
+
+	// Log debug info every 2 seconds (20 ticks/sec * 2 = 40 ticks)
+	if tickCount%uint64(2*w.Config.TickRate) == 0 {
+		stats := w.TickStats()
+		log.Printf("[Tick %d] Active players: %d, overruns: %d, caught up: %d, dropped: %d",
+			tickCount, len(players), stats.Overruns, stats.CatchUpTicks, stats.DroppedTicks)
+	}
 }
 
 // updatePlayerPhysics applies physics calculations to a single player for one tick.
@@ -192,17 +387,20 @@ func StartGameTicker(gameState *GameState, broadcaster Broadcaster, chunkManager
 //
 // Parameters:
 //   - player: The player to update (modified in place)
+//   - config: The world's settings (tick rate and player speed)
 //
 // The function does not acquire any locks. The caller (game ticker) is
 // responsible for thread-safety when accessing player state.
-func updatePlayerPhysics(player *Player) {
+func updatePlayerPhysics(player *Player, config Config) {
+	dt := config.DeltaTime()
+
 	// Apply gravity to vertical velocity
 	// velocityY increases (more downward) each tick due to gravity
-	player.VelocityY += Gravity * DeltaTime
+	player.VelocityY += Gravity * dt
 
 	// Update vertical position based on velocity
 	// y increases (moves down) when velocityY is positive
-	player.Y += player.VelocityY * DeltaTime
+	player.Y += player.VelocityY * dt
 
 	// Check ground collision
 	if player.Y >= GroundY {
@@ -222,5 +420,67 @@ func updatePlayerPhysics(player *Player) {
 
 	// Horizontal movement (constant speed, no acceleration)
 	// Players move right at fixed speed (Phase 5)
-	player.X += PlayerSpeed * DeltaTime
+	player.X += config.PlayerSpeed * dt
+}
+
+// confirmDeaths makes final every pending death older than MaxRewind:
+// no jump the client pressed in time can still arrive to undo it.
+// The client is told, and the final score recorded.
+//
+// Parameters:
+//   - broadcaster: The broadcaster used to deliver death events (may be nil)
+//   - now: The server time of the current tick
+//
+// Returns:
+//   - []*Player: Players whose final score was recorded
+func (w *World) confirmDeaths(broadcaster Broadcaster, now time.Time) []*Player {
+	var recorded []*Player
+	for _, player := range w.State.GetAllPlayers() {
+		if !player.DeathPending() || now.Sub(player.diedAt) < MaxRewind {
+			continue
+		}
+		killPlayer(player, broadcaster)
+		if w.recordScore(player) {
+			recorded = append(recorded, player)
+		}
+	}
+	return recorded
+}
+
+// killPlayer marks a player as dead and notifies their client.
+// The death event is only sent if the broadcaster supports DeathNotifier.
+//
+// Parameters:
+//   - player: The player that collided with an obstacle
+//   - broadcaster: The broadcaster used to deliver the death event (may be nil)
+func killPlayer(player *Player, broadcaster Broadcaster) {
+	player.Kill()
+	score := player.Score()
+
+	log.Printf("Player %d (%s) died at X=%.1f, score=%d", player.ID, player.Name, player.X, score)
+
+	if notifier, ok := broadcaster.(DeathNotifier); ok {
+		notifier.SendDeath(player.ID, score)
+	}
+}
+
+// pushLeaderboard sends the live leaderboard to clients if the broadcaster
+// supports LeaderboardNotifier. Everyone gets it if the top entries changed;
+// otherwise only players whose own score was just recorded get their new rank.
+//
+// Parameters:
+//   - broadcaster: The broadcaster used to deliver the leaderboard (may be nil)
+//   - recorded: Players whose final score was recorded this tick
+func pushLeaderboard(broadcaster Broadcaster, recorded []*Player) {
+	notifier, ok := broadcaster.(LeaderboardNotifier)
+	if !ok {
+		return
+	}
+
+	if notifier.RefreshLeaderboard() {
+		return
+	}
+	for _, player := range recorded {
+		notifier.SendLeaderboard(player.ID, player.Name)
+	}
 }
@@ -0,0 +1,227 @@
+package game
+
+import (
+	"errors"
+	"log"
+	"time"
+)
+
+var (
+	// ErrPlayerNotFound is returned when a player is not in the world.
+	ErrPlayerNotFound = errors.New("player not found")
+
+	// ErrPlayerAlive is returned when a living player asks to respawn.
+	ErrPlayerAlive = errors.New("player is alive")
+
+	// ErrRespawnUnavailable is returned when respawning isn't allowed right now
+	// (outside the running phase, or the match disables respawning).
+	ErrRespawnUnavailable = errors.New("respawn unavailable")
+)
+
+// ScoreRecorder records final scores, e.g. to a persistent leaderboard.
+// This interface keeps the game package independent of score storage.
+type ScoreRecorder interface {
+	// Submit records a player's score achieved at the given time
+	Submit(name string, score int, at time.Time) error
+}
+
+// World bundles the shared state of a single running game world.
+// It is passed to each client connection so the handshake can report the
+// exact seed and generator version the server uses to build the level.
+type World struct {
+	// State holds all players in this world.
+	State *GameState
+
+	// Chunks is the chunk manager generating this world's level (nil to disable).
+	Chunks ChunkManager
+
+	// Seed is the master seed the chunk manager derives every chunk from.
+	Seed string
+
+	// GeneratorVersion identifies the level generation algorithm.
+	// Clients regenerating chunks locally must use the same version.
+	GeneratorVersion int
+
+	// Match is the race lifecycle (waiting, countdown, running, results).
+	Match *Match
+
+	// Config holds the simulation settings (tick rate, speed, chunk window).
+	// Set it through NewWorldWithConfig; it must not change once the world runs.
+	Config Config
+
+	// Scores records final scores on death or finish (nil to disable).
+	Scores ScoreRecorder
+
+	// Inputs queues player commands from client goroutines.
+	// The ticker drains it at the start of each tick, so it is the only
+	// goroutine that ever mutates players.
+	Inputs *InputQueue
+
+	// Clock is the time source for tick and score timestamps.
+	// NewWorld uses RealClock; tests substitute a ManualClock.
+	Clock Clock
+
+	// tickStats records how well the game loop keeps up with real time.
+	tickStats tickStatsRecorder
+}
+
+// NewWorld creates a world with an empty game state around a chunk manager,
+// using DefaultConfig.
+//
+// Parameters:
+//   - chunkManager: The chunk manager for procedural generation (nil to skip)
+//
+// Returns:
+//   - *World: New world ready for players
+func NewWorld(chunkManager ChunkManager) *World {
+	return NewWorldWithConfig(chunkManager, DefaultConfig())
+}
+
+// NewWorldWithConfig creates a world with an empty game state around a
+// chunk manager, simulated with the given settings.
+// The world's match uses the config's race settings (see Config.MatchConfig).
+// The seed and generator version are read from the chunk manager so the
+// values reported to clients always match the level actually generated.
+//
+// Parameters:
+//   - chunkManager: The chunk manager for procedural generation (nil to skip)
+//   - config: Simulation settings (see DefaultConfig)
+//
+// Returns:
+//   - *World: New world ready for players
+func NewWorldWithConfig(chunkManager ChunkManager, config Config) *World {
+	world := &World{
+		State:  NewGameState(),
+		Chunks: chunkManager,
+		Match:  NewMatch(config.MatchConfig()),
+		Config: config,
+		Inputs: NewInputQueue(),
+		Clock:  RealClock{},
+	}
+
+	if chunkManager != nil {
+		world.Seed = chunkManager.Seed()
+		world.GeneratorVersion = chunkManager.GeneratorVersion()
+	}
+
+	return world
+}
+
+// Frontier returns the X position of the leading alive, unfinished player.
+// Returns the start line (SpawnX) if nobody is running.
+//
+// Returns:
+//   - float64: The frontier X position
+func (w *World) Frontier() float64 {
+	frontier := SpawnX
+	for _, player := range w.State.GetAllPlayers() {
+		if player.IsAlive && !player.Finished && player.X > frontier {
+			frontier = player.X
+		}
+	}
+	return frontier
+}
+
+// FlushScores ends the run of every player still racing and records their
+// current scores, so a server shutdown mid-race doesn't lose them.
+// Pending deaths are made final and scored too.
+// Players are only racing in the running phase; other phases record nothing.
+//
+// The world's ticker must be stopped first: this mutates players.
+//
+// Returns:
+//   - int: Number of scores recorded
+func (w *World) FlushScores() int {
+	if w.Match == nil || w.Match.Phase() != PhaseRunning {
+		return 0
+	}
+
+	recorded := 0
+	for _, player := range w.State.GetAllPlayers() {
+		if (!player.IsAlive && !player.DeathPending()) || player.Finished {
+			continue
+		}
+		// A dead player is never scored again, so repeated flushes are harmless
+		player.Kill()
+		if w.recordScore(player) {
+			recorded++
+		}
+	}
+	return recorded
+}
+
+// RespawnPlayer brings a dead player back into the running race.
+// Depending on the match's RespawnMode, the player spawns at the start line
+// or at the frontier. Frontier spawns are moved back until clear of obstacles.
+// The player's score restarts from the new spawn position.
+//
+// Must only be called from the ticker goroutine; clients request a respawn
+// by queueing an InputRespawn command.
+//
+// Parameters:
+//   - playerID: The ID of the player to respawn
+//
+// Returns:
+//   - *Player: The respawned player
+//   - error: ErrPlayerNotFound, ErrPlayerAlive or ErrRespawnUnavailable
+func (w *World) RespawnPlayer(playerID int) (*Player, error) {
+	player := w.State.GetPlayer(playerID)
+	if player == nil {
+		return nil, ErrPlayerNotFound
+	}
+	// Until a pending death is final, the client still sees the player alive
+	if player.IsAlive || player.DeathPending() {
+		return nil, ErrPlayerAlive
+	}
+	if w.Match.Phase() != PhaseRunning {
+		return nil, ErrRespawnUnavailable
+	}
+
+	switch w.Match.Config().Respawn {
+	case RespawnAtStart:
+		player.Respawn(SpawnX)
+	case RespawnAtFrontier:
+		player.Respawn(w.clearSpawnX(w.Frontier()))
+	default:
+		return nil, ErrRespawnUnavailable
+	}
+
+	return player, nil
+}
+
+// clearSpawnX returns the nearest X at or behind x where a grounded player
+// doesn't overlap an obstacle, never going behind the start line.
+func (w *World) clearSpawnX(x float64) float64 {
+	if w.Chunks == nil {
+		return x
+	}
+
+	probe := NewPlayer(0, "")
+	for probe.X = x; probe.X > SpawnX; probe.X -= PlayerWidth {
+		if !checkObstacleCollision(probe, w.Chunks) {
+			return probe.X
+		}
+	}
+	return SpawnX
+}
+
+// recordScore submits a player's current score to the world's score recorder.
+// Zero scores and worlds without a recorder are skipped.
+//
+// Parameters:
+//   - player: The player whose run just ended (death or finish)
+//
+// Returns:
+//   - bool: True if the score was recorded
+func (w *World) recordScore(player *Player) bool {
+	score := player.Score()
+	if w.Scores == nil || score <= 0 {
+		return false
+	}
+
+	if err := w.Scores.Submit(player.Name, score, w.Clock.Now()); err != nil {
+		log.Printf("Failed to record score for player %d (%s): %v", player.ID, player.Name, err)
+		return false
+	}
+	return true
+}
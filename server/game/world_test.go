@@ -0,0 +1,332 @@
+package game
+
+import (
+	"testing"
+	"time"
+	"vibe-runner-server/generation"
+)
+
+// TestNewWorld_UsesChunkManagerSeed verifies the world reports the same seed
+// and generator version as its chunk manager.
+func TestNewWorld_UsesChunkManagerSeed(t *testing.T) {
+	// Arrange
+	chunks := &fakeChunkManager{}
+
+	// Act
+	world := NewWorld(chunks)
+
+	// Assert
+	if world.State == nil {
+		t.Fatal("NewWorld() State is nil")
+	}
+	if world.Seed != chunks.Seed() {
+		t.Errorf("NewWorld() Seed = %q, want %q", world.Seed, chunks.Seed())
+	}
+	if world.GeneratorVersion != chunks.GeneratorVersion() {
+		t.Errorf("NewWorld() GeneratorVersion = %d, want %d", world.GeneratorVersion, chunks.GeneratorVersion())
+	}
+}
+
+// TestNewWorld_NilChunkManager verifies a world can run without generation.
+func TestNewWorld_NilChunkManager(t *testing.T) {
+	// Act
+	world := NewWorld(nil)
+
+	// Assert
+	if world.State == nil {
+		t.Fatal("NewWorld(nil) State is nil")
+	}
+	if world.Seed != "" {
+		t.Errorf("NewWorld(nil) Seed = %q, want empty", world.Seed)
+	}
+}
+
+// runningWorld returns a world whose match is in the running phase.
+func runningWorld(t *testing.T, config MatchConfig, players ...*Player) *World {
+	t.Helper()
+
+	config.CountdownTicks = 1
+	world := NewWorld(&fakeChunkManager{})
+	world.Match = NewMatch(config)
+	for _, player := range players {
+		world.State.AddPlayer(player)
+	}
+	advanceN(world.Match, world.State.GetAllPlayers(), 2)
+	if world.Match.Phase() != PhaseRunning {
+		t.Fatalf("test setup: phase = %s, want running", world.Match.Phase())
+	}
+	return world
+}
+
+// TestRespawnPlayer_AtFrontier verifies dead players respawn at the leader.
+func TestRespawnPlayer_AtFrontier(t *testing.T) {
+	// Arrange
+	leader := NewPlayer(1, "Leader")
+	fallen := NewPlayer(2, "Fallen")
+	world := runningWorld(t, DefaultMatchConfig(), leader, fallen)
+	leader.X = 8000.0
+	fallen.X = 3000.0
+	fallen.Kill()
+
+	// Act
+	player, err := world.RespawnPlayer(2)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RespawnPlayer() error = %v", err)
+	}
+	if player.X != 8000.0 || !player.IsAlive {
+		t.Errorf("RespawnPlayer() X=%.1f alive=%v, want X=8000.0 alive", player.X, player.IsAlive)
+	}
+	if player.Score() != 0 {
+		t.Errorf("Score() after frontier respawn = %d, want 0", player.Score())
+	}
+}
+
+// TestRespawnPlayer_FrontierAvoidsObstacles verifies a frontier spawn is
+// moved back out of an obstacle.
+func TestRespawnPlayer_FrontierAvoidsObstacles(t *testing.T) {
+	// Arrange
+	leader := NewPlayer(1, "Leader")
+	fallen := NewPlayer(2, "Fallen")
+	world := runningWorld(t, DefaultMatchConfig(), leader, fallen)
+	world.Chunks = &fakeChunkManager{obstacles: []generation.Obstacle{
+		{Type: generation.ObstacleTypeLow, X: 7990, Y: 0},
+	}}
+	leader.X = 8000.0
+	fallen.Kill()
+
+	// Act
+	player, err := world.RespawnPlayer(2)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("RespawnPlayer() error = %v", err)
+	}
+	if checkObstacleCollision(player, world.Chunks) {
+		t.Errorf("RespawnPlayer() spawned inside an obstacle at X=%.1f", player.X)
+	}
+}
+
+// TestRespawnPlayer_AtStart verifies RespawnAtStart mode.
+func TestRespawnPlayer_AtStart(t *testing.T) {
+	config := DefaultMatchConfig()
+	config.Respawn = RespawnAtStart
+	leader := NewPlayer(1, "Leader")
+	fallen := NewPlayer(2, "Fallen")
+	world := runningWorld(t, config, leader, fallen)
+	leader.X = 8000.0
+	fallen.Kill()
+
+	player, err := world.RespawnPlayer(2)
+
+	if err != nil {
+		t.Fatalf("RespawnPlayer() error = %v", err)
+	}
+	if player.X != SpawnX {
+		t.Errorf("RespawnPlayer() X = %.1f, want %.1f", player.X, SpawnX)
+	}
+}
+
+// TestRespawnPlayer_Rejected verifies invalid respawn requests fail.
+func TestRespawnPlayer_Rejected(t *testing.T) {
+	disabled := DefaultMatchConfig()
+	disabled.Respawn = RespawnDisabled
+
+	tests := []struct {
+		name     string
+		config   MatchConfig
+		playerID int
+		kill     bool
+		wantErr  error
+	}{
+		{name: "unknown player", config: DefaultMatchConfig(), playerID: 99, kill: true, wantErr: ErrPlayerNotFound},
+		{name: "alive player", config: DefaultMatchConfig(), playerID: 2, kill: false, wantErr: ErrPlayerAlive},
+		{name: "respawn disabled", config: disabled, playerID: 2, kill: true, wantErr: ErrRespawnUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fallen := NewPlayer(2, "Fallen")
+			world := runningWorld(t, tt.config, NewPlayer(1, "Leader"), fallen)
+			if tt.kill {
+				fallen.Kill()
+			}
+
+			_, err := world.RespawnPlayer(tt.playerID)
+
+			if err != tt.wantErr {
+				t.Errorf("RespawnPlayer() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestRespawnPlayer_OutsideRunningPhase verifies respawns wait for a race.
+func TestRespawnPlayer_OutsideRunningPhase(t *testing.T) {
+	world := NewWorld(&fakeChunkManager{})
+	player := NewPlayer(1, "Fallen")
+	player.Kill()
+	world.State.AddPlayer(player)
+
+	if _, err := world.RespawnPlayer(1); err != ErrRespawnUnavailable {
+		t.Errorf("RespawnPlayer() while waiting error = %v, want ErrRespawnUnavailable", err)
+	}
+}
+
+// fakeScoreRecorder records submitted scores by player name.
+type fakeScoreRecorder struct {
+	scores map[string]int
+	times  map[string]time.Time
+}
+
+func (f *fakeScoreRecorder) Submit(name string, score int, at time.Time) error {
+	if f.scores == nil {
+		f.scores = make(map[string]int)
+		f.times = make(map[string]time.Time)
+	}
+	f.scores[name] = score
+	f.times[name] = at
+	return nil
+}
+
+// TestRecordScore_SubmitsPositiveScores verifies final scores reach the
+// recorder and empty runs are skipped.
+func TestRecordScore_SubmitsPositiveScores(t *testing.T) {
+	// Arrange
+	recorder := &fakeScoreRecorder{}
+	world := NewWorld(&fakeChunkManager{})
+	world.Scores = recorder
+	runner := NewPlayer(1, "Runner")
+	runner.X = SpawnX + 1234
+	idle := NewPlayer(2, "Idle")
+
+	// Act
+	world.recordScore(runner)
+	world.recordScore(idle)
+
+	// Assert
+	if recorder.scores["Runner"] != 1234 {
+		t.Errorf("recorded score = %d, want 1234", recorder.scores["Runner"])
+	}
+	if _, ok := recorder.scores["Idle"]; ok {
+		t.Error("recordScore() submitted a zero score")
+	}
+}
+
+// TestRecordScore_NilRecorder verifies worlds without a recorder are safe.
+func TestRecordScore_NilRecorder(t *testing.T) {
+	world := NewWorld(&fakeChunkManager{})
+	player := NewPlayer(1, "Runner")
+	player.X = SpawnX + 500
+
+	world.recordScore(player)
+}
+
+// TestFlushScores_RecordsRacingPlayers verifies a shutdown flush scores
+// everyone still racing exactly once, and skips finished runs.
+func TestFlushScores_RecordsRacingPlayers(t *testing.T) {
+	// Arrange
+	runner := NewPlayer(1, "Runner")
+	finisher := NewPlayer(2, "Finisher")
+	world := runningWorld(t, DefaultMatchConfig(), runner, finisher)
+	runner.X = SpawnX + 800
+	finisher.X = SpawnX + 900
+	finisher.Finished = true
+	recorder := &fakeScoreRecorder{}
+	world.Scores = recorder
+
+	// Act
+	first := world.FlushScores()
+	second := world.FlushScores()
+
+	// Assert
+	if first != 1 || second != 0 {
+		t.Errorf("FlushScores() = %d then %d, want 1 then 0", first, second)
+	}
+	if recorder.scores["Runner"] != 800 {
+		t.Errorf("flushed score = %d, want 800", recorder.scores["Runner"])
+	}
+	if _, ok := recorder.scores["Finisher"]; ok {
+		t.Error("FlushScores() re-submitted a finished run")
+	}
+}
+
+// fakeLeaderboardNotifier records leaderboard pushes from the ticker.
+type fakeLeaderboardNotifier struct {
+	topChanged bool
+	refreshes  int
+	sent       []int
+}
+
+func (f *fakeLeaderboardNotifier) BroadcastState(gameState *GameState) {}
+
+func (f *fakeLeaderboardNotifier) RefreshLeaderboard() bool {
+	f.refreshes++
+	return f.topChanged
+}
+
+func (f *fakeLeaderboardNotifier) SendLeaderboard(playerID int, playerName string) {
+	f.sent = append(f.sent, playerID)
+}
+
+// TestPushLeaderboard_SendsOwnRankWhenTopUnchanged verifies players whose
+// score was recorded still get their new rank if the top didn't change.
+func TestPushLeaderboard_SendsOwnRankWhenTopUnchanged(t *testing.T) {
+	tests := []struct {
+		name       string
+		topChanged bool
+		wantSent   int
+	}{
+		{name: "top changed", topChanged: true, wantSent: 0},
+		{name: "top unchanged", topChanged: false, wantSent: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			notifier := &fakeLeaderboardNotifier{topChanged: tt.topChanged}
+
+			pushLeaderboard(notifier, []*Player{NewPlayer(7, "Runner")})
+
+			if notifier.refreshes != 1 {
+				t.Errorf("RefreshLeaderboard() called %d times, want 1", notifier.refreshes)
+			}
+			if len(notifier.sent) != tt.wantSent {
+				t.Errorf("SendLeaderboard() called %d times, want %d", len(notifier.sent), tt.wantSent)
+			}
+		})
+	}
+}
+
+// fakeChunkBroadcaster records the chunk syncs requested by the ticker.
+type fakeChunkBroadcaster struct {
+	syncs       int
+	chunksAhead int
+}
+
+func (f *fakeChunkBroadcaster) BroadcastState(gameState *GameState) {}
+
+func (f *fakeChunkBroadcaster) SyncChunks(gameState *GameState, chunks ChunkManager, chunksAhead int) {
+	f.syncs++
+	f.chunksAhead = chunksAhead
+}
+
+// TestStep_SyncsChunksEveryTick verifies each tick asks the broadcaster to
+// deliver chunks with the world's lookahead.
+func TestStep_SyncsChunksEveryTick(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	config.ChunksAhead = 4
+	world := NewWorldWithConfig(&fakeChunkManager{}, config)
+	world.Clock = NewManualClock(stepTestStart)
+	world.State.AddPlayer(NewPlayer(1, "Runner"))
+	broadcaster := &fakeChunkBroadcaster{}
+
+	// Act
+	world.Step(3, broadcaster)
+
+	// Assert
+	if broadcaster.syncs != 3 || broadcaster.chunksAhead != 4 {
+		t.Errorf("SyncChunks() called %d times with chunksAhead %d, want 3 times with 4", broadcaster.syncs, broadcaster.chunksAhead)
+	}
+}
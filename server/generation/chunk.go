@@ -10,6 +10,11 @@ import (
 )
 
 const (
+	// GeneratorVersion identifies the chunk generation algorithm.
+	// It must be bumped whenever GenerateChunk would produce a different layout
+	// for the same seed, so clients regenerating chunks locally can detect a mismatch.
+	GeneratorVersion = 2
+
 	// ChunkSize is the width of each chunk in pixels (~5 screen widths).
 	ChunkSize = 5000.0
 
@@ -64,6 +69,8 @@ type Chunk struct {
 //  2. Initializes a PRNG with that seed
 //  3. Generates 3-8 obstacles with random types and positions
 //  4. Ensures obstacles are spaced appropriately
+//  5. Drops or respaces any obstacle CheckSolvable would reject, so every
+//     chunk can be cleared (see makeSolvable)
 //
 // Parameters:
 //   - masterSeed: The global seed for the entire game session
@@ -129,6 +136,6 @@ func GenerateChunk(masterSeed string, chunkID int) *Chunk {
 
 	return &Chunk{
 		ID:        chunkID,
-		Obstacles: obstacles,
+		Obstacles: makeSolvable(obstacles, chunkEndX),
 	}
 }
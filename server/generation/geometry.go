@@ -0,0 +1,262 @@
+package generation
+
+import (
+	"fmt"
+	"math"
+)
+
+// Jump physics used by the solvability rules. These mirror the game package's
+// constants (which imports this package, so can't be imported here); a test
+// keeps them in step. Solvability is judged at the default player speed.
+const (
+	// JumpSpeed is the player's upward launch speed in pixels/second.
+	JumpSpeed = 600.0
+
+	// Gravity is the downward acceleration in pixels/second².
+	Gravity = 1200.0
+
+	// RunSpeed is the default horizontal player speed in pixels/second.
+	RunSpeed = 300.0
+
+	// PlayerWidth is the player's hitbox width in pixels.
+	PlayerWidth = 40.0
+
+	// MaxClearableHeight is the jump apex, the tallest obstacle a grounded
+	// player can jump over: JumpSpeed² / (2 * Gravity) = 150px.
+	MaxClearableHeight = JumpSpeed * JumpSpeed / (2 * Gravity)
+
+	// JumpDistance is how far the player runs during one jump:
+	// RunSpeed * 2 * JumpSpeed / Gravity = 300px.
+	JumpDistance = RunSpeed * 2 * JumpSpeed / Gravity
+)
+
+// Box is an axis-aligned hitbox relative to an obstacle's anchor point.
+// The anchor is the obstacle's X position at its base (Obstacle.Y above the ground).
+// OffsetX grows to the right and OffsetY grows upward from the base.
+type Box struct {
+	// OffsetX is the distance from the obstacle's X to the box's left edge.
+	OffsetX float64
+
+	// OffsetY is the distance from the obstacle's base to the box's bottom edge.
+	OffsetY float64
+
+	// Width is the horizontal size of the box in pixels.
+	Width float64
+
+	// Height is the vertical size of the box in pixels.
+	Height float64
+}
+
+// ObstacleGeometry describes the physical shape of an obstacle type.
+// It is the single source of truth for collision, solvability checks
+// and the obstacle sizes sent to clients.
+type ObstacleGeometry struct {
+	// Boxes are the hitboxes making up the obstacle (at least one).
+	Boxes []Box
+
+	// Jumpable indicates whether a grounded player can clear the obstacle by jumping.
+	Jumpable bool
+}
+
+// geometries maps obstacle types to their geometry.
+// Sizes match the sprites rendered by the client.
+var geometries = map[int]ObstacleGeometry{
+	ObstacleTypeTall: {
+		Boxes:    []Box{{OffsetX: 0, OffsetY: 0, Width: 40, Height: 100}},
+		Jumpable: true,
+	},
+	ObstacleTypeLow: {
+		Boxes:    []Box{{OffsetX: 0, OffsetY: 0, Width: 60, Height: 60}},
+		Jumpable: true,
+	},
+	ObstacleTypeSpike: {
+		Boxes:    []Box{{OffsetX: 0, OffsetY: 0, Width: 30, Height: 80}},
+		Jumpable: true,
+	},
+}
+
+// GeometryFor returns the geometry registered for an obstacle type.
+//
+// Parameters:
+//   - obstacleType: One of the ObstacleType* constants
+//
+// Returns:
+//   - ObstacleGeometry: The obstacle's geometry
+//   - bool: False if the type is not registered
+func GeometryFor(obstacleType int) (ObstacleGeometry, bool) {
+	geometry, ok := geometries[obstacleType]
+	return geometry, ok
+}
+
+// Width returns the overall width of the obstacle's bounding box.
+func (g ObstacleGeometry) Width() float64 {
+	width := 0.0
+	for _, box := range g.Boxes {
+		if right := box.OffsetX + box.Width; right > width {
+			width = right
+		}
+	}
+	return width
+}
+
+// Height returns the overall height of the obstacle's bounding box.
+func (g ObstacleGeometry) Height() float64 {
+	height := 0.0
+	for _, box := range g.Boxes {
+		if top := box.OffsetY + box.Height; top > height {
+			height = top
+		}
+	}
+	return height
+}
+
+// MaxObstacleWidth returns the widest registered obstacle in pixels.
+// Collision queries use this to include obstacles that start behind a player
+// but still overlap them.
+//
+// Returns:
+//   - float64: Width of the widest obstacle type
+func MaxObstacleWidth() float64 {
+	maxWidth := 0.0
+	for _, geometry := range geometries {
+		if width := geometry.Width(); width > maxWidth {
+			maxWidth = width
+		}
+	}
+	return maxWidth
+}
+
+// CheckSolvable verifies that a sequence of obstacles can be cleared by a player.
+// Every obstacle must have registered geometry, be jumpable and be clearable in
+// one jump (see checkClearable), and consecutive obstacles must leave room to
+// land after one and jump the next (see landingGap).
+//
+// Parameters:
+//   - obstacles: Obstacles sorted by ascending X
+//
+// Returns:
+//   - error: Non-nil describing the first unsolvable obstacle
+func CheckSolvable(obstacles []Obstacle) error {
+	for i, obstacle := range obstacles {
+		if err := checkClearable(obstacle); err != nil {
+			return fmt.Errorf("obstacle %d %w", i, err)
+		}
+
+		if i == 0 {
+			continue
+		}
+		need := landingGap(obstacles[i-1], obstacle)
+		if gap := obstacle.X - obstacleEnd(obstacles[i-1]); gap < need {
+			return fmt.Errorf("obstacle %d at X=%.1f leaves %.1fpx landing gap, need %.1fpx",
+				i, obstacle.X, gap, need)
+		}
+	}
+
+	return nil
+}
+
+// checkClearable verifies a single obstacle can be jumped by a grounded player:
+// it must be lower than the jump apex, and the player must stay above it for
+// the whole distance its hitbox overlaps the obstacle.
+//
+// Returns:
+//   - error: Non-nil describing why the obstacle can't be cleared
+func checkClearable(obstacle Obstacle) error {
+	geometry, ok := GeometryFor(obstacle.Type)
+	if !ok {
+		return fmt.Errorf("at X=%.1f has unknown type %d", obstacle.X, obstacle.Type)
+	}
+	if !geometry.Jumpable {
+		return fmt.Errorf("at X=%.1f (type %d) is not jumpable", obstacle.X, obstacle.Type)
+	}
+	top := obstacle.Y + geometry.Height()
+	if top > MaxClearableHeight {
+		return fmt.Errorf("at X=%.1f is %.1fpx tall, max clearable is %.1fpx", obstacle.X, top, MaxClearableHeight)
+	}
+	if span, need := airborneDistance(top), geometry.Width()+PlayerWidth; span < need {
+		return fmt.Errorf("at X=%.1f is %.1fpx wide at %.1fpx tall, a jump only clears %.1fpx",
+			obstacle.X, geometry.Width(), top, span-PlayerWidth)
+	}
+	return nil
+}
+
+// riseDistance returns how far the player runs between taking off and
+// reaching height pixels. The height t seconds after takeoff is
+// JumpSpeed*t - Gravity*t²/2, which first reaches h at
+// t = (JumpSpeed/Gravity) * (1 - sqrt(1 - h/MaxClearableHeight)).
+func riseDistance(height float64) float64 {
+	return RunSpeed * JumpSpeed / Gravity * (1 - math.Sqrt(1-height/MaxClearableHeight))
+}
+
+// airborneDistance returns how far the player runs while at least height
+// pixels up during one jump.
+func airborneDistance(height float64) float64 {
+	return JumpDistance - 2*riseDistance(height)
+}
+
+// landingGap returns the clear distance needed between two obstacles to
+// land after the first and jump the second.
+//
+// The earliest a player can land after clearing an obstacle is
+// riseDistance(its height) past its end, since the jump is symmetric. The
+// latest they can take off for the next obstacle is riseDistance(its height)
+// plus PlayerWidth before its start, so the hitbox is already high enough
+// when it reaches the obstacle.
+//
+// Parameters:
+//   - previous: The obstacle cleared first (must have known geometry)
+//   - next: The obstacle after it (must have known geometry)
+//
+// Returns:
+//   - float64: Minimum distance in pixels from previous's end to next's start
+func landingGap(previous, next Obstacle) float64 {
+	return riseDistance(obstacleTop(previous)) + riseDistance(obstacleTop(next)) + PlayerWidth
+}
+
+// obstacleTop returns the height of an obstacle's top above the ground.
+func obstacleTop(obstacle Obstacle) float64 {
+	geometry, _ := GeometryFor(obstacle.Type)
+	return obstacle.Y + geometry.Height()
+}
+
+// obstacleEnd returns the X coordinate of an obstacle's right edge.
+func obstacleEnd(obstacle Obstacle) float64 {
+	geometry, _ := GeometryFor(obstacle.Type)
+	return obstacle.X + geometry.Width()
+}
+
+// makeSolvable turns generated obstacles into a layout that passes
+// CheckSolvable: obstacles a player can't clear are dropped, and obstacles
+// too close to the previous one are pushed back to leave the landing gap.
+// Obstacles pushed to or past limitX are dropped.
+//
+// Parameters:
+//   - obstacles: Candidate obstacles sorted by ascending X (reused for the result)
+//   - limitX: X coordinate obstacles must start before
+//
+// Returns:
+//   - []Obstacle: The solvable obstacles, sorted by ascending X
+func makeSolvable(obstacles []Obstacle, limitX float64) []Obstacle {
+	solvable := obstacles[:0]
+	for _, obstacle := range obstacles {
+		if checkClearable(obstacle) != nil {
+			continue
+		}
+
+		if len(solvable) > 0 {
+			previous := solvable[len(solvable)-1]
+			end, gap := obstacleEnd(previous), landingGap(previous, obstacle)
+			if obstacle.X-end < gap {
+				obstacle.X = end + gap
+				if obstacle.X-end < gap {
+					obstacle.X = math.Nextafter(obstacle.X, math.Inf(1)) // Undo rounding down
+				}
+			}
+		}
+		if obstacle.X >= limitX {
+			break
+		}
+		solvable = append(solvable, obstacle)
+	}
+	return solvable
+}
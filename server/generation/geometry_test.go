@@ -0,0 +1,166 @@
+package generation_test
+
+import (
+	"testing"
+
+	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
+)
+
+// TestGeometryFor_AllObstacleTypesRegistered verifies every obstacle type has geometry.
+func TestGeometryFor_AllObstacleTypesRegistered(t *testing.T) {
+	tests := []struct {
+		name       string
+		typ        int
+		wantWidth  float64
+		wantHeight float64
+	}{
+		{name: "tall", typ: generation.ObstacleTypeTall, wantWidth: 40, wantHeight: 100},
+		{name: "low", typ: generation.ObstacleTypeLow, wantWidth: 60, wantHeight: 60},
+		{name: "spike", typ: generation.ObstacleTypeSpike, wantWidth: 30, wantHeight: 80},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			geometry, ok := generation.GeometryFor(tt.typ)
+
+			// Assert
+			if !ok {
+				t.Fatalf("GeometryFor(%d) not registered", tt.typ)
+			}
+			if len(geometry.Boxes) == 0 {
+				t.Error("geometry has no boxes")
+			}
+			if geometry.Width() != tt.wantWidth {
+				t.Errorf("Width() = %.1f, want %.1f", geometry.Width(), tt.wantWidth)
+			}
+			if geometry.Height() != tt.wantHeight {
+				t.Errorf("Height() = %.1f, want %.1f", geometry.Height(), tt.wantHeight)
+			}
+		})
+	}
+}
+
+// TestGeometryFor_UnknownType verifies unknown types are not registered.
+func TestGeometryFor_UnknownType(t *testing.T) {
+	if _, ok := generation.GeometryFor(99); ok {
+		t.Error("GeometryFor(99) returned ok=true, want false")
+	}
+}
+
+// TestMaxObstacleWidth_ReturnsWidestType verifies the widest obstacle is reported.
+func TestMaxObstacleWidth_ReturnsWidestType(t *testing.T) {
+	if got := generation.MaxObstacleWidth(); got != 60 {
+		t.Errorf("MaxObstacleWidth() = %.1f, want 60.0", got)
+	}
+}
+
+// TestCheckSolvable_RejectsUnsolvableLayouts verifies the solvability rules.
+func TestCheckSolvable_RejectsUnsolvableLayouts(t *testing.T) {
+	tests := []struct {
+		name      string
+		obstacles []generation.Obstacle
+		wantErr   bool
+	}{
+		{
+			name:      "empty",
+			obstacles: nil,
+			wantErr:   false,
+		},
+		{
+			name: "well spaced",
+			obstacles: []generation.Obstacle{
+				{Type: generation.ObstacleTypeLow, X: 1000},
+				{Type: generation.ObstacleTypeTall, X: 1400},
+			},
+			wantErr: false,
+		},
+		{
+			name: "no landing gap",
+			obstacles: []generation.Obstacle{
+				{Type: generation.ObstacleTypeLow, X: 1000},
+				{Type: generation.ObstacleTypeTall, X: 1070},
+			},
+			wantErr: true,
+		},
+		{
+			name:      "unknown type",
+			obstacles: []generation.Obstacle{{Type: 99, X: 1000}},
+			wantErr:   true,
+		},
+		{
+			name:      "elevated too high",
+			obstacles: []generation.Obstacle{{Type: generation.ObstacleTypeTall, X: 1000, Y: 100}},
+			wantErr:   true,
+		},
+		{
+			// Top at 140px: a jump stays that high for only ~77px, less
+			// than the 60px obstacle plus the 40px player
+			name:      "too wide at its height",
+			obstacles: []generation.Obstacle{{Type: generation.ObstacleTypeLow, X: 1000, Y: 80}},
+			wantErr:   true,
+		},
+		{
+			// One jump stays above 100px for ~173px, short of the 220px
+			// spanning both; landing between takes ~63px to come down
+			// and ~63px plus the player's width to get back up
+			name: "too close to land between",
+			obstacles: []generation.Obstacle{
+				{Type: generation.ObstacleTypeTall, X: 1000},
+				{Type: generation.ObstacleTypeTall, X: 1140},
+			},
+			wantErr: true,
+		},
+		{
+			name: "exactly one landing apart",
+			obstacles: []generation.Obstacle{
+				{Type: generation.ObstacleTypeTall, X: 1000},
+				{Type: generation.ObstacleTypeTall, X: 1210},
+			},
+			wantErr: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := generation.CheckSolvable(tt.obstacles)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckSolvable() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestGenerateChunk_ProducesSolvableChunks verifies generated layouts pass the solvability check.
+func TestGenerateChunk_ProducesSolvableChunks(t *testing.T) {
+	for chunkID := 0; chunkID < 200; chunkID++ {
+		chunk := generation.GenerateChunk("solvable-seed", chunkID)
+		if err := generation.CheckSolvable(chunk.Obstacles); err != nil {
+			t.Fatalf("chunk %d unsolvable: %v", chunkID, err)
+		}
+	}
+}
+
+// TestJumpPhysics_MatchesGame verifies the physics the solvability rules
+// are derived from stay in step with the game simulation.
+func TestJumpPhysics_MatchesGame(t *testing.T) {
+	tests := []struct {
+		name string
+		got  float64
+		want float64
+	}{
+		{name: "jump speed", got: generation.JumpSpeed, want: -game.JumpVelocity},
+		{name: "gravity", got: generation.Gravity, want: game.Gravity},
+		{name: "run speed", got: generation.RunSpeed, want: game.PlayerSpeed},
+		{name: "player width", got: generation.PlayerWidth, want: game.PlayerWidth},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.got != tt.want {
+				t.Errorf("generation has %.1f, game has %.1f", tt.got, tt.want)
+			}
+		})
+	}
+}
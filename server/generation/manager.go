@@ -205,3 +205,62 @@ func (cm *ChunkManager) GetAllChunks() []*Chunk {
 func (cm *ChunkManager) GetOrGenerateChunkInterface(chunkID int) interface{} {
 	return cm.GetOrGenerateChunk(chunkID)
 }
+
+// ObstaclesInRange returns the obstacles of already-generated chunks whose
+// X position lies within [startX, endX]. Chunks are not generated by this call,
+// so obstacles in chunks that were never generated (or were cleaned up) are omitted.
+// This method is thread-safe.
+//
+// Parameters:
+//   - startX: The starting X position (in world coordinates)
+//   - endX: The ending X position (in world coordinates)
+//
+// Returns:
+//   - []Obstacle: Obstacles in range (empty if none)
+//
+// Example:
+//
+//	obstacles := manager.ObstaclesInRange(player.X-60, player.X+40)
+func (cm *ChunkManager) ObstaclesInRange(startX, endX float64) []Obstacle {
+	if startX > endX {
+		return []Obstacle{}
+	}
+
+	startChunkID := int(startX / ChunkSize)
+	endChunkID := int(endX / ChunkSize)
+
+	cm.mu.RLock()
+	defer cm.mu.RUnlock()
+
+	obstacles := make([]Obstacle, 0)
+	for chunkID := startChunkID; chunkID <= endChunkID; chunkID++ {
+		chunk, exists := cm.chunks[chunkID]
+		if !exists {
+			continue
+		}
+		for _, obstacle := range chunk.Obstacles {
+			if obstacle.X >= startX && obstacle.X <= endX {
+				obstacles = append(obstacles, obstacle)
+			}
+		}
+	}
+
+	return obstacles
+}
+
+// Seed returns the master seed this manager derives all chunks from.
+//
+// Returns:
+//   - string: The master seed passed to NewChunkManager
+func (cm *ChunkManager) Seed() string {
+	return cm.masterSeed
+}
+
+// GeneratorVersion returns the version of the algorithm used by GenerateChunk.
+// Clients must use the same seed and version to regenerate identical chunks.
+//
+// Returns:
+//   - int: The GeneratorVersion constant
+func (cm *ChunkManager) GeneratorVersion() int {
+	return GeneratorVersion
+}
@@ -195,3 +195,41 @@ func TestChunkManager_DeterministicAcrossInstances(t *testing.T) {
 		}
 	}
 }
+
+// TestObstaclesInRange_ReturnsOnlyGeneratedObstaclesInRange verifies range
+// filtering and that ungenerated chunks are not created.
+func TestObstaclesInRange_ReturnsOnlyGeneratedObstaclesInRange(t *testing.T) {
+	// Arrange
+	manager := generation.NewChunkManager("test-seed")
+	chunk := manager.GetOrGenerateChunk(0)
+	target := chunk.Obstacles[0]
+
+	// Act
+	obstacles := manager.ObstaclesInRange(target.X-1, target.X+1)
+	ungenerated := manager.ObstaclesInRange(generation.ChunkSize*5, generation.ChunkSize*6)
+
+	// Assert
+	if len(obstacles) != 1 || obstacles[0] != target {
+		t.Errorf("ObstaclesInRange() = %v, want [%v]", obstacles, target)
+	}
+	if len(ungenerated) != 0 {
+		t.Errorf("ObstaclesInRange() for ungenerated chunk = %d obstacles, want 0", len(ungenerated))
+	}
+	if len(manager.GetAllChunks()) != 1 {
+		t.Errorf("ObstaclesInRange() generated chunks: cached = %d, want 1", len(manager.GetAllChunks()))
+	}
+}
+
+// TestChunkManager_ReportsSeedAndVersion verifies the seed and generator version accessors.
+func TestChunkManager_ReportsSeedAndVersion(t *testing.T) {
+	// Arrange
+	manager := generation.NewChunkManager("seed-abc")
+
+	// Assert
+	if manager.Seed() != "seed-abc" {
+		t.Errorf("Seed() = %q, want %q", manager.Seed(), "seed-abc")
+	}
+	if manager.GeneratorVersion() != generation.GeneratorVersion {
+		t.Errorf("GeneratorVersion() = %d, want %d", manager.GeneratorVersion(), generation.GeneratorVersion)
+	}
+}
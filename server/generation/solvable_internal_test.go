@@ -0,0 +1,84 @@
+package generation
+
+import "testing"
+
+// TestGenerateChunk_DropsUnclearableObstacles verifies GenerateChunk drops
+// obstacles too tall to clear, by generating with geometry that would
+// otherwise produce impossible layouts.
+func TestGenerateChunk_DropsUnclearableObstacles(t *testing.T) {
+	// Arrange: tall obstacles can't be cleared
+	original := geometries
+	t.Cleanup(func() { geometries = original })
+	geometries = map[int]ObstacleGeometry{
+		ObstacleTypeTall:  {Boxes: []Box{{Width: 40, Height: MaxClearableHeight + 50}}, Jumpable: true},
+		ObstacleTypeLow:   original[ObstacleTypeLow],
+		ObstacleTypeSpike: original[ObstacleTypeSpike],
+	}
+
+	for chunkID := 0; chunkID < 100; chunkID++ {
+		// Act
+		chunk := GenerateChunk("enforce-seed", chunkID)
+
+		// Assert
+		if err := CheckSolvable(chunk.Obstacles); err != nil {
+			t.Fatalf("chunk %d unsolvable: %v", chunkID, err)
+		}
+		for _, obstacle := range chunk.Obstacles {
+			if obstacle.Type == ObstacleTypeTall {
+				t.Fatalf("chunk %d kept an unclearable obstacle at X=%.1f", chunkID, obstacle.X)
+			}
+		}
+	}
+}
+
+// TestMakeSolvable_RespacesCloseObstacles verifies obstacles too close to
+// land between are pushed back by the landing gap, and ones pushed past the
+// limit are dropped.
+func TestMakeSolvable_RespacesCloseObstacles(t *testing.T) {
+	// Arrange: each tall obstacle is 100px after the previous one's end
+	obstacles := []Obstacle{
+		{Type: ObstacleTypeTall, X: 1000},
+		{Type: ObstacleTypeTall, X: 1140},
+		{Type: ObstacleTypeTall, X: 1280},
+		{Type: ObstacleTypeTall, X: 1420},
+	}
+	gap := landingGap(obstacles[0], obstacles[1])
+
+	// Act
+	got := makeSolvable(obstacles, 1500)
+
+	// Assert
+	if len(got) != 3 {
+		t.Fatalf("kept %d obstacles, want 3 (the last pushed past the limit)", len(got))
+	}
+	if err := CheckSolvable(got); err != nil {
+		t.Fatalf("respaced layout unsolvable: %v", err)
+	}
+	for i := 1; i < len(got); i++ {
+		if want := obstacleEnd(got[i-1]) + gap; got[i].X < want || got[i].X > want+1e-9 {
+			t.Errorf("obstacle %d at X=%.3f, want %.3f", i, got[i].X, want)
+		}
+	}
+}
+
+// TestGenerateChunk_RespacesJitteredObstacles verifies the generator's
+// position jitter does produce obstacles closer than the landing gap, so
+// makeSolvable is exercised by real layouts.
+func TestGenerateChunk_RespacesJitteredObstacles(t *testing.T) {
+	respaced := 0
+	for chunkID := 0; chunkID < 100; chunkID++ {
+		// Act
+		chunk := GenerateChunk("respace-seed", chunkID)
+
+		// Assert
+		for i := 1; i < len(chunk.Obstacles); i++ {
+			previous, obstacle := chunk.Obstacles[i-1], chunk.Obstacles[i]
+			if obstacle.X-obstacleEnd(previous) < landingGap(previous, obstacle)+1e-6 {
+				respaced++
+			}
+		}
+	}
+	if respaced == 0 {
+		t.Error("no obstacles generated at the landing gap; makeSolvable never respaced")
+	}
+}
@@ -2,4 +2,8 @@ module vibe-runner-server
 
 go 1.22.1
 
-require github.com/gorilla/websocket v1.5.3 // indirect
+require (
+	github.com/BurntSushi/toml v1.6.0
+	github.com/gorilla/websocket v1.5.3
+	gopkg.in/yaml.v3 v3.0.1
+)
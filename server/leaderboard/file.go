@@ -0,0 +1,195 @@
+package leaderboard
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// record is one line of the append-only leaderboard log.
+// A record is either a score submission or a window reset.
+type record struct {
+	// Op is "score" or "reset".
+	Op string `json:"op"`
+
+	// Name is the player's name (score records only).
+	Name string `json:"n,omitempty"`
+
+	// Score is the submitted score (score records only).
+	Score int `json:"s,omitempty"`
+
+	// Window is the window being reset (reset records only).
+	Window Window `json:"w,omitempty"`
+
+	// At is when the score was achieved or the reset happened.
+	At time.Time `json:"at"`
+}
+
+// File is a durable Leaderboard backed by an append-only JSON-lines log.
+// Every submission and reset is appended to the log; on open the log is
+// replayed into an in-memory index, so high scores survive restarts
+// without any external database.
+type File struct {
+	// index serves all queries.
+	index *Memory
+
+	// file is the open log, appended to on every change.
+	file *os.File
+
+	// mu serializes appends so log lines never interleave.
+	mu sync.Mutex
+}
+
+// File must satisfy Leaderboard.
+var _ Leaderboard = (*File)(nil)
+
+// OpenFile opens (or creates) a leaderboard log and replays it.
+// Malformed lines (e.g. a partial write before a crash) are skipped and logged.
+//
+// Parameters:
+//   - path: Path of the log file
+//
+// Returns:
+//   - *File: Leaderboard ready for use
+//   - error: Non-nil if the file cannot be opened or read
+func OpenFile(path string) (*File, error) {
+	return openFile(path, time.Now)
+}
+
+// openFile opens a leaderboard log using the given clock.
+func openFile(path string, now func() time.Time) (*File, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open leaderboard log: %w", err)
+	}
+
+	index := NewMemory()
+	index.now = now
+
+	replayed, err := replay(file, index)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to replay leaderboard log: %w", err)
+	}
+	log.Printf("Leaderboard loaded %d records from %s", replayed, path)
+
+	return &File{
+		index: index,
+		file:  file,
+	}, nil
+}
+
+// replay applies every record in the log to the index.
+//
+// Returns:
+//   - int: Number of records applied
+//   - error: Non-nil if reading the file fails
+func replay(file *os.File, index *Memory) (int, error) {
+	scanner := bufio.NewScanner(file)
+	applied := 0
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		var rec record
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			log.Printf("Skipping malformed leaderboard record on line %d: %v", line, err)
+			continue
+		}
+
+		switch rec.Op {
+		case "score":
+			index.Submit(rec.Name, rec.Score, rec.At)
+		case "reset":
+			if err := index.resetAt(rec.Window, rec.At); err != nil {
+				log.Printf("Skipping leaderboard reset on line %d: %v", line, err)
+				continue
+			}
+		default:
+			log.Printf("Skipping unknown leaderboard record %q on line %d", rec.Op, line)
+			continue
+		}
+		applied++
+	}
+
+	return applied, scanner.Err()
+}
+
+// Submit appends the score to the log, then records it in the index.
+func (f *File) Submit(name string, score int, at time.Time) error {
+	if err := f.append(record{Op: "score", Name: name, Score: score, At: at}); err != nil {
+		return err
+	}
+	return f.index.Submit(name, score, at)
+}
+
+// Top returns the best n entries in a window, best first.
+func (f *File) Top(window Window, n int) ([]Entry, error) {
+	return f.index.Top(window, n)
+}
+
+// Rank returns a player's ranked entry in a window.
+func (f *File) Rank(window Window, name string) (Entry, bool, error) {
+	return f.index.Rank(window, name)
+}
+
+// PersonalBest returns a player's best score in a window.
+func (f *File) PersonalBest(window Window, name string) (int, bool, error) {
+	return f.index.PersonalBest(window, name)
+}
+
+// ResetWindow appends a reset marker to the log, then clears the window's current period.
+func (f *File) ResetWindow(window Window) error {
+	if _, err := ParseWindow(string(window)); err != nil {
+		return err
+	}
+
+	at := f.index.now()
+	if err := f.append(record{Op: "reset", Window: window, At: at}); err != nil {
+		return err
+	}
+	return f.index.resetAt(window, at)
+}
+
+// Close syncs the log to disk and closes it.
+func (f *File) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+
+	syncErr := f.file.Sync()
+	closeErr := f.file.Close()
+	f.file = nil
+
+	if syncErr != nil {
+		return fmt.Errorf("failed to sync leaderboard log: %w", syncErr)
+	}
+	return closeErr
+}
+
+// append writes one record as a JSON line.
+func (f *File) append(rec record) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal leaderboard record: %w", err)
+	}
+	line = append(line, '\n')
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return fmt.Errorf("leaderboard log is closed")
+	}
+	if _, err := f.file.Write(line); err != nil {
+		return fmt.Errorf("failed to append leaderboard record: %w", err)
+	}
+	return nil
+}
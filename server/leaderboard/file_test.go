@@ -0,0 +1,115 @@
+package leaderboard
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestFile opens a leaderboard log in a temp dir on the test clock.
+func openTestFile(t *testing.T, path string) *File {
+	t.Helper()
+	clock := &testClock{current: testNow}
+	board, err := openFile(path, clock.now)
+	if err != nil {
+		t.Fatalf("openFile() error = %v", err)
+	}
+	return board
+}
+
+// TestFile_PersistsAcrossReopen verifies scores survive closing and reopening the log.
+func TestFile_PersistsAcrossReopen(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "leaderboard.log")
+	board := openTestFile(t, path)
+	board.Submit("alice", 500, testNow)
+	board.Submit("bob", 900, testNow)
+	board.Submit("alice", 700, testNow)
+	if err := board.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// Act
+	reopened := openTestFile(t, path)
+	defer reopened.Close()
+	top, err := reopened.Top(AllTime, 10)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+	if len(top) != 2 {
+		t.Fatalf("Top() after reopen returned %d entries, want 2", len(top))
+	}
+	if top[0].Name != "bob" || top[1].Name != "alice" || top[1].Score != 700 {
+		t.Errorf("Top() after reopen = %+v, want bob 900 then alice 700", top)
+	}
+}
+
+// TestFile_ReplaysResets verifies window resets are persisted in the log.
+func TestFile_ReplaysResets(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "leaderboard.log")
+	board := openTestFile(t, path)
+	board.Submit("alice", 500, testNow)
+	if err := board.ResetWindow(Weekly); err != nil {
+		t.Fatalf("ResetWindow() error = %v", err)
+	}
+	board.Close()
+
+	// Act
+	reopened := openTestFile(t, path)
+	defer reopened.Close()
+
+	// Assert
+	if _, ok, _ := reopened.PersonalBest(Weekly, "alice"); ok {
+		t.Error("Weekly score survived a replayed reset")
+	}
+	if _, ok, _ := reopened.PersonalBest(AllTime, "alice"); !ok {
+		t.Error("All-time score cleared by a weekly reset")
+	}
+}
+
+// TestFile_SkipsMalformedLines verifies a truncated or unknown record doesn't
+// prevent the rest of the log from loading.
+func TestFile_SkipsMalformedLines(t *testing.T) {
+	// Arrange
+	path := filepath.Join(t.TempDir(), "leaderboard.log")
+	contents := `{"op":"score","n":"alice","s":500,"at":"2026-10-14T12:00:00Z"}
+{"op":"score","n":"bo
+{"op":"bogus","at":"2026-10-14T12:00:00Z"}
+{"op":"score","n":"carol","s":300,"at":"2026-10-14T12:00:00Z"}
+`
+	if err := os.WriteFile(path, []byte(contents), 0o644); err != nil {
+		t.Fatalf("test setup: %v", err)
+	}
+
+	// Act
+	board := openTestFile(t, path)
+	defer board.Close()
+	top, _ := board.Top(AllTime, 10)
+
+	// Assert
+	if len(top) != 2 {
+		t.Errorf("Top() returned %d entries, want 2 valid records: %+v", len(top), top)
+	}
+}
+
+// TestFileSubmit_AfterClose verifies submitting to a closed log fails.
+func TestFileSubmit_AfterClose(t *testing.T) {
+	// Arrange
+	board, err := OpenFile(filepath.Join(t.TempDir(), "leaderboard.log"))
+	if err != nil {
+		t.Fatalf("OpenFile() error = %v", err)
+	}
+	board.Close()
+
+	// Act
+	err = board.Submit("alice", 500, time.Now())
+
+	// Assert
+	if err == nil {
+		t.Error("Submit() after Close() error = nil, want error")
+	}
+}
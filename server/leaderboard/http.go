@@ -0,0 +1,162 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// DefaultLimit is the number of entries returned when no limit is given.
+	DefaultLimit = 10
+
+	// MaxLimit caps the number of entries a single request can return.
+	MaxLimit = 100
+)
+
+// TopResponse is the body of GET /api/leaderboard.
+type TopResponse struct {
+	// Window is the window the entries are ranked in.
+	Window Window `json:"window"`
+
+	// Entries are the best scores, best first.
+	Entries []Entry `json:"entries"`
+}
+
+// RankResponse is the body of GET /api/leaderboard/rank.
+type RankResponse struct {
+	// Window is the window the entry is ranked in.
+	Window Window `json:"window"`
+
+	// Entry is the player's ranked best score.
+	Entry Entry `json:"entry"`
+}
+
+// errorResponse is the body of any failed API request.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// TopHandler serves GET /api/leaderboard?window=daily|weekly|alltime&limit=N.
+// The window defaults to alltime and the limit to DefaultLimit (max MaxLimit).
+//
+// Parameters:
+//   - board: The leaderboard to read from
+//
+// Returns:
+//   - http.HandlerFunc: Handler returning a TopResponse as JSON
+func TopHandler(board Leaderboard) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowGet(w, r) {
+			return
+		}
+
+		window, ok := windowParam(w, r)
+		if !ok {
+			return
+		}
+
+		limit := DefaultLimit
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed < 1 {
+				writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+				return
+			}
+			limit = min(parsed, MaxLimit)
+		}
+
+		entries, err := board.Top(window, limit)
+		if err != nil {
+			log.Printf("Leaderboard query failed: %v", err)
+			writeError(w, http.StatusInternalServerError, "leaderboard unavailable")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, TopResponse{Window: window, Entries: entries})
+	}
+}
+
+// RankHandler serves GET /api/leaderboard/rank?name=...&window=....
+// The window defaults to alltime. Players without a score in the window get 404.
+//
+// Parameters:
+//   - board: The leaderboard to read from
+//
+// Returns:
+//   - http.HandlerFunc: Handler returning a RankResponse as JSON
+func RankHandler(board Leaderboard) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !allowGet(w, r) {
+			return
+		}
+
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			writeError(w, http.StatusBadRequest, "name is required")
+			return
+		}
+
+		window, ok := windowParam(w, r)
+		if !ok {
+			return
+		}
+
+		entry, found, err := board.Rank(window, name)
+		if err != nil {
+			log.Printf("Leaderboard rank query failed: %v", err)
+			writeError(w, http.StatusInternalServerError, "leaderboard unavailable")
+			return
+		}
+		if !found {
+			writeError(w, http.StatusNotFound, "no score for player in window")
+			return
+		}
+
+		writeJSON(w, http.StatusOK, RankResponse{Window: window, Entry: entry})
+	}
+}
+
+// allowGet rejects anything but GET and HEAD with 405.
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+	w.Header().Set("Allow", "GET, HEAD")
+	writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+	return false
+}
+
+// windowParam reads the window query parameter, writing 400 if it is invalid.
+func windowParam(w http.ResponseWriter, r *http.Request) (Window, bool) {
+	raw := r.URL.Query().Get("window")
+	if raw == "" {
+		return AllTime, true
+	}
+
+	window, err := ParseWindow(raw)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "window must be daily, weekly or alltime")
+		return "", false
+	}
+	return window, true
+}
+
+// writeError writes a JSON error body with the given status.
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, errorResponse{Error: message})
+}
+
+// writeJSON writes a JSON body with the given status.
+// Responses are readable from any origin so external dashboards can use them.
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.WriteHeader(status)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write leaderboard response: %v", err)
+	}
+}
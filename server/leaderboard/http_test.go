@@ -0,0 +1,135 @@
+package leaderboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// seededMemory returns an in-memory leaderboard with a few scores.
+func seededMemory() *Memory {
+	board, _ := newTestMemory()
+	board.Submit("alice", 500, testNow)
+	board.Submit("bob", 900, testNow)
+	board.Submit("carol", 300, testNow)
+	return board
+}
+
+// TestTopHandler_ReturnsRankedEntries verifies window and limit parameters.
+func TestTopHandler_ReturnsRankedEntries(t *testing.T) {
+	// Arrange
+	handler := TopHandler(seededMemory())
+	req := httptest.NewRequest(http.MethodGet, "/api/leaderboard?window=daily&limit=2", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler(rec, req)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body TopResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body.Window != Daily {
+		t.Errorf("window = %q, want daily", body.Window)
+	}
+	if len(body.Entries) != 2 || body.Entries[0].Name != "bob" || body.Entries[1].Name != "alice" {
+		t.Errorf("entries = %+v, want bob then alice", body.Entries)
+	}
+}
+
+// TestTopHandler_Defaults verifies the alltime window and default limit.
+func TestTopHandler_Defaults(t *testing.T) {
+	handler := TopHandler(seededMemory())
+	rec := httptest.NewRecorder()
+
+	handler(rec, httptest.NewRequest(http.MethodGet, "/api/leaderboard", nil))
+
+	var body TopResponse
+	json.Unmarshal(rec.Body.Bytes(), &body)
+	if body.Window != AllTime || len(body.Entries) != 3 {
+		t.Errorf("response = %+v, want 3 alltime entries", body)
+	}
+}
+
+// TestTopHandler_RejectsBadRequests verifies invalid parameters and methods.
+func TestTopHandler_RejectsBadRequests(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		wantStatus int
+	}{
+		{name: "unknown window", method: http.MethodGet, url: "/api/leaderboard?window=monthly", wantStatus: http.StatusBadRequest},
+		{name: "non-numeric limit", method: http.MethodGet, url: "/api/leaderboard?limit=abc", wantStatus: http.StatusBadRequest},
+		{name: "zero limit", method: http.MethodGet, url: "/api/leaderboard?limit=0", wantStatus: http.StatusBadRequest},
+		{name: "post", method: http.MethodPost, url: "/api/leaderboard", wantStatus: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			TopHandler(seededMemory())(rec, httptest.NewRequest(tt.method, tt.url, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
+
+// TestRankHandler_ReturnsPlayerRank verifies a known player's rank lookup.
+func TestRankHandler_ReturnsPlayerRank(t *testing.T) {
+	// Arrange
+	handler := RankHandler(seededMemory())
+	req := httptest.NewRequest(http.MethodGet, "/api/leaderboard/rank?name=alice&window=weekly", nil)
+	rec := httptest.NewRecorder()
+
+	// Act
+	handler(rec, req)
+
+	// Assert
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var body RankResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if body.Window != Weekly || body.Entry.Rank != 2 || body.Entry.Score != 500 {
+		t.Errorf("response = %+v, want weekly rank 2 with score 500", body)
+	}
+}
+
+// TestRankHandler_Errors verifies missing names and unknown players.
+func TestRankHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+	}{
+		{name: "missing name", url: "/api/leaderboard/rank", wantStatus: http.StatusBadRequest},
+		{name: "unknown window", url: "/api/leaderboard/rank?name=alice&window=monthly", wantStatus: http.StatusBadRequest},
+		{name: "unknown player", url: "/api/leaderboard/rank?name=zed", wantStatus: http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+
+			RankHandler(seededMemory())(rec, httptest.NewRequest(http.MethodGet, tt.url, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+		})
+	}
+}
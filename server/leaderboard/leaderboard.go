@@ -0,0 +1,109 @@
+// Package leaderboard stores player high scores over daily, weekly and
+// all-time windows. It provides an in-memory store and a durable
+// append-only file store behind a single Leaderboard interface.
+package leaderboard
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Window is a time window scores are ranked within.
+type Window string
+
+const (
+	// Daily ranks scores submitted during the current UTC day.
+	Daily Window = "daily"
+
+	// Weekly ranks scores submitted during the current ISO week (Monday-Sunday, UTC).
+	Weekly Window = "weekly"
+
+	// AllTime ranks every score ever submitted.
+	AllTime Window = "alltime"
+)
+
+// Windows lists every supported window.
+var Windows = []Window{Daily, Weekly, AllTime}
+
+// ErrUnknownWindow is returned for a window name that isn't supported.
+var ErrUnknownWindow = errors.New("unknown leaderboard window")
+
+// ParseWindow converts a window name ("daily", "weekly", "alltime") to a Window.
+//
+// Parameters:
+//   - name: The window name
+//
+// Returns:
+//   - Window: The parsed window
+//   - error: ErrUnknownWindow if the name isn't supported
+func ParseWindow(name string) (Window, error) {
+	for _, window := range Windows {
+		if string(window) == name {
+			return window, nil
+		}
+	}
+	return "", fmt.Errorf("%w: %q", ErrUnknownWindow, name)
+}
+
+// Entry is a player's best score within a window.
+type Entry struct {
+	// Rank is the 1-based position in the window (ties share a rank).
+	Rank int `json:"rank"`
+
+	// Name is the player's display name.
+	Name string `json:"name"`
+
+	// Score is the player's best score (distance in pixels).
+	Score int `json:"score"`
+
+	// At is when the best score was achieved.
+	At time.Time `json:"at"`
+}
+
+// Leaderboard stores and ranks player scores.
+// Players are identified by display name; each keeps only their best
+// score per window. Implementations must be safe for concurrent use.
+type Leaderboard interface {
+	// Submit records a score achieved at the given time.
+	Submit(name string, score int, at time.Time) error
+
+	// Top returns the best n entries in a window, best first.
+	Top(window Window, n int) ([]Entry, error)
+
+	// Rank returns a player's ranked entry in a window.
+	// The bool is false if the player has no score in the window.
+	Rank(window Window, name string) (Entry, bool, error)
+
+	// PersonalBest returns a player's best score in a window.
+	// The bool is false if the player has no score in the window.
+	PersonalBest(window Window, name string) (int, bool, error)
+
+	// ResetWindow clears all scores in the window's current period.
+	ResetWindow(window Window) error
+
+	// Close flushes pending writes and releases resources.
+	Close() error
+}
+
+// periodKey identifies the period of a window that a time falls in.
+// Scores only compete with other scores in the same period.
+//
+// Parameters:
+//   - window: The window
+//   - at: The time to classify
+//
+// Returns:
+//   - string: e.g. "2026-10-16" (daily), "2026-W42" (weekly), "alltime"
+func periodKey(window Window, at time.Time) string {
+	at = at.UTC()
+	switch window {
+	case Daily:
+		return at.Format("2006-01-02")
+	case Weekly:
+		year, week := at.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	default:
+		return string(AllTime)
+	}
+}
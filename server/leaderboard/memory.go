@@ -0,0 +1,193 @@
+package leaderboard
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// board holds the best score per player for the current period of one window.
+type board struct {
+	// period is the period key these scores belong to.
+	period string
+
+	// best maps player name to their best entry (Rank unset).
+	best map[string]Entry
+}
+
+// Memory is an in-memory Leaderboard. Scores are lost on restart.
+//
+// Only the current period of each window is kept; when the day or week
+// rolls over the old period's scores are discarded.
+type Memory struct {
+	// boards holds the current period of each window.
+	boards map[Window]*board
+
+	// now returns the current time (replaceable in tests).
+	now func() time.Time
+
+	// mu protects boards.
+	mu sync.Mutex
+}
+
+// Memory must satisfy Leaderboard.
+var _ Leaderboard = (*Memory)(nil)
+
+// NewMemory creates an empty in-memory leaderboard.
+//
+// Returns:
+//   - *Memory: Empty leaderboard using the system clock
+func NewMemory() *Memory {
+	return &Memory{
+		boards: make(map[Window]*board),
+		now:    time.Now,
+	}
+}
+
+// Submit records a score. It only replaces a player's entry in a window if
+// it beats their previous best, and only counts toward windows whose current
+// period contains the submission time.
+func (m *Memory) Submit(name string, score int, at time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, window := range Windows {
+		b := m.currentLocked(window)
+		if periodKey(window, at) != b.period {
+			continue
+		}
+		if previous, exists := b.best[name]; exists && previous.Score >= score {
+			continue
+		}
+		b.best[name] = Entry{Name: name, Score: score, At: at}
+	}
+
+	return nil
+}
+
+// Top returns the best n entries in a window, best first.
+// Ties are ordered by who reached the score first.
+func (m *Memory) Top(window Window, n int) ([]Entry, error) {
+	if _, err := ParseWindow(string(window)); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ranked := rankEntries(m.currentLocked(window).best)
+	if n >= 0 && n < len(ranked) {
+		ranked = ranked[:n]
+	}
+	return ranked, nil
+}
+
+// Rank returns a player's ranked entry in a window.
+func (m *Memory) Rank(window Window, name string) (Entry, bool, error) {
+	if _, err := ParseWindow(string(window)); err != nil {
+		return Entry{}, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := m.currentLocked(window)
+	entry, exists := b.best[name]
+	if !exists {
+		return Entry{}, false, nil
+	}
+
+	// Rank is one more than the number of strictly better scores
+	entry.Rank = 1
+	for _, other := range b.best {
+		if other.Score > entry.Score {
+			entry.Rank++
+		}
+	}
+	return entry, true, nil
+}
+
+// PersonalBest returns a player's best score in a window.
+func (m *Memory) PersonalBest(window Window, name string) (int, bool, error) {
+	if _, err := ParseWindow(string(window)); err != nil {
+		return 0, false, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, exists := m.currentLocked(window).best[name]
+	return entry.Score, exists, nil
+}
+
+// ResetWindow clears all scores in the window's current period.
+func (m *Memory) ResetWindow(window Window) error {
+	return m.resetAt(window, m.now())
+}
+
+// Close is a no-op for the in-memory store.
+func (m *Memory) Close() error {
+	return nil
+}
+
+// resetAt clears a window if at falls in its current period.
+// Used by ResetWindow and when replaying a file log.
+func (m *Memory) resetAt(window Window, at time.Time) error {
+	if _, err := ParseWindow(string(window)); err != nil {
+		return err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := m.currentLocked(window)
+	if periodKey(window, at) == b.period {
+		b.best = make(map[string]Entry)
+	}
+	return nil
+}
+
+// currentLocked returns the board for the window's current period,
+// discarding the previous period's scores if it has rolled over.
+// The caller must hold m.mu for writing.
+func (m *Memory) currentLocked(window Window) *board {
+	period := periodKey(window, m.now())
+
+	b, exists := m.boards[window]
+	if !exists || b.period != period {
+		b = &board{
+			period: period,
+			best:   make(map[string]Entry),
+		}
+		m.boards[window] = b
+	}
+	return b
+}
+
+// rankEntries sorts best-score entries and assigns ranks (ties share a rank).
+func rankEntries(best map[string]Entry) []Entry {
+	entries := make([]Entry, 0, len(best))
+	for _, entry := range best {
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Score != entries[j].Score {
+			return entries[i].Score > entries[j].Score
+		}
+		if !entries[i].At.Equal(entries[j].At) {
+			return entries[i].At.Before(entries[j].At)
+		}
+		return entries[i].Name < entries[j].Name
+	})
+
+	for i := range entries {
+		if i > 0 && entries[i].Score == entries[i-1].Score {
+			entries[i].Rank = entries[i-1].Rank
+		} else {
+			entries[i].Rank = i + 1
+		}
+	}
+
+	return entries
+}
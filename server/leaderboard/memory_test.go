@@ -0,0 +1,188 @@
+package leaderboard
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// testNow is a Wednesday in ISO week 42 of 2026.
+var testNow = time.Date(2026, 10, 14, 12, 0, 0, 0, time.UTC)
+
+// testClock is a settable clock for period rollover tests.
+type testClock struct {
+	current time.Time
+}
+
+func (c *testClock) now() time.Time { return c.current }
+
+// newTestMemory creates an in-memory leaderboard on a settable clock.
+func newTestMemory() (*Memory, *testClock) {
+	clock := &testClock{current: testNow}
+	board := NewMemory()
+	board.now = clock.now
+	return board, clock
+}
+
+// TestParseWindow_KnownAndUnknown verifies window names parse and unknown names are rejected.
+func TestParseWindow_KnownAndUnknown(t *testing.T) {
+	for _, window := range Windows {
+		parsed, err := ParseWindow(string(window))
+		if err != nil || parsed != window {
+			t.Errorf("ParseWindow(%q) = %q, %v, want %q", window, parsed, err, window)
+		}
+	}
+
+	if _, err := ParseWindow("monthly"); !errors.Is(err, ErrUnknownWindow) {
+		t.Errorf("ParseWindow(\"monthly\") error = %v, want ErrUnknownWindow", err)
+	}
+}
+
+// TestMemorySubmit_KeepsPersonalBest verifies lower scores don't replace a best.
+func TestMemorySubmit_KeepsPersonalBest(t *testing.T) {
+	// Arrange
+	board, _ := newTestMemory()
+
+	// Act
+	board.Submit("alice", 500, testNow)
+	board.Submit("alice", 800, testNow)
+	board.Submit("alice", 300, testNow)
+
+	// Assert
+	best, ok, err := board.PersonalBest(AllTime, "alice")
+	if err != nil || !ok {
+		t.Fatalf("PersonalBest() ok=%v error=%v", ok, err)
+	}
+	if best != 800 {
+		t.Errorf("PersonalBest() = %d, want 800", best)
+	}
+	if _, ok, _ := board.PersonalBest(AllTime, "bob"); ok {
+		t.Error("PersonalBest() found a score for a player who never submitted")
+	}
+}
+
+// TestMemoryTop_RanksWithTies verifies ordering, limits and shared ranks.
+func TestMemoryTop_RanksWithTies(t *testing.T) {
+	// Arrange
+	board, _ := newTestMemory()
+	board.Submit("alice", 500, testNow)
+	board.Submit("bob", 900, testNow)
+	board.Submit("carol", 500, testNow.Add(time.Second))
+	board.Submit("dave", 100, testNow)
+
+	// Act
+	top, err := board.Top(Daily, 3)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Top() error = %v", err)
+	}
+	want := []Entry{
+		{Rank: 1, Name: "bob", Score: 900},
+		{Rank: 2, Name: "alice", Score: 500},
+		{Rank: 2, Name: "carol", Score: 500},
+	}
+	if len(top) != len(want) {
+		t.Fatalf("Top() returned %d entries, want %d", len(top), len(want))
+	}
+	for i := range want {
+		if top[i].Rank != want[i].Rank || top[i].Name != want[i].Name || top[i].Score != want[i].Score {
+			t.Errorf("Top()[%d] = %+v, want %+v", i, top[i], want[i])
+		}
+	}
+}
+
+// TestMemoryRank_CountsBetterScores verifies a player's rank outside the top list.
+func TestMemoryRank_CountsBetterScores(t *testing.T) {
+	// Arrange
+	board, _ := newTestMemory()
+	board.Submit("alice", 500, testNow)
+	board.Submit("bob", 900, testNow)
+	board.Submit("carol", 500, testNow)
+	board.Submit("dave", 100, testNow)
+
+	// Act
+	entry, ok, err := board.Rank(Weekly, "dave")
+
+	// Assert
+	if err != nil || !ok {
+		t.Fatalf("Rank() ok=%v error=%v", ok, err)
+	}
+	if entry.Rank != 4 || entry.Score != 100 {
+		t.Errorf("Rank() = %+v, want rank 4 with score 100", entry)
+	}
+	if _, _, err := board.Rank("monthly", "dave"); !errors.Is(err, ErrUnknownWindow) {
+		t.Errorf("Rank() with unknown window error = %v, want ErrUnknownWindow", err)
+	}
+}
+
+// TestMemory_PeriodRollover verifies daily and weekly scores expire with their period.
+func TestMemory_PeriodRollover(t *testing.T) {
+	// Arrange
+	board, clock := newTestMemory()
+	board.Submit("alice", 500, testNow)
+
+	// Act: next day, same ISO week
+	clock.current = testNow.Add(24 * time.Hour)
+
+	// Assert
+	if _, ok, _ := board.PersonalBest(Daily, "alice"); ok {
+		t.Error("Daily score survived into the next day")
+	}
+	if _, ok, _ := board.PersonalBest(Weekly, "alice"); !ok {
+		t.Error("Weekly score expired within the same week")
+	}
+
+	// Act: following week
+	clock.current = testNow.Add(7 * 24 * time.Hour)
+
+	// Assert
+	if _, ok, _ := board.PersonalBest(Weekly, "alice"); ok {
+		t.Error("Weekly score survived into the next week")
+	}
+	if _, ok, _ := board.PersonalBest(AllTime, "alice"); !ok {
+		t.Error("All-time score expired")
+	}
+}
+
+// TestMemorySubmit_OldScoreOnlyCountsAllTime verifies scores from a past
+// period don't enter the current daily board.
+func TestMemorySubmit_OldScoreOnlyCountsAllTime(t *testing.T) {
+	// Arrange
+	board, _ := newTestMemory()
+
+	// Act
+	board.Submit("alice", 500, testNow.Add(-48*time.Hour))
+
+	// Assert
+	if _, ok, _ := board.PersonalBest(Daily, "alice"); ok {
+		t.Error("Score from two days ago entered the daily board")
+	}
+	if _, ok, _ := board.PersonalBest(AllTime, "alice"); !ok {
+		t.Error("Score from two days ago missing from all-time board")
+	}
+}
+
+// TestMemoryResetWindow_ClearsOnlyThatWindow verifies a reset leaves other windows alone.
+func TestMemoryResetWindow_ClearsOnlyThatWindow(t *testing.T) {
+	// Arrange
+	board, _ := newTestMemory()
+	board.Submit("alice", 500, testNow)
+
+	// Act
+	err := board.ResetWindow(Daily)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("ResetWindow() error = %v", err)
+	}
+	if _, ok, _ := board.PersonalBest(Daily, "alice"); ok {
+		t.Error("Daily score survived a daily reset")
+	}
+	if _, ok, _ := board.PersonalBest(AllTime, "alice"); !ok {
+		t.Error("All-time score cleared by a daily reset")
+	}
+	if err := board.ResetWindow("monthly"); !errors.Is(err, ErrUnknownWindow) {
+		t.Errorf("ResetWindow(\"monthly\") error = %v, want ErrUnknownWindow", err)
+	}
+}
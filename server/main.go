@@ -1,41 +1,53 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"errors"
+	"flag"
 	"log"
+	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
-	"vibe-runner-server/game"
-	"vibe-runner-server/generation"
+	"vibe-runner-server/config"
+	"vibe-runner-server/leaderboard"
 	"vibe-runner-server/network"
+	"vibe-runner-server/room"
 
 	"github.com/gorilla/websocket"
 )
 
+// shutdownTimeout is how long a graceful shutdown may take before the
+// process exits anyway.
+const shutdownTimeout = 10 * time.Second
+
 // upgrader configures the WebSocket connection upgrade from HTTP.
-// It sets buffer sizes for read/write operations and allows connections
-// from any origin (CORS). In production, CheckOrigin should validate
-// the origin to prevent unauthorized connections.
+// It sets buffer sizes for read/write operations, negotiates the wire
+// encoding through the subprotocol (binary or JSON, JSON if the client
+// offers neither) and allows connections from any origin (CORS). In
+// production, CheckOrigin should validate the origin to prevent
+// unauthorized connections.
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
+	Subprotocols:    network.Subprotocols,
 	// Allow all origins for development. In production, implement proper origin checking.
 	CheckOrigin: func(r *http.Request) bool {
 		return true
 	},
 }
 
-// makeWebSocketHandler creates a WebSocket upgrade handler with access to game state and client hub.
-// This returns a closure that captures the game state, client hub, and chunk manager for use in HandleClient.
+// makeWebSocketHandler creates a WebSocket upgrade handler with access to the room manager.
+// This returns a closure that captures the room manager for use in HandleClient.
 //
 // Parameters:
-//   - gameState: The shared game state for player management
-//   - clientHub: The client hub for state broadcasting
-//   - chunkManager: The chunk manager for procedural generation (nil to skip)
+//   - rooms: The room manager that assigns clients to game worlds
 //
 // Returns:
 //   - http.HandlerFunc: Handler function for WebSocket upgrades
-func makeWebSocketHandler(gameState *game.GameState, clientHub *network.ClientHub, chunkManager game.ChunkManager) http.HandlerFunc {
+func makeWebSocketHandler(rooms *room.Manager) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		// Upgrade HTTP connection to WebSocket protocol
 		conn, err := upgrader.Upgrade(w, r, nil)
@@ -48,60 +60,111 @@ func makeWebSocketHandler(gameState *game.GameState, clientHub *network.ClientHu
 		log.Printf("WebSocket upgrade: client connected from %s", r.RemoteAddr)
 
 		// Delegate connection handling to network package
-		// HandleClient manages message parsing, event routing, player state, and cleanup
+		// HandleClient manages message parsing, event routing, room assignment, player state, and cleanup
 		// This call blocks until the client disconnects
-		network.HandleClient(conn, gameState, clientHub, chunkManager)
+		network.HandleClient(conn, rooms)
 	}
 }
 
 // main initializes and starts the HTTP server with WebSocket support.
-// It creates the game state, chunk manager, sets up routing for the WebSocket endpoint,
-// and starts listening on port 8080.
+// It loads the configuration (flags, VIBE_RUNNER_* environment variables and
+// an optional config file; see package config), creates the room manager,
+// sets up routing for the WebSocket endpoint, and starts listening on the
+// configured port (8080 by default).
+//
+// Rooms (each with its own game state, chunk manager, seed and ticker) are
+// created on demand when clients join and torn down when they empty.
 //
-// The server registers a single endpoint:
+// The server registers the following endpoints:
 //   - /ws: WebSocket upgrade endpoint for game client connections
+//   - /api/leaderboard: Top scores as JSON (?window=daily|weekly|alltime&limit=N)
+//   - /api/leaderboard/rank: A player's rank as JSON (?name=...&window=...)
 //
-// The function blocks indefinitely, serving incoming HTTP requests.
-// If the server fails to start, the application exits with a fatal error.
+// The function blocks until SIGINT or SIGTERM, then shuts down gracefully
+// within shutdownTimeout: the listener closes, every room's ticker stops,
+// clients get a server_shutdown event and a close frame, in-progress scores
+// are recorded and the leaderboard is flushed to disk. A second signal
+// exits immediately. If the server fails to start, the application exits
+// with a fatal error.
 func main() {
-	// Generate master seed for this game session
-	// In production, this could be a persistent seed or session-specific
-	masterSeed := fmt.Sprintf("vibe-runner-%d", time.Now().Unix())
-	log.Printf("Generated master seed: %s", masterSeed)
-
-	// Create chunk manager for procedural level generation
-	chunkManager := generation.NewChunkManager(masterSeed)
-	log.Printf("Chunk manager initialized")
-
-	// Pre-generate first few chunks (0, 1, 2) so they're ready immediately
-	for i := 0; i < 3; i++ {
-		chunkManager.GetOrGenerateChunk(i)
+	// Load settings; -h prints the available flags
+	cfg, err := config.Load(os.Args[1:], os.Getenv)
+	if errors.Is(err, flag.ErrHelp) {
+		return
 	}
-	log.Printf("Pre-generated initial chunks (0-2)")
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	log.Printf("Config: %+v", cfg)
+
+	// Cancelled on the first SIGINT/SIGTERM; stops every room's game loop
+	ctx, stopSignals := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stopSignals()
 
-	// Create game state (shared across all client connections)
-	gameState := game.NewGameState()
-	log.Printf("Game state initialized")
+	// Open the durable leaderboard (high scores survive restarts)
+	scores, err := leaderboard.OpenFile(cfg.LeaderboardPath)
+	if err != nil {
+		log.Fatalf("Failed to open leaderboard: %v", err)
+	}
+	defer scores.Close()
+	log.Printf("Leaderboard opened at %s", cfg.LeaderboardPath)
 
-	// Create client hub for broadcasting state updates
-	clientHub := network.NewClientHub()
-	log.Printf("Client hub initialized")
+	// Create room manager (rooms are created when the first client joins)
+	// All rooms submit scores to the shared leaderboard
+	rooms := room.NewManager(ctx, cfg.Room(), scores)
+	log.Printf("Room manager initialized")
 
-	// Start game ticker (20Hz physics loop with state broadcasting and chunk management)
-	game.StartGameTicker(gameState, clientHub, chunkManager)
-	log.Printf("Game ticker started")
+	// Register WebSocket handler at /ws endpoint with the room manager
+	http.HandleFunc("/ws", makeWebSocketHandler(rooms))
 
-	// Register WebSocket handler at /ws endpoint with game state, client hub, and chunk manager
-	http.HandleFunc("/ws", makeWebSocketHandler(gameState, clientHub, chunkManager))
+	// Register read-only leaderboard API for the splash screen and dashboards
+	http.HandleFunc("/api/leaderboard", leaderboard.TopHandler(scores))
+	http.HandleFunc("/api/leaderboard/rank", leaderboard.RankHandler(scores))
 
-	// Start HTTP server on port 8080
-	addr := ":8080"
+	// Start HTTP server on the configured port (8080 by default)
+	addr := cfg.Addr()
+	server := &http.Server{
+		Addr: addr,
+		// Requests see the shutdown signal through their context
+		BaseContext: func(net.Listener) context.Context { return ctx },
+	}
 	log.Printf("Server starting on %s", addr)
 	log.Printf("WebSocket endpoint available at ws://localhost%s/ws", addr)
+	log.Printf("Leaderboard API available at http://localhost%s/api/leaderboard", addr)
 
-	// Start listening and serving requests
-	// This blocks until the server encounters a fatal error
-	if err := http.ListenAndServe(addr, nil); err != nil {
+	// Serve in the background until a signal arrives or the server fails
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
 		log.Fatalf("Server failed to start: %v", err)
+	case <-ctx.Done():
+	}
+
+	// Restore default signal handling so a second Ctrl+C kills the process
+	stopSignals()
+	log.Printf("Shutting down (up to %s)...", shutdownTimeout)
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	// Stop accepting connections. WebSocket connections are hijacked, so
+	// this doesn't wait for them; the room manager says goodbye to those.
+	if err := server.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Printf("HTTP server shutdown: %v", err)
+	}
+
+	// Stop rooms, record in-progress scores and notify every client
+	if err := rooms.Shutdown(shutdownCtx); err != nil {
+		log.Printf("Room shutdown: %v", err)
+	}
+
+	// Flush the leaderboard before exiting (the deferred Close is then a no-op)
+	if err := scores.Close(); err != nil {
+		log.Printf("Failed to flush leaderboard: %v", err)
 	}
+	log.Printf("Server stopped")
 }
@@ -2,14 +2,21 @@ package network
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"sync"
 	"time"
 	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
+	"vibe-runner-server/leaderboard"
 
 	"github.com/gorilla/websocket"
 )
 
+// DefaultSendBuffer is the number of outgoing messages queued per client
+// before further messages to that client are dropped.
+const DefaultSendBuffer = 10
+
 // ClientConnection represents a connected client with write capabilities.
 // Each client has a dedicated write goroutine that reads from a buffered channel.
 // This prevents slow clients from blocking the broadcast.
@@ -17,18 +24,53 @@ type ClientConnection struct {
 	// PlayerID is the unique identifier for this client's player
 	PlayerID int
 
+	// PlayerName is the player's display name (used for leaderboard ranks)
+	PlayerName string
+
 	// Conn is the WebSocket connection
 	Conn *websocket.Conn
 
+	// Codec encodes everything sent to this client, negotiated through the
+	// WebSocket subprotocol (nil means JSONCodec)
+	Codec Codec
+
 	// SendChan is the buffered channel for outgoing messages
-	// Buffer size of 10 allows some tolerance for slow clients
+	// A buffer of DefaultSendBuffer (10) allows some tolerance for slow clients
 	SendChan chan []byte
 
 	// closed indicates if this connection has been closed
 	closed bool
 
-	// mu protects the closed flag
+	// closeFrame is sent after the queued messages when the server shuts
+	// down (nil on a normal disconnect, where the queue is abandoned)
+	closeFrame []byte
+
+	// mu protects the closed flag, closeFrame, followed, chunks, rtt and clock
 	mu sync.Mutex
+
+	// done is closed when the write goroutine exits
+	done chan struct{}
+
+	// snapshots holds the states recently sent to this client and the one
+	// it last acknowledged, for delta compression
+	snapshots snapshotHistory
+
+	// followed lists players this client sees at any distance; protected by mu
+	followed []int
+
+	// chunks is the set of chunk IDs queued for this client and not yet
+	// unloaded; protected by mu
+	chunks map[int]bool
+
+	// pingInterval is how often the write goroutine pings the client (0 for never)
+	pingInterval time.Duration
+
+	// rtt is the client's round-trip time measured from pongs; protected by mu
+	rtt rttEstimator
+
+	// clock estimates the client's clock offset from its time_sync
+	// requests; protected by mu
+	clock clockEstimator
 }
 
 // ClientHub manages all connected clients and broadcasts game state.
@@ -40,6 +82,37 @@ type ClientHub struct {
 
 	// mu protects concurrent access to the clients map
 	mu sync.RWMutex
+
+	// leaderboard is the source of the live leaderboard (nil to disable)
+	leaderboard leaderboard.Leaderboard
+
+	// lastTop is the top entries last pushed to clients
+	lastTop []leaderboard.Entry
+
+	// leaderboardMu protects leaderboard and lastTop.
+	// It is always acquired before mu.
+	leaderboardMu sync.Mutex
+
+	// shutdown is set once Shutdown has been called; protected by mu
+	shutdown bool
+
+	// sendBuffer is the capacity of each new client's SendChan; protected by mu
+	sendBuffer int
+
+	// interestRadius is how far from its own player each client sees
+	// others (0 for everyone); protected by mu
+	interestRadius float64
+
+	// pingInterval is how often each new client is pinged (0 for never); protected by mu
+	pingInterval time.Duration
+
+	// maxMissedPings is how many ping intervals a client may stay silent
+	// before it is dropped; protected by mu
+	maxMissedPings int
+
+	// resumeGrace is how long a disconnected client's player is held for a
+	// resume; protected by mu
+	resumeGrace time.Duration
 }
 
 // NewClientHub creates a new client hub for managing connections.
@@ -48,30 +121,74 @@ type ClientHub struct {
 //   - *ClientHub: New hub instance ready for use
 func NewClientHub() *ClientHub {
 	return &ClientHub{
-		clients: make(map[int]*ClientConnection),
+		clients:        make(map[int]*ClientConnection),
+		sendBuffer:     DefaultSendBuffer,
+		interestRadius: DefaultInterestRadius,
+		pingInterval:   DefaultPingInterval,
+		maxMissedPings: DefaultMaxMissedPings,
+		resumeGrace:    DefaultResumeGrace,
 	}
 }
 
+// SetSendBuffer sets how many outgoing messages are queued per client.
+// It applies to clients added afterwards.
+//
+// Parameters:
+//   - size: Queue capacity per client (at least 1)
+func (h *ClientHub) SetSendBuffer(size int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sendBuffer = size
+}
+
 // AddClient registers a new client connection and starts its write goroutine.
 // The write goroutine reads from the client's send channel and writes to
 // the WebSocket connection.
 //
 // Parameters:
 //   - playerID: Unique player identifier
+//   - playerName: Player display name
 //   - conn: WebSocket connection for this client
 //
+// Returns:
+//   - bool: False if the hub has shut down; the client was sent a close
+//     frame instead of registered
+//
 // The function starts a goroutine that handles all writes for this client.
 // The goroutine exits when the send channel is closed.
-func (h *ClientHub) AddClient(playerID int, conn *websocket.Conn) {
+func (h *ClientHub) AddClient(playerID int, playerName string, conn *websocket.Conn) bool {
+	if !h.register(playerID, playerName, conn) {
+		// Written outside the hub lock, so a slow peer can't hold up the hub
+		conn.WriteControl(websocket.CloseMessage, shutdownCloseFrame, time.Now().Add(time.Second))
+		return false
+	}
+	return true
+}
+
+// register adds a client to the hub and starts its write goroutine,
+// unless the hub has shut down.
+//
+// Returns:
+//   - bool: False if the hub has shut down and the client wasn't registered
+func (h *ClientHub) register(playerID int, playerName string, conn *websocket.Conn) bool {
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
+	if h.shutdown {
+		return false
+	}
+
 	// Create client connection with buffered send channel
 	client := &ClientConnection{
-		PlayerID: playerID,
-		Conn:     conn,
-		SendChan: make(chan []byte, 10), // Buffer 10 messages
-		closed:   false,
+		PlayerID:   playerID,
+		PlayerName: playerName,
+		Conn:       conn,
+		Codec:      CodecFor(conn.Subprotocol()),
+		SendChan:   make(chan []byte, h.sendBuffer),
+		closed:     false,
+		done:       make(chan struct{}),
+
+		pingInterval: h.pingInterval,
 	}
 
 	h.clients[playerID] = client
@@ -79,7 +196,8 @@ func (h *ClientHub) AddClient(playerID int, conn *websocket.Conn) {
 	// Start write goroutine for this client
 	go client.writeLoop()
 
-	log.Printf("Client added to hub: PlayerID=%d, Total clients: %d", playerID, len(h.clients))
+	log.Printf("Client added to hub: PlayerID=%d, Codec=%s, Total clients: %d", playerID, client.codec().Name(), len(h.clients))
+	return true
 }
 
 // RemoveClient unregisters a client connection and cleans up resources.
@@ -114,8 +232,13 @@ func (h *ClientHub) RemoveClient(playerID int) {
 // BroadcastState sends the current game state to all connected clients.
 // This is called by the game ticker at 20Hz.
 //
-// The function creates a state message with current server time and all
-// alive player positions, then sends it to all clients via their send channels.
+// The function snapshots the alive player positions with the current server
+// time and tick number, then sends each client the state via its send
+// channel. Each client only gets the players within the interest radius of
+// its own player, plus the race leader and the players it follows. The state
+// is a delta against the last state the client acknowledged, or a full
+// snapshot if it hasn't acknowledged one recently. Each client's copy also
+// acknowledges its own last applied input.
 //
 // Parameters:
 //   - gameState: The game state containing all players
@@ -126,44 +249,43 @@ func (h *ClientHub) BroadcastState(gameState *game.GameState) {
 	// Get all active players
 	players := gameState.GetAllPlayers()
 
-	// Build player state array (only alive players)
-	playerStates := make([]PlayerState, 0, len(players))
+	// Snapshot the alive players once; each client gets its own view of it
+	current := newSnapshot(gameState.Tick(), players)
+	playersByID := make(map[int]*game.Player, len(players))
 	for _, player := range players {
-		if player.IsAlive {
-			playerStates = append(playerStates, PlayerState{
-				I: player.ID,
-				X: player.X,
-				Y: player.Y,
-			})
-		}
-	}
-
-	// Create state message
-	stateMsg := Message{
-		E: "state",
-		D: StateMessage{
-			T: time.Now().UnixMilli(),
-			P: playerStates,
-		},
-	}
-
-	// Marshal to JSON once (more efficient than per-client)
-	messageBytes, err := json.Marshal(stateMsg)
-	if err != nil {
-		log.Printf("Failed to marshal state message: %v", err)
-		return
+		playersByID[player.ID] = player
 	}
+	now := time.Now().UnixMilli()
 
 	// Broadcast to all clients
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 
 	for playerID, client := range h.clients {
+		own := playersByID[playerID]
+		view := current
+		if h.interestRadius > 0 {
+			view = current.visibleTo(newInterestArea(own, h.interestRadius, client.followedPlayers(), current))
+		}
+
+		stateData := buildState(view, client.snapshots.baseline())
+		stateData.T = now
+		// Acknowledge this client's own inputs
+		if own != nil {
+			stateData.A = own.LastInputSeq
+		}
+		messageBytes, err := client.codec().Encode(Message{E: "state", D: stateData})
+		if err != nil {
+			log.Printf("Failed to marshal state message for PlayerID=%d: %v", playerID, err)
+			continue
+		}
+
 		// Non-blocking send
 		// If client's channel is full, skip this update for them
 		select {
 		case client.SendChan <- messageBytes:
-			// Message queued successfully
+			// Message queued successfully; it can become a delta baseline once acked
+			client.snapshots.record(view)
 		default:
 			// Channel full - client is too slow
 			log.Printf("Dropped state update for slow client: PlayerID=%d", playerID)
@@ -171,53 +293,207 @@ func (h *ClientHub) BroadcastState(gameState *game.GameState) {
 	}
 }
 
-// BroadcastChunk sends a level chunk to all connected clients.
-// This is called when a new chunk needs to be sent to players (typically
-// when the leading player approaches a new chunk boundary).
+// AckState records that a client received the state of a tick.
+// Later states sent to the client are deltas against it while it stays
+// within the client's last SnapshotHistorySize states.
+//
+// Parameters:
+//   - playerID: The client's player ID
+//   - tick: The tick of the received state (StateMessage.K)
+func (h *ClientHub) AckState(playerID int, tick uint64) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if client, exists := h.clients[playerID]; exists {
+		client.snapshots.ack(tick)
+	}
+}
+
+// BroadcastPhase sends a race phase transition to all connected clients.
+// This is called by the game ticker whenever the match changes phase.
+//
+// Parameters:
+//   - event: The new phase, with remaining time and results
+func (h *ClientHub) BroadcastPhase(event game.MatchEvent) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	h.broadcastLocked(buildPhaseMessage(event))
+}
+
+// SendPhase sends the current race phase to a single client.
+// This is called after a client joins so it knows where the match is.
+//
+// Parameters:
+//   - playerID: The ID of the player to notify
+//   - event: The current phase, with remaining time and results
+func (h *ClientHub) SendPhase(playerID int, event game.MatchEvent) {
+	h.sendToClient(playerID, buildPhaseMessage(event))
+}
+
+// buildPhaseMessage converts a match event into a phase protocol message.
+func buildPhaseMessage(event game.MatchEvent) Message {
+	phaseData := PhaseMessage{
+		P:  event.Phase.String(),
+		Ms: event.Remaining.Milliseconds(),
+		F:  event.FinishDistance,
+	}
+
+	for _, result := range event.Results {
+		phaseData.R = append(phaseData.R, ResultEntry{
+			I: result.PlayerID,
+			N: result.Name,
+			S: result.Score,
+			F: result.Finished,
+		})
+	}
+
+	return Message{
+		E: "phase",
+		D: phaseData,
+	}
+}
+
+// SendDeath notifies a single client that their player has died.
+// This is called by the game ticker when server-side collision detection
+// kills a player.
 //
 // Parameters:
-//   - chunkID: The ID of the chunk to broadcast
-//   - chunkData: The chunk data (interface{} to avoid circular dependency)
+//   - playerID: The ID of the player who died
+//   - score: The player's final score (distance traveled in pixels)
 //
-// The function creates a chunk message and sends it to all clients.
-// The chunkData is expected to be a *generation.Chunk but we use interface{}
-// to avoid import cycles.
-func (h *ClientHub) BroadcastChunk(chunkID int, chunkData interface{}) {
-	// Convert chunk data to network format
-	// We use reflection to extract obstacles without importing generation package
-	obstacles := convertChunkToObstacles(chunkData)
-	// Create chunk message
-	chunkMsg := Message{
-		E: "chunk",
-		D: ChunkMessage{
-			ID:  chunkID,
-			Obs: obstacles,
+// If the client is not connected or its send buffer is full, the
+// death event is dropped and logged.
+func (h *ClientHub) SendDeath(playerID int, score int) {
+	h.sendToClient(playerID, Message{
+		E: "death",
+		D: DeathMessage{
+			S: score,
 		},
+	})
+}
+
+// SendSpawn tells a single client where its player respawned.
+//
+// Parameters:
+//   - playerID: The ID of the respawned player
+//   - x: Spawn X position in pixels
+//   - y: Spawn Y position in pixels
+func (h *ClientHub) SendSpawn(playerID int, x, y float64) {
+	h.sendToClient(playerID, Message{
+		E: "spawn",
+		D: SpawnMessage{
+			X: x,
+			Y: y,
+		},
+	})
+}
+
+// RejectRespawn tells a single client why its respawn request was refused.
+//
+// Parameters:
+//   - playerID: The ID of the player who asked to respawn
+//   - reason: Why the respawn was refused
+func (h *ClientHub) RejectRespawn(playerID int, reason error) {
+	h.sendToClient(playerID, Message{
+		E: "error",
+		D: ErrorMessage{
+			C: ErrCodeRespawnRejected,
+			E: "respawn",
+			M: reason.Error(),
+		},
+	})
+}
+
+// sendToClient queues a message for a single client.
+// If the client is not connected or its send buffer is full, the
+// message is dropped and logged.
+//
+// Parameters:
+//   - playerID: The ID of the player to send to
+//   - msg: The message to send (encoded with the client's codec)
+func (h *ClientHub) sendToClient(playerID int, msg Message) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	client, exists := h.clients[playerID]
+	if !exists {
+		return
 	}
 
-	// Marshal to JSON once
-	messageBytes, err := json.Marshal(chunkMsg)
+	messageBytes, err := client.codec().Encode(msg)
 	if err != nil {
-		log.Printf("Failed to marshal chunk message: %v", err)
+		log.Printf("Failed to marshal %s message: %v", msg.E, err)
 		return
 	}
 
-	// Broadcast to all clients
-	h.mu.RLock()
-	defer h.mu.RUnlock()
+	select {
+	case client.SendChan <- messageBytes:
+		// Message queued successfully
+	default:
+		log.Printf("Dropped %s event for slow client: PlayerID=%d", msg.E, playerID)
+	}
+}
 
+// broadcastLocked queues a message for every client. The message is encoded
+// once per codec in use rather than once per client. Clients with a full
+// send buffer miss the message. The caller must hold h.mu.
+//
+// Parameters:
+//   - msg: The message to send
+func (h *ClientHub) broadcastLocked(msg Message) {
+	encoder := newMessageEncoder(msg)
 	for playerID, client := range h.clients {
+		messageBytes, err := encoder.encodeFor(client)
+		if err != nil {
+			log.Printf("Failed to marshal %s message for PlayerID=%d: %v", msg.E, playerID, err)
+			continue
+		}
+
 		// Non-blocking send
 		select {
 		case client.SendChan <- messageBytes:
 			// Message queued successfully
 		default:
-			// Channel full
-			log.Printf("Dropped chunk update for slow client: PlayerID=%d", playerID)
+			// Channel full - client is too slow
+			log.Printf("Dropped %s update for slow client: PlayerID=%d", msg.E, playerID)
 		}
 	}
+}
+
+// messageEncoder encodes one message for many clients, caching the bytes
+// per codec so a broadcast encodes at most once per encoding in use.
+type messageEncoder struct {
+	msg     Message
+	encoded map[Codec][]byte
+}
 
-	log.Printf("Broadcasted chunk %d with %d obstacles to %d clients", chunkID, len(obstacles), len(h.clients))
+// newMessageEncoder creates an encoder for a message.
+func newMessageEncoder(msg Message) *messageEncoder {
+	return &messageEncoder{msg: msg, encoded: make(map[Codec][]byte, 2)}
+}
+
+// encodeFor returns the message encoded with a client's codec.
+func (e *messageEncoder) encodeFor(client *ClientConnection) ([]byte, error) {
+	codec := client.codec()
+	if messageBytes, ok := e.encoded[codec]; ok {
+		return messageBytes, nil
+	}
+
+	messageBytes, err := codec.Encode(e.msg)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", codec.Name(), err)
+	}
+	e.encoded[codec] = messageBytes
+	return messageBytes, nil
+}
+
+// codec returns the client's codec, defaulting to JSON.
+func (c *ClientConnection) codec() Codec {
+	if c.Codec == nil {
+		return JSONCodec
+	}
+	return c.Codec
 }
 
 // convertChunkToObstacles converts a generation.Chunk to network ObstacleData format.
@@ -256,7 +532,7 @@ func convertChunkToObstacles(chunkData interface{}) []ObstacleData {
 		return []ObstacleData{}
 	}
 
-	// Convert to network format
+	// Convert to network format, attaching sizes from the geometry registry
 	obstacles := make([]ObstacleData, len(chunk.Obstacles))
 	for i, obs := range chunk.Obstacles {
 		obstacles[i] = ObstacleData{
@@ -264,6 +540,10 @@ func convertChunkToObstacles(chunkData interface{}) []ObstacleData {
 			X: obs.X,
 			Y: obs.Y,
 		}
+		if geometry, ok := generation.GeometryFor(obs.Type); ok {
+			obstacles[i].W = geometry.Width()
+			obstacles[i].H = geometry.Height()
+		}
 	}
 
 	return obstacles
@@ -273,6 +553,8 @@ func convertChunkToObstacles(chunkData interface{}) []ObstacleData {
 // This runs in a dedicated goroutine per client.
 //
 // The function reads from the SendChan and writes each message to the WebSocket.
+// Every pingInterval it also sends a WebSocket ping carrying the send time,
+// which the client's pong echoes back for RTT measurement (see RecordPong).
 // It exits when SendChan is closed (on client disconnect).
 //
 // Write errors (e.g., connection closed) are logged but don't crash the goroutine.
@@ -282,25 +564,59 @@ func (c *ClientConnection) writeLoop() {
 	// If a write takes longer than 10 seconds, consider client dead
 	writeTimeout := 10 * time.Second
 
-	for messageBytes := range c.SendChan {
-		// Check if connection is closed
-		c.mu.Lock()
-		if c.closed {
+	defer close(c.done)
+
+	// A nil channel never fires, so a zero interval disables pings
+	var pings <-chan time.Time
+	if c.pingInterval > 0 {
+		pingTicker := time.NewTicker(c.pingInterval)
+		defer pingTicker.Stop()
+		pings = pingTicker.C
+	}
+
+loop:
+	for {
+		select {
+		case messageBytes, ok := <-c.SendChan:
+			if !ok {
+				break loop
+			}
+
+			// Check if connection is closed (a shutdown still drains the queue)
+			c.mu.Lock()
+			if c.closed && c.closeFrame == nil {
+				c.mu.Unlock()
+				break loop
+			}
 			c.mu.Unlock()
-			break
-		}
-		c.mu.Unlock()
 
-		// Set write deadline
-		if err := c.Conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
-			log.Printf("Failed to set write deadline for PlayerID=%d: %v", c.PlayerID, err)
-			break
+			// Set write deadline
+			if err := c.Conn.SetWriteDeadline(time.Now().Add(writeTimeout)); err != nil {
+				log.Printf("Failed to set write deadline for PlayerID=%d: %v", c.PlayerID, err)
+				break loop
+			}
+
+			// Write message to WebSocket as a text or binary frame, per the codec
+			if err := c.Conn.WriteMessage(c.codec().FrameType(), messageBytes); err != nil {
+				log.Printf("Failed to write to PlayerID=%d: %v", c.PlayerID, err)
+				break loop
+			}
+
+		case now := <-pings:
+			if err := c.Conn.WriteControl(websocket.PingMessage, pingPayload(now), now.Add(writeTimeout)); err != nil {
+				log.Printf("Failed to ping PlayerID=%d: %v", c.PlayerID, err)
+				break loop
+			}
 		}
+	}
 
-		// Write message to WebSocket
-		if err := c.Conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
-			log.Printf("Failed to write to PlayerID=%d: %v", c.PlayerID, err)
-			break
+	// Say goodbye properly when the server is shutting down
+	c.mu.Lock()
+	closeFrame := c.closeFrame
+	c.mu.Unlock()
+	if closeFrame != nil {
+		if err := c.Conn.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(writeTimeout)); err != nil {
+			log.Printf("Failed to send close frame to PlayerID=%d: %v", c.PlayerID, err)
 		}
 	}
 
@@ -1,10 +1,12 @@
 package network
 
 import (
+	"encoding/json"
 	"sync"
 	"testing"
 	"time"
 	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
 )
 
 // TestNewClientHub_CreatesEmptyHub verifies that NewClientHub
@@ -354,3 +356,159 @@ func TestBroadcastState_MultiplePlayersJSON(t *testing.T) {
 		t.Error("BroadcastState() did not send message")
 	}
 }
+
+// TestSendDeath_QueuesToTargetClientOnly tests that SendDeath delivers the
+// death event only to the player who died, with their score.
+func TestSendDeath_QueuesToTargetClientOnly(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	victim := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	bystander := &ClientConnection{PlayerID: 2, SendChan: make(chan []byte, 10)}
+
+	hub.mu.Lock()
+	hub.clients[1] = victim
+	hub.clients[2] = bystander
+	hub.mu.Unlock()
+
+	// Act
+	hub.SendDeath(1, 4200)
+
+	// Assert
+	select {
+	case msg := <-victim.SendChan:
+		want := `{"e":"death","d":{"s":4200}}`
+		if string(msg) != want {
+			t.Errorf("SendDeath() message = %s, want %s", msg, want)
+		}
+	default:
+		t.Error("SendDeath() did not queue message to victim")
+	}
+
+	if len(bystander.SendChan) != 0 {
+		t.Error("SendDeath() queued message to another client")
+	}
+}
+
+// TestRejectRespawn_QueuesErrorToRequester tests that a refused respawn is
+// answered with a respawn_rejected error to the requesting client.
+func TestRejectRespawn_QueuesErrorToRequester(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+
+	hub.mu.Lock()
+	hub.clients[1] = client
+	hub.mu.Unlock()
+
+	// Act
+	hub.RejectRespawn(1, game.ErrRespawnUnavailable)
+
+	// Assert
+	select {
+	case msg := <-client.SendChan:
+		want := `{"e":"error","d":{"c":"respawn_rejected","e":"respawn","m":"respawn unavailable"}}`
+		if string(msg) != want {
+			t.Errorf("RejectRespawn() message = %s, want %s", msg, want)
+		}
+	default:
+		t.Error("RejectRespawn() did not queue message to client")
+	}
+}
+
+// TestConvertChunkToObstacles_IncludesGeometry tests that chunk payloads
+// carry obstacle sizes from the generation geometry registry.
+func TestConvertChunkToObstacles_IncludesGeometry(t *testing.T) {
+	// Arrange
+	chunk := &generation.Chunk{
+		ID: 0,
+		Obstacles: []generation.Obstacle{
+			{Type: generation.ObstacleTypeTall, X: 600, Y: 0},
+			{Type: generation.ObstacleTypeLow, X: 1000, Y: 0},
+		},
+	}
+
+	// Act
+	obstacles := convertChunkToObstacles(chunk)
+
+	// Assert
+	if len(obstacles) != 2 {
+		t.Fatalf("convertChunkToObstacles() returned %d obstacles, want 2", len(obstacles))
+	}
+	if obstacles[0].W != 40 || obstacles[0].H != 100 {
+		t.Errorf("tall obstacle size = %.0fx%.0f, want 40x100", obstacles[0].W, obstacles[0].H)
+	}
+	if obstacles[1].W != 60 || obstacles[1].H != 60 {
+		t.Errorf("low obstacle size = %.0fx%.0f, want 60x60", obstacles[1].W, obstacles[1].H)
+	}
+}
+
+// TestBroadcastPhase_QueuesPhaseToAllClients tests that phase transitions
+// reach every client with the results payload.
+func TestBroadcastPhase_QueuesPhaseToAllClients(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	for i := 1; i <= 2; i++ {
+		hub.mu.Lock()
+		hub.clients[i] = &ClientConnection{PlayerID: i, SendChan: make(chan []byte, 10)}
+		hub.mu.Unlock()
+	}
+	event := game.MatchEvent{
+		Phase:     game.PhaseResults,
+		Remaining: 5 * time.Second,
+		Results:   []game.MatchResult{{PlayerID: 1, Name: "VibeKing", Score: 4200}},
+	}
+
+	// Act
+	hub.BroadcastPhase(event)
+
+	// Assert
+	want := `{"e":"phase","d":{"p":"results","ms":5000,"r":[{"i":1,"n":"VibeKing","s":4200}]}}`
+	for playerID, client := range hub.clients {
+		select {
+		case msg := <-client.SendChan:
+			if string(msg) != want {
+				t.Errorf("client %d phase message = %s, want %s", playerID, msg, want)
+			}
+		default:
+			t.Errorf("BroadcastPhase() did not queue message to client %d", playerID)
+		}
+	}
+}
+
+// TestBroadcastState_AcknowledgesEachClientsInputs verifies every client's
+// state update carries the tick and that client's own last input sequence.
+func TestBroadcastState_AcknowledgesEachClientsInputs(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	gameState := game.NewGameState()
+	for i := 1; i <= 2; i++ {
+		player := game.NewPlayer(i, "Racer")
+		player.LastInputSeq = uint32(i * 10)
+		gameState.AddPlayer(player)
+		hub.mu.Lock()
+		hub.clients[i] = &ClientConnection{PlayerID: i, SendChan: make(chan []byte, 10)}
+		hub.mu.Unlock()
+	}
+	gameState.AdvanceTick()
+	gameState.AdvanceTick()
+
+	// Act
+	hub.BroadcastState(gameState)
+
+	// Assert
+	for playerID, client := range hub.clients {
+		var msg struct {
+			E string       `json:"e"`
+			D StateMessage `json:"d"`
+		}
+		if err := json.Unmarshal(<-client.SendChan, &msg); err != nil {
+			t.Fatalf("client %d state message is not valid JSON: %v", playerID, err)
+		}
+		if msg.D.K != 2 {
+			t.Errorf("client %d tick = %d, want 2", playerID, msg.D.K)
+		}
+		if want := uint32(playerID * 10); msg.D.A != want {
+			t.Errorf("client %d ack = %d, want %d", playerID, msg.D.A, want)
+		}
+	}
+}
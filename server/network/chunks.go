@@ -0,0 +1,138 @@
+package network
+
+import (
+	"log"
+	"sort"
+	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
+)
+
+// ChunksBehind is the number of chunks behind a client's own player that it
+// keeps. Chunks further behind get an unload hint.
+const ChunksBehind = 1
+
+// chunkWindow returns the chunk IDs a player at x needs: ChunksBehind
+// behind its own chunk through chunksAhead ahead of it.
+func chunkWindow(x float64, chunksAhead int) (first, last int) {
+	current := int(x / generation.ChunkSize)
+	first = current - ChunksBehind
+	if first < 0 {
+		first = 0
+	}
+	return first, current + chunksAhead
+}
+
+// SyncChunks delivers level chunks per client. Each client is sent the
+// chunks covering its own player's position plus chunksAhead that it
+// hasn't received yet, and an unload hint for chunks it has passed.
+// This is called by the game ticker every tick.
+//
+// Parameters:
+//   - gameState: The game state holding every client's player
+//   - chunks: The chunk manager to read (or generate) chunks from
+//   - chunksAhead: Chunks to deliver ahead of each player's own chunk
+func (h *ClientHub) SyncChunks(gameState *game.GameState, chunks game.ChunkManager, chunksAhead int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for playerID, client := range h.clients {
+		if player := gameState.GetPlayer(playerID); player != nil {
+			client.syncChunks(player.X, chunks, chunksAhead)
+		}
+	}
+}
+
+// SyncPlayerChunks delivers the chunks around one client's player right
+// away, so a joining client doesn't wait for the next tick.
+//
+// Parameters:
+//   - playerID: The client's player ID
+//   - x: The player's X position
+//   - chunks: The chunk manager to read (or generate) chunks from
+//   - chunksAhead: Chunks to deliver ahead of the player's own chunk
+func (h *ClientHub) SyncPlayerChunks(playerID int, x float64, chunks game.ChunkManager, chunksAhead int) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if client, exists := h.clients[playerID]; exists {
+		client.syncChunks(x, chunks, chunksAhead)
+	}
+}
+
+// syncChunks sends a client the missing chunks of its window and unload
+// hints for the chunks it has passed. A chunk only counts as delivered once
+// it is queued, so one dropped for a full buffer is retried next tick.
+// The caller must hold the hub's read lock.
+//
+// Parameters:
+//   - x: The client's player X position
+//   - chunks: The chunk manager to read (or generate) chunks from
+//   - chunksAhead: Chunks to deliver ahead of the player's own chunk
+func (c *ClientConnection) syncChunks(x float64, chunks game.ChunkManager, chunksAhead int) {
+	first, last := chunkWindow(x, chunksAhead)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.chunks == nil {
+		c.chunks = make(map[int]bool)
+	}
+
+	// Chunks behind the window are no longer needed
+	var passed []int
+	for chunkID := range c.chunks {
+		if chunkID < first {
+			passed = append(passed, chunkID)
+		}
+	}
+	if len(passed) > 0 {
+		sort.Ints(passed)
+		if c.queue(Message{E: "chunk_unload", D: ChunkUnloadMessage{IDs: passed}}) {
+			for _, chunkID := range passed {
+				delete(c.chunks, chunkID)
+			}
+		}
+	}
+
+	for chunkID := first; chunkID <= last; chunkID++ {
+		if c.chunks[chunkID] {
+			continue
+		}
+		chunk := chunks.GetOrGenerateChunkInterface(chunkID)
+		if chunk == nil {
+			return
+		}
+		msg := Message{
+			E: "chunk",
+			D: ChunkMessage{
+				ID:  chunkID,
+				Obs: convertChunkToObstacles(chunk),
+			},
+		}
+		if !c.queue(msg) {
+			return // Buffer full; retry the rest next tick
+		}
+		c.chunks[chunkID] = true
+	}
+}
+
+// queue encodes a message with the client's codec and queues it without
+// blocking.
+//
+// Returns:
+//   - bool: False if the message couldn't be encoded or the buffer is full
+func (c *ClientConnection) queue(msg Message) bool {
+	messageBytes, err := c.codec().Encode(msg)
+	if err != nil {
+		log.Printf("Failed to marshal %s message: %v", msg.E, err)
+		return false
+	}
+
+	select {
+	case c.SendChan <- messageBytes:
+		return true
+	default:
+		log.Printf("Dropped %s event for slow client: PlayerID=%d", msg.E, c.PlayerID)
+		return false
+	}
+}
@@ -0,0 +1,141 @@
+package network
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
+)
+
+// receiveChunkEvents drains a client's queue, returning the delivered chunk
+// IDs and the unloaded chunk IDs in order.
+func receiveChunkEvents(t *testing.T, client *ClientConnection) (delivered, unloaded []int) {
+	t.Helper()
+
+	for len(client.SendChan) > 0 {
+		var msg struct {
+			E string          `json:"e"`
+			D json.RawMessage `json:"d"`
+		}
+		if err := json.Unmarshal(<-client.SendChan, &msg); err != nil {
+			t.Fatalf("queued message is not JSON: %v", err)
+		}
+		switch msg.E {
+		case "chunk":
+			var chunk ChunkMessage
+			json.Unmarshal(msg.D, &chunk)
+			delivered = append(delivered, chunk.ID)
+		case "chunk_unload":
+			var unload ChunkUnloadMessage
+			json.Unmarshal(msg.D, &unload)
+			unloaded = append(unloaded, unload.IDs...)
+		default:
+			t.Fatalf("unexpected %s event", msg.E)
+		}
+	}
+	return delivered, unloaded
+}
+
+// newChunkTestHub returns a hub with one client whose player is at x.
+func newChunkTestHub(x float64, buffer int) (*ClientHub, *ClientConnection, *game.GameState, *game.Player) {
+	hub := NewClientHub()
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, buffer)}
+	hub.clients[1] = client
+	gameState := game.NewGameState()
+	player := game.NewPlayer(1, "Runner")
+	player.X = x
+	gameState.AddPlayer(player)
+	return hub, client, gameState, player
+}
+
+// TestSyncChunks_LateJoinerGetsOwnWindow verifies a player joining far from
+// the start gets the chunks around its own position, not chunks 0-2.
+func TestSyncChunks_LateJoinerGetsOwnWindow(t *testing.T) {
+	// Arrange
+	hub, client, gameState, _ := newChunkTestHub(7*generation.ChunkSize+100, 10)
+
+	// Act
+	hub.SyncChunks(gameState, generation.NewChunkManager("sync-test"), 2)
+
+	// Assert
+	delivered, unloaded := receiveChunkEvents(t, client)
+	if want := []int{6, 7, 8, 9}; !reflect.DeepEqual(delivered, want) {
+		t.Errorf("delivered chunks = %v, want %v", delivered, want)
+	}
+	if len(unloaded) != 0 {
+		t.Errorf("unloaded chunks = %v, want none", unloaded)
+	}
+}
+
+// TestSyncChunks_SendsEachChunkOnce verifies chunks already delivered aren't
+// resent on later ticks.
+func TestSyncChunks_SendsEachChunkOnce(t *testing.T) {
+	// Arrange
+	hub, client, gameState, _ := newChunkTestHub(game.SpawnX, 10)
+	chunks := generation.NewChunkManager("sync-test")
+
+	// Act
+	hub.SyncChunks(gameState, chunks, 2)
+	first, _ := receiveChunkEvents(t, client)
+	hub.SyncChunks(gameState, chunks, 2)
+	second, _ := receiveChunkEvents(t, client)
+
+	// Assert
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(first, want) {
+		t.Errorf("first sync delivered %v, want %v", first, want)
+	}
+	if len(second) != 0 {
+		t.Errorf("second sync delivered %v, want nothing", second)
+	}
+}
+
+// TestSyncChunks_UnloadsPassedChunks verifies chunks more than ChunksBehind
+// behind the player are unloaded, and resent when the player returns.
+func TestSyncChunks_UnloadsPassedChunks(t *testing.T) {
+	// Arrange
+	hub, client, gameState, player := newChunkTestHub(game.SpawnX, 10)
+	chunks := generation.NewChunkManager("sync-test")
+	hub.SyncChunks(gameState, chunks, 2)
+	receiveChunkEvents(t, client)
+
+	// Act: run into chunk 3, then restart at spawn
+	player.X = 3*generation.ChunkSize + 100
+	hub.SyncChunks(gameState, chunks, 2)
+	ahead, passed := receiveChunkEvents(t, client)
+	player.X = game.SpawnX
+	hub.SyncChunks(gameState, chunks, 2)
+	restarted, _ := receiveChunkEvents(t, client)
+
+	// Assert
+	if want := []int{0, 1}; !reflect.DeepEqual(passed, want) {
+		t.Errorf("unloaded chunks = %v, want %v", passed, want)
+	}
+	if want := []int{3, 4, 5}; !reflect.DeepEqual(ahead, want) {
+		t.Errorf("delivered chunks = %v, want %v", ahead, want)
+	}
+	if want := []int{0, 1}; !reflect.DeepEqual(restarted, want) {
+		t.Errorf("delivered after restart = %v, want unloaded chunks %v again", restarted, want)
+	}
+}
+
+// TestSyncChunks_RetriesDroppedChunks verifies a chunk dropped for a full
+// send buffer is delivered on a later tick.
+func TestSyncChunks_RetriesDroppedChunks(t *testing.T) {
+	// Arrange
+	hub, client, gameState, _ := newChunkTestHub(game.SpawnX, 1)
+	chunks := generation.NewChunkManager("sync-test")
+
+	// Act
+	var delivered []int
+	for i := 0; i < 3; i++ {
+		hub.SyncChunks(gameState, chunks, 2)
+		ids, _ := receiveChunkEvents(t, client)
+		delivered = append(delivered, ids...)
+	}
+
+	// Assert
+	if want := []int{0, 1, 2}; !reflect.DeepEqual(delivered, want) {
+		t.Errorf("delivered chunks = %v, want %v one per tick", delivered, want)
+	}
+}
@@ -0,0 +1,400 @@
+package network
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/gorilla/websocket"
+)
+
+// WebSocket subprotocols a client can offer to pick its wire encoding.
+// Clients that offer neither get JSON.
+const (
+	// SubprotocolBinary selects the compact binary encoding (BinaryCodec).
+	SubprotocolBinary = "vibe-runner.bin.v1"
+
+	// SubprotocolJSON selects the JSON encoding (JSONCodec).
+	SubprotocolJSON = "vibe-runner.json.v1"
+)
+
+// Subprotocols lists the supported subprotocols in server preference order,
+// for websocket.Upgrader.Subprotocols.
+var Subprotocols = []string{SubprotocolBinary, SubprotocolJSON}
+
+// ErrMalformedFrame is returned when a binary frame can't be decoded.
+var ErrMalformedFrame = errors.New("malformed binary frame")
+
+// Codec converts messages to and from WebSocket frames.
+// Each connection uses one codec for everything the server sends; incoming
+// frames are decoded by their frame type, so a binary client may still send
+// JSON text frames (handy when debugging from a console).
+type Codec interface {
+	// Name is the codec's subprotocol name.
+	Name() string
+
+	// FrameType is the WebSocket message type the codec writes
+	// (websocket.TextMessage or websocket.BinaryMessage).
+	FrameType() int
+
+	// Encode converts a message into one frame's payload.
+	Encode(msg Message) ([]byte, error)
+
+	// Decode converts one frame's payload into a message.
+	Decode(data []byte) (Message, error)
+}
+
+var (
+	// JSONCodec encodes every message as {"e": "event", "d": data} text.
+	JSONCodec Codec = jsonCodec{}
+
+	// BinaryCodec encodes state, chunk, jump, respawn and ack messages in a
+	// compact little-endian layout, and everything else as JSON inside a
+	// binary frame. See the network protocol docs for the layout.
+	BinaryCodec Codec = binaryCodec{}
+)
+
+// CodecFor returns the codec for a negotiated subprotocol.
+//
+// Parameters:
+//   - subprotocol: The connection's subprotocol (conn.Subprotocol())
+//
+// Returns:
+//   - Codec: BinaryCodec for SubprotocolBinary, JSONCodec otherwise
+func CodecFor(subprotocol string) Codec {
+	if subprotocol == SubprotocolBinary {
+		return BinaryCodec
+	}
+	return JSONCodec
+}
+
+// codecForFrame returns the codec that decodes an incoming frame type.
+func codecForFrame(frameType int) Codec {
+	if frameType == websocket.BinaryMessage {
+		return BinaryCodec
+	}
+	return JSONCodec
+}
+
+// jsonCodec is the human-readable JSON encoding.
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return SubprotocolJSON }
+
+func (jsonCodec) FrameType() int { return websocket.TextMessage }
+
+func (jsonCodec) Encode(msg Message) ([]byte, error) {
+	return json.Marshal(msg)
+}
+
+func (jsonCodec) Decode(data []byte) (Message, error) {
+	var msg Message
+	err := json.Unmarshal(data, &msg)
+	return msg, err
+}
+
+// Binary frame opcodes. Every binary frame starts with one opcode byte.
+const (
+	// opEnvelope is followed by a JSON-encoded message (any event).
+	opEnvelope byte = 0x00
+
+	// opState is a state message (server to client).
+	opState byte = 0x01
+
+	// opChunk is a chunk message (server to client).
+	opChunk byte = 0x02
+
+	// opJump is a jump input (client to server).
+	opJump byte = 0x10
+
+	// opRespawn is a respawn input (client to server).
+	opRespawn byte = 0x11
+
+	// opAck acknowledges a state (client to server).
+	opAck byte = 0x12
+)
+
+// Fixed sizes of the binary layouts in bytes.
+const (
+	// stateHeaderSize is t int64, k uint64, a uint32, b uint64 and the three
+	// list counts uint16.
+	stateHeaderSize = 8 + 8 + 4 + 8 + 3*2
+
+	// playerStateSize is i uint32, and x and y int32 in 1/PositionScale pixels.
+	playerStateSize = 4 + 4 + 4
+
+	// despawnSize is i uint32.
+	despawnSize = 4
+
+	// chunkHeaderSize is id uint32 and the obstacle count uint16.
+	chunkHeaderSize = 4 + 2
+
+	// obstacleSize is t uint8, x float64, and y, w, h float32.
+	obstacleSize = 1 + 8 + 4 + 4 + 4
+)
+
+// binaryCodec is the compact little-endian encoding.
+//
+// Layouts after the opcode byte (all little-endian):
+//
+//	state:   t int64 | k uint64 | a uint32 | b uint64 |
+//	         np uint16 | np × player | ns uint16 | ns × player | nr uint16 | nr × (i uint32)
+//	player:  i uint32 | x int32 | y int32 (positions in 1/PositionScale pixels)
+//	chunk:   id uint32 | n uint16 | n × (t uint8 | x float64 | y float32 | w float32 | h float32)
+//	jump:    s uint32 | t int64
+//	respawn: s uint32
+//	ack:     k uint64
+//	other:   JSON-encoded message
+//
+// State positions are already snapped to the PositionScale grid, so the
+// fixed-point encoding is lossless. Obstacle X positions keep full precision;
+// heights and sizes fit comfortably in float32.
+type binaryCodec struct{}
+
+func (binaryCodec) Name() string { return SubprotocolBinary }
+
+func (binaryCodec) FrameType() int { return websocket.BinaryMessage }
+
+func (binaryCodec) Encode(msg Message) ([]byte, error) {
+	switch data := msg.D.(type) {
+	case StateMessage:
+		if msg.E == "state" {
+			size := 1 + stateHeaderSize + (len(data.P)+len(data.S))*playerStateSize + len(data.R)*despawnSize
+			return appendState(make([]byte, 0, size), data)
+		}
+	case ChunkMessage:
+		if msg.E == "chunk" {
+			return appendChunk(make([]byte, 0, 1+chunkHeaderSize+len(data.Obs)*obstacleSize), data)
+		}
+	case JumpMessage:
+		if msg.E == "jump" {
+			frame := []byte{opJump}
+			frame = binary.LittleEndian.AppendUint32(frame, data.S)
+			return binary.LittleEndian.AppendUint64(frame, uint64(data.T)), nil
+		}
+	case RespawnMessage:
+		if msg.E == "respawn" {
+			return binary.LittleEndian.AppendUint32([]byte{opRespawn}, data.S), nil
+		}
+	case AckMessage:
+		if msg.E == "ack" {
+			return binary.LittleEndian.AppendUint64([]byte{opAck}, data.K), nil
+		}
+	}
+
+	envelope, err := json.Marshal(msg)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte{opEnvelope}, envelope...), nil
+}
+
+func (binaryCodec) Decode(data []byte) (Message, error) {
+	if len(data) == 0 {
+		return Message{}, fmt.Errorf("%w: empty frame", ErrMalformedFrame)
+	}
+
+	r := &frameReader{data: data[1:]}
+	var msg Message
+	switch data[0] {
+	case opEnvelope:
+		err := json.Unmarshal(data[1:], &msg)
+		return msg, err
+
+	case opState:
+		state := StateMessage{
+			T: int64(r.uint64()),
+			K: r.uint64(),
+			A: r.uint32(),
+			B: r.uint64(),
+		}
+		state.P = r.players()
+		if spawned := r.players(); len(spawned) > 0 {
+			state.S = spawned
+		}
+		if n := r.count(despawnSize); n > 0 {
+			state.R = make([]int, n)
+			for i := range state.R {
+				state.R[i] = int(r.uint32())
+			}
+		}
+		msg = Message{E: "state", D: state}
+
+	case opChunk:
+		chunk := ChunkMessage{ID: int(r.uint32())}
+		chunk.Obs = make([]ObstacleData, r.count(obstacleSize))
+		for i := range chunk.Obs {
+			chunk.Obs[i] = ObstacleData{
+				T: int(r.uint8()),
+				X: r.float64(),
+				Y: float64(r.float32()),
+				W: float64(r.float32()),
+				H: float64(r.float32()),
+			}
+		}
+		msg = Message{E: "chunk", D: chunk}
+
+	case opJump:
+		jump := JumpMessage{S: r.uint32()}
+		jump.T = int64(r.uint64())
+		msg = Message{E: "jump", D: jump}
+
+	case opRespawn:
+		msg = Message{E: "respawn", D: RespawnMessage{S: r.uint32()}}
+
+	case opAck:
+		msg = Message{E: "ack", D: AckMessage{K: r.uint64()}}
+
+	default:
+		return Message{}, fmt.Errorf("%w: unknown opcode 0x%02x", ErrMalformedFrame, data[0])
+	}
+
+	if r.err != nil {
+		return Message{}, r.err
+	}
+	return msg, nil
+}
+
+// appendState appends an opState frame to buf.
+func appendState(buf []byte, state StateMessage) ([]byte, error) {
+	if len(state.P) > math.MaxUint16 || len(state.S) > math.MaxUint16 || len(state.R) > math.MaxUint16 {
+		return nil, fmt.Errorf("state has %d/%d/%d players, binary limit is %d",
+			len(state.P), len(state.S), len(state.R), math.MaxUint16)
+	}
+
+	buf = append(buf, opState)
+	buf = binary.LittleEndian.AppendUint64(buf, uint64(state.T))
+	buf = binary.LittleEndian.AppendUint64(buf, state.K)
+	buf = binary.LittleEndian.AppendUint32(buf, state.A)
+	buf = binary.LittleEndian.AppendUint64(buf, state.B)
+	buf = appendPlayers(buf, state.P)
+	buf = appendPlayers(buf, state.S)
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(state.R)))
+	for _, playerID := range state.R {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(playerID))
+	}
+	return buf, nil
+}
+
+// appendPlayers appends a counted list of fixed-point player positions to buf.
+func appendPlayers(buf []byte, players []PlayerState) []byte {
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(players)))
+	for _, player := range players {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(player.I))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(toFixed(player.X)))
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(toFixed(player.Y)))
+	}
+	return buf
+}
+
+// toFixed converts a position to int32 steps of 1/PositionScale pixels.
+func toFixed(position float64) int32 {
+	return int32(math.Round(position * PositionScale))
+}
+
+// fromFixed converts int32 steps of 1/PositionScale pixels to a position.
+func fromFixed(steps int32) float64 {
+	return float64(steps) / PositionScale
+}
+
+// appendChunk appends an opChunk frame to buf.
+func appendChunk(buf []byte, chunk ChunkMessage) ([]byte, error) {
+	if len(chunk.Obs) > math.MaxUint16 {
+		return nil, fmt.Errorf("chunk %d has %d obstacles, binary limit is %d", chunk.ID, len(chunk.Obs), math.MaxUint16)
+	}
+
+	buf = append(buf, opChunk)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(chunk.ID))
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(chunk.Obs)))
+	for _, obstacle := range chunk.Obs {
+		buf = append(buf, uint8(obstacle.T))
+		buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(obstacle.X))
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(obstacle.Y)))
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(obstacle.W)))
+		buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(float32(obstacle.H)))
+	}
+	return buf, nil
+}
+
+// frameReader reads little-endian fields from a binary frame.
+// After the first short read every field reads as zero and err is set.
+type frameReader struct {
+	data []byte
+	err  error
+}
+
+// next consumes n bytes, or returns nil if fewer remain.
+func (r *frameReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.data) < n {
+		r.err = fmt.Errorf("%w: truncated", ErrMalformedFrame)
+		return nil
+	}
+	field := r.data[:n]
+	r.data = r.data[n:]
+	return field
+}
+
+func (r *frameReader) uint8() uint8 {
+	if field := r.next(1); field != nil {
+		return field[0]
+	}
+	return 0
+}
+
+func (r *frameReader) uint16() uint16 {
+	if field := r.next(2); field != nil {
+		return binary.LittleEndian.Uint16(field)
+	}
+	return 0
+}
+
+func (r *frameReader) uint32() uint32 {
+	if field := r.next(4); field != nil {
+		return binary.LittleEndian.Uint32(field)
+	}
+	return 0
+}
+
+func (r *frameReader) uint64() uint64 {
+	if field := r.next(8); field != nil {
+		return binary.LittleEndian.Uint64(field)
+	}
+	return 0
+}
+
+func (r *frameReader) float32() float32 {
+	return math.Float32frombits(r.uint32())
+}
+
+func (r *frameReader) float64() float64 {
+	return math.Float64frombits(r.uint64())
+}
+
+// players reads a counted list of fixed-point player positions.
+func (r *frameReader) players() []PlayerState {
+	players := make([]PlayerState, r.count(playerStateSize))
+	for i := range players {
+		players[i] = PlayerState{
+			I: int(r.uint32()),
+			X: fromFixed(int32(r.uint32())),
+			Y: fromFixed(int32(r.uint32())),
+		}
+	}
+	return players
+}
+
+// count reads a uint16 element count and checks the frame holds that many
+// elements of the given size, so a corrupt count can't force a huge allocation.
+func (r *frameReader) count(elementSize int) int {
+	n := int(r.uint16())
+	if r.err == nil && len(r.data) < n*elementSize {
+		r.err = fmt.Errorf("%w: %d elements don't fit in %d bytes", ErrMalformedFrame, n, len(r.data))
+		return 0
+	}
+	return n
+}
@@ -0,0 +1,370 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
+	"vibe-runner-server/leaderboard"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestBinaryCodec_RoundTrip verifies every binary layout decodes to the
+// message it was encoded from.
+func TestBinaryCodec_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name   string
+		msg    Message
+		wantOp byte
+	}{
+		{
+			name: "state",
+			msg: Message{E: "state", D: StateMessage{T: 1700000000000, K: 1234, A: 42, P: []PlayerState{
+				{I: 1, X: 1234567.2, Y: 440},
+				{I: 2, X: 100, Y: 312.5},
+			}}},
+			wantOp: opState,
+		},
+		{
+			name: "delta state",
+			msg: Message{E: "state", D: StateMessage{T: 1700000000050, K: 1235, A: 42, B: 1234,
+				P: []PlayerState{{I: 1, X: 1234582.2, Y: 440}},
+				S: []PlayerState{{I: 3, X: 1234000, Y: 440}},
+				R: []int{2},
+			}},
+			wantOp: opState,
+		},
+		{
+			name:   "empty state",
+			msg:    Message{E: "state", D: StateMessage{T: 1700000000000, K: 1, P: []PlayerState{}}},
+			wantOp: opState,
+		},
+		{
+			name: "chunk",
+			msg: Message{E: "chunk", D: ChunkMessage{ID: 10, Obs: []ObstacleData{
+				{T: 1, X: 50000.5, Y: 0, W: 40, H: 100},
+				{T: 3, X: 50400, Y: 0, W: 30, H: 30},
+			}}},
+			wantOp: opChunk,
+		},
+		{name: "jump", msg: Message{E: "jump", D: JumpMessage{T: 1700000000000, S: 43}}, wantOp: opJump},
+		{name: "respawn", msg: Message{E: "respawn", D: RespawnMessage{S: 44}}, wantOp: opRespawn},
+		{name: "ack", msg: Message{E: "ack", D: AckMessage{K: 1235}}, wantOp: opAck},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Act
+			data, err := BinaryCodec.Encode(tt.msg)
+			if err != nil {
+				t.Fatalf("Encode() error = %v", err)
+			}
+			got, err := BinaryCodec.Decode(data)
+
+			// Assert
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if data[0] != tt.wantOp {
+				t.Errorf("Encode() opcode = 0x%02x, want 0x%02x", data[0], tt.wantOp)
+			}
+			if !reflect.DeepEqual(got, tt.msg) {
+				t.Errorf("Decode(Encode()) = %+v, want %+v", got, tt.msg)
+			}
+		})
+	}
+}
+
+// TestBinaryCodec_StateIsCompact verifies the binary state layout is the
+// documented size and much smaller than the same state as JSON.
+func TestBinaryCodec_StateIsCompact(t *testing.T) {
+	// Arrange
+	state := StateMessage{T: 1700000000000, K: 123456, A: 42}
+	for i := 1; i <= 20; i++ {
+		state.P = append(state.P, PlayerState{I: i, X: quantize(15000.123456 + float64(i)), Y: 440})
+	}
+	msg := Message{E: "state", D: state}
+
+	// Act
+	binaryData, err := BinaryCodec.Encode(msg)
+	if err != nil {
+		t.Fatalf("BinaryCodec.Encode() error = %v", err)
+	}
+	jsonData, err := JSONCodec.Encode(msg)
+	if err != nil {
+		t.Fatalf("JSONCodec.Encode() error = %v", err)
+	}
+
+	// Assert
+	wantSize := 1 + stateHeaderSize + 20*playerStateSize
+	if len(binaryData) != wantSize {
+		t.Errorf("binary state size = %d, want %d", len(binaryData), wantSize)
+	}
+	if len(binaryData)*2 > len(jsonData) {
+		t.Errorf("binary state size = %d, want under half of JSON size %d", len(binaryData), len(jsonData))
+	}
+}
+
+// TestBinaryCodec_OtherEventsUseJSONEnvelope verifies events without a binary
+// layout are sent as JSON behind the envelope opcode and decode the same as
+// with the JSON codec.
+func TestBinaryCodec_OtherEventsUseJSONEnvelope(t *testing.T) {
+	// Arrange
+	msg := Message{E: "death", D: DeathMessage{S: 1234}}
+
+	// Act
+	data, err := BinaryCodec.Encode(msg)
+	if err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	got, err := BinaryCodec.Decode(data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if data[0] != opEnvelope {
+		t.Fatalf("Encode() opcode = 0x%02x, want envelope 0x%02x", data[0], opEnvelope)
+	}
+	if string(data[1:]) != `{"e":"death","d":{"s":1234}}` {
+		t.Errorf("envelope payload = %s, want JSON death event", data[1:])
+	}
+	if got.E != "death" {
+		t.Errorf("Decode() event = %q, want death", got.E)
+	}
+}
+
+// TestBinaryCodec_RejectsMalformedFrames verifies truncated, empty and
+// unknown frames fail with ErrMalformedFrame instead of panicking.
+func TestBinaryCodec_RejectsMalformedFrames(t *testing.T) {
+	jump, _ := BinaryCodec.Encode(Message{E: "jump", D: JumpMessage{T: 1, S: 1}})
+	state, _ := BinaryCodec.Encode(Message{E: "state", D: StateMessage{P: []PlayerState{{I: 1}}}})
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{name: "empty", data: []byte{}},
+		{name: "unknown opcode", data: []byte{0x7f}},
+		{name: "truncated jump", data: jump[:len(jump)-1]},
+		{name: "truncated player", data: state[:len(state)-1]},
+		{name: "oversized count", data: []byte{opChunk, 1, 0, 0, 0, 0xff, 0xff}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := BinaryCodec.Decode(tt.data)
+
+			if !errors.Is(err, ErrMalformedFrame) {
+				t.Errorf("Decode() error = %v, want ErrMalformedFrame", err)
+			}
+		})
+	}
+}
+
+// TestCodecFor verifies subprotocol negotiation falls back to JSON.
+func TestCodecFor(t *testing.T) {
+	tests := []struct {
+		subprotocol string
+		want        Codec
+	}{
+		{subprotocol: SubprotocolBinary, want: BinaryCodec},
+		{subprotocol: SubprotocolJSON, want: JSONCodec},
+		{subprotocol: "", want: JSONCodec},
+		{subprotocol: "mqtt", want: JSONCodec},
+	}
+
+	for _, tt := range tests {
+		if got := CodecFor(tt.subprotocol); got != tt.want {
+			t.Errorf("CodecFor(%q) = %s, want %s", tt.subprotocol, got.Name(), tt.want.Name())
+		}
+	}
+}
+
+// TestSyncChunks_EncodesPerClientCodec verifies chunks reach a JSON client
+// and a binary client each in their own encoding.
+func TestSyncChunks_EncodesPerClientCodec(t *testing.T) {
+	// Arrange: both players at the start, so each is sent chunk 0
+	hub := NewClientHub()
+	jsonClient := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	binaryClient := &ClientConnection{PlayerID: 2, Codec: BinaryCodec, SendChan: make(chan []byte, 10)}
+	hub.clients[1] = jsonClient
+	hub.clients[2] = binaryClient
+	gameState := game.NewGameState()
+	gameState.AddPlayer(game.NewPlayer(1, "Json"))
+	gameState.AddPlayer(game.NewPlayer(2, "Binary"))
+	chunks := generation.NewChunkManager("codec-test")
+	want := convertChunkToObstacles(chunks.GetOrGenerateChunk(0))
+
+	// Act
+	hub.SyncChunks(gameState, chunks, 0)
+
+	// Assert
+	var fromJSON struct {
+		E string       `json:"e"`
+		D ChunkMessage `json:"d"`
+	}
+	if err := json.Unmarshal(<-jsonClient.SendChan, &fromJSON); err != nil || fromJSON.E != "chunk" || fromJSON.D.ID != 0 {
+		t.Errorf("JSON client got %+v (error %v), want chunk 0", fromJSON, err)
+	}
+	fromBinary, err := BinaryCodec.Decode(<-binaryClient.SendChan)
+	if err != nil {
+		t.Fatalf("binary client frame: Decode() error = %v", err)
+	}
+	chunkData, ok := fromBinary.D.(ChunkMessage)
+	if !ok || chunkData.ID != 0 || !reflect.DeepEqual(chunkData.Obs, want) {
+		t.Errorf("binary client got %+v, want chunk 0 with %d obstacles", fromBinary, len(want))
+	}
+}
+
+// failingCodec is a Codec whose Encode always fails.
+type failingCodec struct{}
+
+func (failingCodec) Name() string                       { return "failing" }
+func (failingCodec) FrameType() int                     { return websocket.BinaryMessage }
+func (failingCodec) Encode(msg Message) ([]byte, error) { return nil, errors.New("encode failed") }
+func (failingCodec) Decode(data []byte) (Message, error) {
+	return Message{}, errors.New("decode failed")
+}
+
+// addFailingClient registers one client whose codec can't encode alongside
+// healthy JSON clients 1..healthy, returning the healthy ones.
+func addFailingClient(hub *ClientHub, healthy int) []*ClientConnection {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+
+	hub.clients[0] = &ClientConnection{PlayerID: 0, Codec: failingCodec{}, SendChan: make(chan []byte, 10)}
+	clients := make([]*ClientConnection, 0, healthy)
+	for id := 1; id <= healthy; id++ {
+		client := &ClientConnection{PlayerID: id, SendChan: make(chan []byte, 10)}
+		hub.clients[id] = client
+		clients = append(clients, client)
+	}
+	return clients
+}
+
+// TestBroadcastPhase_SkipsClientsThatFailToEncode verifies one client's
+// encode error doesn't cost the others the message.
+func TestBroadcastPhase_SkipsClientsThatFailToEncode(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	clients := addFailingClient(hub, 8)
+
+	// Act
+	hub.BroadcastPhase(game.MatchEvent{Phase: game.PhaseRunning})
+
+	// Assert
+	for _, client := range clients {
+		if len(client.SendChan) != 1 {
+			t.Errorf("client %d got %d messages, want the phase event", client.PlayerID, len(client.SendChan))
+		}
+	}
+}
+
+// TestRefreshLeaderboard_SkipsClientsThatFailToEncode verifies one client's
+// encode error doesn't cost the others the new standings.
+func TestRefreshLeaderboard_SkipsClientsThatFailToEncode(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	board := leaderboard.NewMemory()
+	board.Submit("Runner", 4200, time.Now())
+	hub.SetLeaderboard(board)
+	clients := addFailingClient(hub, 8)
+
+	// Act
+	pushed := hub.RefreshLeaderboard()
+
+	// Assert
+	if !pushed {
+		t.Error("RefreshLeaderboard() = false, want true")
+	}
+	for _, client := range clients {
+		if len(client.SendChan) != 1 {
+			t.Errorf("client %d got %d messages, want the leaderboard", client.PlayerID, len(client.SendChan))
+		}
+	}
+}
+
+// TestBroadcastState_SkipsClientsThatFailToEncode verifies one client's
+// encode error doesn't cost the others the tick's state.
+func TestBroadcastState_SkipsClientsThatFailToEncode(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	clients := addFailingClient(hub, 8)
+	gameState := game.NewGameState()
+	gameState.AddPlayer(game.NewPlayer(1, "Runner"))
+
+	// Act
+	hub.BroadcastState(gameState)
+
+	// Assert
+	for _, client := range clients {
+		if len(client.SendChan) != 1 {
+			t.Errorf("client %d got %d messages, want the state", client.PlayerID, len(client.SendChan))
+		}
+	}
+}
+
+// TestAddClient_NegotiatesBinarySubprotocol connects a real WebSocket client
+// offering the binary subprotocol and verifies state arrives in binary frames.
+func TestAddClient_NegotiatesBinarySubprotocol(t *testing.T) {
+	// Arrange: a server that registers every connection with the hub
+	hub := NewClientHub()
+	added := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{Subprotocols: Subprotocols}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.AddClient(1, "Runner", conn)
+		close(added)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				hub.RemoveClient(1)
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	dialer := websocket.Dialer{Subprotocols: []string{SubprotocolBinary}}
+	client, _, err := dialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+	<-added
+
+	gameState := game.NewGameState()
+	gameState.AddPlayer(game.NewPlayer(1, "Runner"))
+
+	// Act
+	hub.BroadcastState(gameState)
+
+	// Assert
+	if client.Subprotocol() != SubprotocolBinary {
+		t.Errorf("Subprotocol() = %q, want %q", client.Subprotocol(), SubprotocolBinary)
+	}
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	frameType, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v", err)
+	}
+	if frameType != websocket.BinaryMessage {
+		t.Errorf("frame type = %d, want binary (%d)", frameType, websocket.BinaryMessage)
+	}
+	msg, err := BinaryCodec.Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if state, ok := msg.D.(StateMessage); !ok || len(state.P) != 1 || state.P[0].I != 1 {
+		t.Errorf("decoded %+v, want state with player 1", msg)
+	}
+}
@@ -0,0 +1,140 @@
+package network
+
+import (
+	"errors"
+	"log"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Protocol versions a client can announce in its join message.
+// A join without a version is treated as version 1, the protocol before
+// versioning was added.
+const (
+	// ProtocolVersion is the protocol version this server speaks.
+	ProtocolVersion = 1
+
+	// MinProtocolVersion is the oldest protocol version still accepted.
+	MinProtocolVersion = 1
+)
+
+var (
+	// ErrUnsupportedVersion is returned when a client joins with a protocol
+	// version outside MinProtocolVersion..ProtocolVersion.
+	ErrUnsupportedVersion = errors.New("unsupported protocol version")
+
+	// ErrBadPayload is returned when an event's payload can't be parsed.
+	ErrBadPayload = errors.New("malformed event payload")
+)
+
+// ErrorCode is the machine-readable reason in an error event.
+type ErrorCode string
+
+const (
+	// ErrCodeUnsupportedVersion means the join's protocol version isn't
+	// supported. The connection is closed (1002 protocol error).
+	ErrCodeUnsupportedVersion ErrorCode = "unsupported_version"
+
+	// ErrCodeNotJoined means a game event arrived before join or resume.
+	// The event is ignored.
+	ErrCodeNotJoined ErrorCode = "not_joined"
+
+	// ErrCodeRateLimited means the client is sending too many messages.
+	// Messages are dropped until the rate falls back under the limit.
+	ErrCodeRateLimited ErrorCode = "rate_limited"
+
+	// ErrCodeBadPayload means a frame or event payload couldn't be parsed.
+	// Bad join and resume payloads close the connection (1007 invalid
+	// payload); other events are ignored.
+	ErrCodeBadPayload ErrorCode = "bad_payload"
+
+	// ErrCodeUnknownEvent means the event type isn't one the server handles.
+	// The event is ignored.
+	ErrCodeUnknownEvent ErrorCode = "unknown_event"
+
+	// ErrCodeAlreadyJoined means join, create_room or resume was sent on a
+	// connection that already has a player. The event is ignored.
+	ErrCodeAlreadyJoined ErrorCode = "already_joined"
+
+	// ErrCodeJoinFailed means no room could take the player (room full,
+	// bad room ID or invite code, server shutting down). The connection is
+	// closed (1008 policy violation, or 1001 going away when shutting down).
+	ErrCodeJoinFailed ErrorCode = "join_failed"
+
+	// ErrCodeUnknownSession means a resume token is unknown, used or
+	// expired. The connection is closed (1008 policy violation); the client
+	// should join as a new player.
+	ErrCodeUnknownSession ErrorCode = "unknown_session"
+
+	// ErrCodeRespawnRejected means a respawn (or play_again) was refused:
+	// the player is alive, respawning is disabled, or no race is running.
+	// The request is ignored.
+	ErrCodeRespawnRejected ErrorCode = "respawn_rejected"
+)
+
+// joinErrorCode picks the error code and WebSocket close code for a failed
+// join or resume.
+//
+// Parameters:
+//   - err: The error from handleJoin or handleResume
+//
+// Returns:
+//   - ErrorCode: The code for the error event
+//   - int: The WebSocket close code
+func joinErrorCode(err error) (ErrorCode, int) {
+	switch {
+	case errors.Is(err, ErrUnsupportedVersion):
+		return ErrCodeUnsupportedVersion, websocket.CloseProtocolError
+	case errors.Is(err, ErrBadPayload):
+		return ErrCodeBadPayload, websocket.CloseInvalidFramePayloadData
+	case errors.Is(err, ErrUnknownSession):
+		return ErrCodeUnknownSession, websocket.ClosePolicyViolation
+	case errors.Is(err, ErrServerShuttingDown):
+		return ErrCodeJoinFailed, websocket.CloseGoingAway
+	default:
+		return ErrCodeJoinFailed, websocket.ClosePolicyViolation
+	}
+}
+
+// rejectClient tells a client that hasn't joined why it is being
+// disconnected: an error event, then a close frame carrying the code.
+// Only used before join, while HandleClient is the connection's only writer.
+//
+// Parameters:
+//   - conn: The client's WebSocket connection
+//   - code: The error code
+//   - event: The event that caused the error
+//   - message: Human-readable detail
+//   - closeCode: The WebSocket close code
+func rejectClient(conn *websocket.Conn, code ErrorCode, event, message string, closeCode int) {
+	if err := sendMessage(conn, Message{E: "error", D: ErrorMessage{C: code, E: event, M: message}}); err != nil {
+		log.Printf("Failed to send %s error to %s: %v", code, conn.RemoteAddr(), err)
+		return
+	}
+	closeFrame := websocket.FormatCloseMessage(closeCode, string(code))
+	if err := conn.WriteControl(websocket.CloseMessage, closeFrame, time.Now().Add(time.Second)); err != nil {
+		log.Printf("Failed to send close frame to %s: %v", conn.RemoteAddr(), err)
+	}
+}
+
+// reportError tells a client an event was ignored, without disconnecting it.
+// Before join the error is written directly; afterwards it is queued for
+// the client's write goroutine.
+//
+// Parameters:
+//   - conn: The client's WebSocket connection
+//   - session: The client's session (nil before join)
+//   - code: The error code
+//   - event: The event that caused the error (empty if it couldn't be decoded)
+//   - message: Human-readable detail
+func reportError(conn *websocket.Conn, session *clientSession, code ErrorCode, event, message string) {
+	errorMsg := Message{E: "error", D: ErrorMessage{C: code, E: event, M: message}}
+	if session != nil {
+		session.hub.sendToClient(session.playerID, errorMsg)
+		return
+	}
+	if err := sendMessage(conn, errorMsg); err != nil {
+		log.Printf("Failed to send %s error to %s: %v", code, conn.RemoteAddr(), err)
+	}
+}
@@ -0,0 +1,188 @@
+package network
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"vibe-runner-server/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// readError reads messages until an error event arrives.
+func readError(t *testing.T, client *websocket.Conn) ErrorMessage {
+	t.Helper()
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, data, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage() error = %v, want error event", err)
+		}
+		var msg struct {
+			E string       `json:"e"`
+			D ErrorMessage `json:"d"`
+		}
+		if err := json.Unmarshal(data, &msg); err == nil && msg.E == "error" {
+			return msg.D
+		}
+	}
+}
+
+// readCloseCode reads until the server closes the connection and returns
+// the close frame's code.
+func readCloseCode(t *testing.T, client *websocket.Conn) int {
+	t.Helper()
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, _, err := client.ReadMessage()
+		if err == nil {
+			continue
+		}
+		var closeErr *websocket.CloseError
+		if !errors.As(err, &closeErr) {
+			t.Fatalf("ReadMessage() error = %v, want a close frame", err)
+		}
+		return closeErr.Code
+	}
+}
+
+// TestCheckProtocolVersion verifies which join versions are accepted.
+func TestCheckProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		version int
+		wantErr bool
+	}{
+		{name: "unversioned client", version: 0, wantErr: false},
+		{name: "current version", version: ProtocolVersion, wantErr: false},
+		{name: "newer than server", version: ProtocolVersion + 1, wantErr: true},
+		{name: "negative", version: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkProtocolVersion(tt.version)
+			if gotErr := errors.Is(err, ErrUnsupportedVersion); gotErr != tt.wantErr {
+				t.Errorf("checkProtocolVersion(%d) = %v, want ErrUnsupportedVersion: %v", tt.version, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestJoinErrorCode verifies failed joins map to the documented error and
+// close codes.
+func TestJoinErrorCode(t *testing.T) {
+	tests := []struct {
+		name          string
+		err           error
+		wantCode      ErrorCode
+		wantCloseCode int
+	}{
+		{name: "unsupported version", err: fmt.Errorf("%w: v9", ErrUnsupportedVersion), wantCode: ErrCodeUnsupportedVersion, wantCloseCode: websocket.CloseProtocolError},
+		{name: "bad payload", err: fmt.Errorf("%w: join", ErrBadPayload), wantCode: ErrCodeBadPayload, wantCloseCode: websocket.CloseInvalidFramePayloadData},
+		{name: "unknown session", err: ErrUnknownSession, wantCode: ErrCodeUnknownSession, wantCloseCode: websocket.ClosePolicyViolation},
+		{name: "shutting down", err: fmt.Errorf("failed to join room: %w", ErrServerShuttingDown), wantCode: ErrCodeJoinFailed, wantCloseCode: websocket.CloseGoingAway},
+		{name: "room full", err: errors.New("failed to join room: room is full"), wantCode: ErrCodeJoinFailed, wantCloseCode: websocket.ClosePolicyViolation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, closeCode := joinErrorCode(tt.err)
+			if code != tt.wantCode || closeCode != tt.wantCloseCode {
+				t.Errorf("joinErrorCode(%v) = %s, %d, want %s, %d", tt.err, code, closeCode, tt.wantCode, tt.wantCloseCode)
+			}
+		})
+	}
+}
+
+// TestHandleClient_RejectsBadJoin verifies a refused join or resume is
+// explained with an error event and a close code before disconnecting.
+func TestHandleClient_RejectsBadJoin(t *testing.T) {
+	tests := []struct {
+		name          string
+		msg           Message
+		wantCode      ErrorCode
+		wantCloseCode int
+	}{
+		{
+			name:          "unsupported version",
+			msg:           Message{E: "join", D: JoinMessage{N: "Runner", V: ProtocolVersion + 1}},
+			wantCode:      ErrCodeUnsupportedVersion,
+			wantCloseCode: websocket.CloseProtocolError,
+		},
+		{
+			name:          "malformed join",
+			msg:           Message{E: "join", D: "Runner"},
+			wantCode:      ErrCodeBadPayload,
+			wantCloseCode: websocket.CloseInvalidFramePayloadData,
+		},
+		{
+			name:          "unknown resume token",
+			msg:           Message{E: "resume", D: ResumeMessage{Tok: "not-a-token"}},
+			wantCode:      ErrCodeUnknownSession,
+			wantCloseCode: websocket.ClosePolicyViolation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange
+			rooms := newSingleRoom(game.NewWorld(nil), NewClientHub())
+
+			// Act
+			client := dialSending(t, newClientServer(t, rooms), tt.msg, true)
+			got := readError(t, client)
+			closeCode := readCloseCode(t, client)
+
+			// Assert
+			if got.C != tt.wantCode || got.E != tt.msg.E {
+				t.Errorf("error event = %+v, want code %s for %s", got, tt.wantCode, tt.msg.E)
+			}
+			if closeCode != tt.wantCloseCode {
+				t.Errorf("close code = %d, want %d", closeCode, tt.wantCloseCode)
+			}
+			if players := rooms.world.State.GetPlayerCount(); players != 0 {
+				t.Errorf("players = %d, want 0", players)
+			}
+		})
+	}
+}
+
+// TestHandleClient_ReportsIgnoredEvents verifies events the server can't
+// act on get an error but leave the connection usable.
+func TestHandleClient_ReportsIgnoredEvents(t *testing.T) {
+	// Arrange
+	rooms := newSingleRoom(game.NewWorld(nil), NewClientHub())
+	client := dialSending(t, newClientServer(t, rooms), Message{E: "jump", D: JumpMessage{S: 1}}, true)
+
+	// Act and Assert: a jump before join
+	if got := readError(t, client); got.C != ErrCodeNotJoined || got.E != "jump" {
+		t.Errorf("jump before join: error = %+v, want not_joined", got)
+	}
+
+	// Malformed JSON
+	if err := client.WriteMessage(websocket.TextMessage, []byte("{not json")); err != nil {
+		t.Fatalf("WriteMessage() error = %v", err)
+	}
+	if got := readError(t, client); got.C != ErrCodeBadPayload {
+		t.Errorf("malformed frame: error = %+v, want bad_payload", got)
+	}
+
+	// The connection can still join, after which unknown events are reported
+	if err := client.WriteJSON(Message{E: "join", D: JoinMessage{N: "Runner", V: ProtocolVersion}}); err != nil {
+		t.Fatalf("WriteJSON(join) error = %v", err)
+	}
+	if welcome := readWelcome(t, client); welcome.V != ProtocolVersion {
+		t.Errorf("welcome v = %d, want %d", welcome.V, ProtocolVersion)
+	}
+	if err := client.WriteJSON(Message{E: "dance", D: nil}); err != nil {
+		t.Fatalf("WriteJSON(dance) error = %v", err)
+	}
+	if got := readError(t, client); got.C != ErrCodeUnknownEvent || got.E != "dance" {
+		t.Errorf("unknown event: error = %+v, want unknown_event", got)
+	}
+}
@@ -0,0 +1,144 @@
+package network
+
+import (
+	"strconv"
+	"time"
+)
+
+const (
+	// DefaultPingInterval is how often each client is sent a WebSocket ping.
+	DefaultPingInterval = 5 * time.Second
+
+	// DefaultMaxMissedPings is how many ping intervals may pass without
+	// hearing from a client before its connection is closed.
+	DefaultMaxMissedPings = 3
+)
+
+// rttEstimator keeps a smoothed round-trip time and jitter from RTT samples,
+// in the style of TCP's retransmission timer (RFC 6298): the smoothed RTT
+// moves 1/8 of the way to each sample and the jitter 1/4 of the way to the
+// sample's deviation from it.
+type rttEstimator struct {
+	// rtt is the smoothed round-trip time.
+	rtt time.Duration
+
+	// jitter is the smoothed mean deviation of samples from rtt.
+	jitter time.Duration
+
+	// samples is the number of samples taken.
+	samples int
+}
+
+// add folds a round-trip time sample into the estimate.
+func (e *rttEstimator) add(sample time.Duration) {
+	if e.samples == 0 {
+		e.rtt = sample
+		e.jitter = sample / 2
+	} else {
+		deviation := e.rtt - sample
+		if deviation < 0 {
+			deviation = -deviation
+		}
+		e.jitter = (3*e.jitter + deviation) / 4
+		e.rtt = (7*e.rtt + sample) / 8
+	}
+	e.samples++
+}
+
+// SetHeartbeat sets how often clients are pinged and how many pings they
+// may miss. It applies to clients added afterwards.
+//
+// Parameters:
+//   - interval: Time between pings (0 disables pings and reaping)
+//   - maxMissed: Ping intervals without a reply before a client is dropped
+func (h *ClientHub) SetHeartbeat(interval time.Duration, maxMissed int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.pingInterval = interval
+	h.maxMissedPings = maxMissed
+}
+
+// HeartbeatTimeout returns how long a client may stay silent (no pong or
+// message) before its connection is considered dead.
+//
+// Returns:
+//   - time.Duration: The timeout, or 0 if heartbeats are disabled
+func (h *ClientHub) HeartbeatTimeout() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.pingInterval * time.Duration(h.maxMissedPings)
+}
+
+// RecordPong takes an RTT sample from a client's reply to a WebSocket ping.
+// The ping payload is the server time it was sent at (see pingPayload).
+//
+// Parameters:
+//   - playerID: The client's player ID
+//   - payload: The pong's application data
+//   - now: When the pong arrived
+func (h *ClientHub) RecordPong(playerID int, payload string, now time.Time) {
+	sentAt, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return // Not one of our pings (e.g. an unsolicited pong)
+	}
+	sample := now.Sub(time.Unix(0, sentAt))
+	if sample < 0 {
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if client, exists := h.clients[playerID]; exists {
+		client.mu.Lock()
+		client.rtt.add(sample)
+		client.mu.Unlock()
+	}
+}
+
+// RTT returns a client's smoothed round-trip time and jitter.
+//
+// Parameters:
+//   - playerID: The client's player ID
+//
+// Returns:
+//   - time.Duration: The smoothed round-trip time
+//   - time.Duration: The jitter (mean deviation of the round-trip time)
+//   - bool: False if the client isn't connected or hasn't answered a ping yet
+func (h *ClientHub) RTT(playerID int) (time.Duration, time.Duration, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	client, exists := h.clients[playerID]
+	if !exists {
+		return 0, 0, false
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	return client.rtt.rtt, client.rtt.jitter, client.rtt.samples > 0
+}
+
+// SendPong answers a client's application-level ping, echoing its
+// timestamp alongside the server time and the server's RTT estimate.
+//
+// Parameters:
+//   - playerID: The client's player ID
+//   - clientTime: The timestamp from the client's ping (ms since Unix epoch)
+func (h *ClientHub) SendPong(playerID int, clientTime int64) {
+	rtt, jitter, _ := h.RTT(playerID)
+	h.sendToClient(playerID, Message{
+		E: "pong",
+		D: PongMessage{
+			T: clientTime,
+			S: time.Now().UnixMilli(),
+			R: rtt.Milliseconds(),
+			J: jitter.Milliseconds(),
+		},
+	})
+}
+
+// pingPayload returns the application data of a WebSocket ping sent at now.
+func pingPayload(now time.Time) []byte {
+	return []byte(strconv.FormatInt(now.UnixNano(), 10))
+}
@@ -0,0 +1,203 @@
+package network
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"vibe-runner-server/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestRTTEstimator_SmoothsSamples verifies the first sample seeds the
+// estimate and later samples move it gradually.
+func TestRTTEstimator_SmoothsSamples(t *testing.T) {
+	// Arrange
+	var estimator rttEstimator
+
+	// Act
+	estimator.add(80 * time.Millisecond)
+	first, firstJitter := estimator.rtt, estimator.jitter
+	estimator.add(160 * time.Millisecond)
+
+	// Assert
+	if first != 80*time.Millisecond || firstJitter != 40*time.Millisecond {
+		t.Errorf("after one sample: rtt %s jitter %s, want 80ms and 40ms", first, firstJitter)
+	}
+	if estimator.rtt != 90*time.Millisecond || estimator.jitter != 50*time.Millisecond {
+		t.Errorf("after two samples: rtt %s jitter %s, want 90ms and 50ms", estimator.rtt, estimator.jitter)
+	}
+}
+
+// TestRecordPong_MeasuresRTT verifies a pong echoing a ping payload yields
+// an RTT sample, and foreign payloads are ignored.
+func TestRecordPong_MeasuresRTT(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	hub.clients[1] = &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	sentAt := time.Unix(1700000000, 0)
+
+	// Act
+	hub.RecordPong(1, "not a timestamp", sentAt)
+	_, _, beforePong := hub.RTT(1)
+	hub.RecordPong(1, string(pingPayload(sentAt)), sentAt.Add(60*time.Millisecond))
+
+	// Assert
+	if beforePong {
+		t.Errorf("RTT() measured before any valid pong")
+	}
+	rtt, _, ok := hub.RTT(1)
+	if !ok || rtt != 60*time.Millisecond {
+		t.Errorf("RTT() = %s, %v, want 60ms, true", rtt, ok)
+	}
+}
+
+// TestSendPong_EchoesClientTime verifies the application-level pong echoes
+// the client's timestamp.
+func TestSendPong_EchoesClientTime(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.clients[1] = client
+
+	// Act
+	hub.SendPong(1, 1700000000123)
+
+	// Assert
+	var msg struct {
+		E string      `json:"e"`
+		D PongMessage `json:"d"`
+	}
+	if err := json.Unmarshal(<-client.SendChan, &msg); err != nil {
+		t.Fatalf("queued message is not JSON: %v", err)
+	}
+	if msg.E != "pong" || msg.D.T != 1700000000123 || msg.D.S == 0 {
+		t.Errorf("queued %+v, want pong echoing t=1700000000123 with server time", msg)
+	}
+}
+
+// singleRoom is a RoomProvider with one room.
+type singleRoom struct {
+	world    *game.World
+	hub      *ClientHub
+	sessions *SessionRegistry
+}
+
+// newSingleRoom returns a RoomProvider for one room with its own sessions.
+func newSingleRoom(world *game.World, hub *ClientHub) *singleRoom {
+	return &singleRoom{world: world, hub: hub, sessions: NewSessionRegistry()}
+}
+
+func (r *singleRoom) JoinRoom(request RoomRequest) (*RoomAssignment, error) {
+	return &RoomAssignment{RoomID: "test", World: r.world, Hub: r.hub}, nil
+}
+
+func (r *singleRoom) LeaveRoom(roomID string) {}
+
+func (r *singleRoom) HeartbeatTimeout() time.Duration { return r.hub.HeartbeatTimeout() }
+
+func (r *singleRoom) Sessions() *SessionRegistry { return r.sessions }
+
+// newClientServer starts a server running HandleClient and returns its WebSocket URL.
+func newClientServer(t *testing.T, rooms RoomProvider) string {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		HandleClient(conn, rooms)
+	}))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+// dialSending connects a client to url and sends msg.
+// If answerPings is false, the client never replies to WebSocket pings.
+func dialSending(t *testing.T, url string, msg Message, answerPings bool) *websocket.Conn {
+	t.Helper()
+
+	client, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	if !answerPings {
+		client.SetPingHandler(func(string) error { return nil })
+	}
+	if err := client.WriteJSON(msg); err != nil {
+		t.Fatalf("WriteJSON(%s) error = %v", msg.E, err)
+	}
+	return client
+}
+
+// dialJoined connects a client to a HandleClient server and joins.
+func dialJoined(t *testing.T, rooms RoomProvider, answerPings bool) *websocket.Conn {
+	t.Helper()
+	return dialSending(t, newClientServer(t, rooms), Message{E: "join", D: JoinMessage{N: "Runner"}}, answerPings)
+}
+
+// TestHandleClient_HeartbeatReaping verifies a client that stops answering
+// pings is disconnected and its player removed, while one that answers stays.
+func TestHandleClient_HeartbeatReaping(t *testing.T) {
+	tests := []struct {
+		name        string
+		answerPings bool
+		wantPlayers int
+	}{
+		{name: "silent client is reaped", answerPings: false, wantPlayers: 0},
+		{name: "answering client stays", answerPings: true, wantPlayers: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Arrange: three missed 20ms pings time a client out after 60ms,
+			// and its player isn't held for a resume
+			hub := NewClientHub()
+			hub.SetHeartbeat(20*time.Millisecond, 3)
+			hub.SetResumeGrace(0)
+			rooms := newSingleRoom(game.NewWorld(nil), hub)
+
+			// Act: keep reading (which answers pings) well past the timeout
+			client := dialJoined(t, rooms, tt.answerPings)
+			client.SetReadDeadline(time.Now().Add(300 * time.Millisecond))
+			for {
+				if _, _, err := client.ReadMessage(); err != nil {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond) // Let HandleClient finish cleaning up
+
+			// Assert
+			if got := rooms.world.State.GetPlayerCount(); got != tt.wantPlayers {
+				t.Errorf("players after 300ms = %d, want %d", got, tt.wantPlayers)
+			}
+			for _, player := range rooms.world.State.GetAllPlayers() {
+				if _, _, ok := hub.RTT(player.ID); !ok {
+					t.Errorf("RTT(%d) not measured for a client answering pings", player.ID)
+				}
+			}
+		})
+	}
+}
+
+// TestHandleClient_PreJoinTimeoutFollowsConfig verifies a connection that
+// never joins is dropped after the configured heartbeat timeout.
+func TestHandleClient_PreJoinTimeoutFollowsConfig(t *testing.T) {
+	// Arrange: three missed 20ms pings time a client out after 60ms
+	hub := NewClientHub()
+	hub.SetHeartbeat(20*time.Millisecond, 3)
+	rooms := newSingleRoom(game.NewWorld(nil), hub)
+	client, _, err := websocket.DefaultDialer.Dial(newClientServer(t, rooms), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+
+	// Act & Assert: the server gives up well before the default 15s
+	expectClosed(t, client, "silent connection that never joined")
+}
@@ -0,0 +1,144 @@
+package network
+
+import (
+	"sort"
+	"vibe-runner-server/game"
+)
+
+const (
+	// DefaultInterestRadius is how far ahead of and behind its own player a
+	// client sees other players, in pixels (three 1280px screen widths).
+	DefaultInterestRadius = 3 * 1280.0
+
+	// MaxFollowed is the most players a client can follow (always see
+	// regardless of distance, e.g. friends).
+	MaxFollowed = 16
+)
+
+// SetInterestRadius sets how far from its own player each client sees
+// other players. Players outside the radius are left out of the client's
+// state, except the race leader and the players it follows.
+//
+// Parameters:
+//   - radius: Distance in pixels along X (0 sends every player to every client)
+func (h *ClientHub) SetInterestRadius(radius float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.interestRadius = radius
+}
+
+// Follow sets the players a client always receives, whatever their distance.
+// Unknown or departed IDs are harmless; only the first MaxFollowed are kept.
+//
+// Parameters:
+//   - playerID: The following client's player ID
+//   - followed: IDs of the players to follow (replaces the previous set)
+func (h *ClientHub) Follow(playerID int, followed []int) {
+	if len(followed) > MaxFollowed {
+		followed = followed[:MaxFollowed]
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	client, exists := h.clients[playerID]
+	if !exists {
+		return
+	}
+
+	client.mu.Lock()
+	client.followed = append([]int(nil), followed...)
+	client.mu.Unlock()
+}
+
+// followedPlayers returns the IDs the client follows.
+func (c *ClientConnection) followedPlayers() []int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.followed
+}
+
+// interestArea describes which players one client receives.
+type interestArea struct {
+	// center is the X position the radius is measured from (the client's own player).
+	center float64
+
+	// radius is the distance along X within which players are included.
+	radius float64
+
+	// always lists players included at any distance (own player, followed players).
+	always []int
+}
+
+// newInterestArea builds a client's interest area around its own player.
+// A client whose player isn't in the world is centred on the leader.
+//
+// Parameters:
+//   - own: The client's player (nil if not in the world)
+//   - radius: The hub's interest radius
+//   - followed: Players the client follows
+//   - s: The snapshot being sent (for the leader's position)
+//
+// Returns:
+//   - interestArea: The area to filter the snapshot with
+func newInterestArea(own *game.Player, radius float64, followed []int, s *snapshot) interestArea {
+	area := interestArea{radius: radius, always: followed}
+	if own != nil {
+		// Dead players keep their position, so they still see where they fell
+		area.center = own.X
+		area.always = append([]int{own.ID}, followed...)
+	} else if leader, ok := s.leader(); ok {
+		area.center = leader.X
+	}
+	return area
+}
+
+// visibleTo returns the part of a snapshot inside a client's interest area,
+// plus the race leader. The result is itself a snapshot, so deltas against
+// it list players entering the area as spawns and leaving it as despawns.
+//
+// Parameters:
+//   - area: The client's interest area
+//
+// Returns:
+//   - *snapshot: Snapshot of the same tick with only the visible players
+func (s *snapshot) visibleTo(area interestArea) *snapshot {
+	// Players sorted by X: the area is one contiguous range
+	from := sort.Search(len(s.byX), func(i int) bool { return s.byX[i].X >= area.center-area.radius })
+	to := sort.Search(len(s.byX), func(i int) bool { return s.byX[i].X > area.center+area.radius })
+
+	visible := &snapshot{
+		tick:    s.tick,
+		players: make([]PlayerState, 0, to-from+len(area.always)+1),
+		byID:    make(map[int]PlayerState, to-from+len(area.always)+1),
+	}
+	include := func(player PlayerState) {
+		if _, added := visible.byID[player.I]; !added {
+			visible.players = append(visible.players, player)
+			visible.byID[player.I] = player
+		}
+	}
+
+	for _, player := range s.byX[from:to] {
+		include(player)
+	}
+	if leader, ok := s.leader(); ok {
+		include(leader)
+	}
+	for _, playerID := range area.always {
+		if player, alive := s.byID[playerID]; alive {
+			include(player)
+		}
+	}
+
+	sort.Slice(visible.players, func(i, j int) bool { return visible.players[i].I < visible.players[j].I })
+	return visible
+}
+
+// leader returns the alive player furthest ahead.
+func (s *snapshot) leader() (PlayerState, bool) {
+	if len(s.byX) == 0 {
+		return PlayerState{}, false
+	}
+	return s.byX[len(s.byX)-1], true
+}
@@ -0,0 +1,164 @@
+package network
+
+import (
+	"reflect"
+	"testing"
+	"vibe-runner-server/game"
+)
+
+// playerIDs returns the IDs of a snapshot's players, in order.
+func playerIDs(s *snapshot) []int {
+	ids := make([]int, len(s.players))
+	for i, player := range s.players {
+		ids[i] = player.I
+	}
+	return ids
+}
+
+// TestVisibleTo_FiltersByDistance verifies a client sees players within the
+// radius of its own position, the leader and followed players only.
+func TestVisibleTo_FiltersByDistance(t *testing.T) {
+	// Arrange
+	current := newTestSnapshot(1,
+		PlayerState{I: 1, X: 10000, Y: 440}, // self
+		PlayerState{I: 2, X: 9000, Y: 440},  // nearby behind
+		PlayerState{I: 3, X: 11000, Y: 440}, // nearby ahead, on the edge
+		PlayerState{I: 4, X: 2000, Y: 440},  // far behind
+		PlayerState{I: 5, X: 30000, Y: 440}, // leader
+		PlayerState{I: 6, X: 500, Y: 440},   // far behind, followed
+	)
+	area := interestArea{center: 10000, radius: 1000, always: []int{1, 6}}
+
+	// Act
+	visible := current.visibleTo(area)
+
+	// Assert
+	if want := []int{1, 2, 3, 5, 6}; !reflect.DeepEqual(playerIDs(visible), want) {
+		t.Errorf("visibleTo() players = %v, want %v", playerIDs(visible), want)
+	}
+	if visible.tick != current.tick {
+		t.Errorf("visibleTo() tick = %d, want %d", visible.tick, current.tick)
+	}
+}
+
+// TestNewInterestArea_CentersOnOwnPlayer verifies the area follows the
+// client's own player, dead or alive, and falls back to the leader.
+func TestNewInterestArea_CentersOnOwnPlayer(t *testing.T) {
+	current := newTestSnapshot(1, PlayerState{I: 5, X: 30000, Y: 440})
+	fallen := game.NewPlayer(1, "Fallen")
+	fallen.X = 7000
+	fallen.Kill()
+
+	tests := []struct {
+		name       string
+		own        *game.Player
+		wantCenter float64
+		wantAlways []int
+	}{
+		{name: "dead player", own: fallen, wantCenter: 7000, wantAlways: []int{1, 9}},
+		{name: "no player", own: nil, wantCenter: 30000, wantAlways: []int{9}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			area := newInterestArea(tt.own, 1000, []int{9}, current)
+
+			if area.center != tt.wantCenter || !reflect.DeepEqual(area.always, tt.wantAlways) {
+				t.Errorf("newInterestArea() = %+v, want center %g, always %v", area, tt.wantCenter, tt.wantAlways)
+			}
+		})
+	}
+}
+
+// TestBroadcastState_InterestManagement verifies players entering and leaving
+// a client's interest area arrive as spawns and despawns in deltas.
+func TestBroadcastState_InterestManagement(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	hub.SetInterestRadius(1000)
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.clients[1] = client
+	gameState := game.NewGameState()
+	self := game.NewPlayer(1, "Self")
+	self.X = 10000
+	nearby := game.NewPlayer(2, "Nearby")
+	nearby.X = 10500
+	far := game.NewPlayer(3, "Far")
+	far.X = 5000
+	leader := game.NewPlayer(4, "Leader")
+	leader.X = 50000
+	for _, player := range []*game.Player{self, nearby, far, leader} {
+		gameState.AddPlayer(player)
+	}
+
+	gameState.AdvanceTick()
+	hub.BroadcastState(gameState)
+	full := receiveState(t, client)
+	hub.AckState(1, full.K)
+
+	// Act: the nearby player pulls away and the far one catches up
+	nearby.X = 12000
+	far.X = 9500
+	gameState.AdvanceTick()
+	hub.BroadcastState(gameState)
+	delta := receiveState(t, client)
+
+	// Assert
+	gotFull := make([]int, len(full.P))
+	for i, player := range full.P {
+		gotFull[i] = player.I
+	}
+	if want := []int{1, 2, 4}; !reflect.DeepEqual(gotFull, want) {
+		t.Errorf("full snapshot players = %v, want %v", gotFull, want)
+	}
+	if len(delta.S) != 1 || delta.S[0].I != 3 {
+		t.Errorf("delta spawns = %+v, want player 3 entering", delta.S)
+	}
+	if want := []int{2}; !reflect.DeepEqual(delta.R, want) {
+		t.Errorf("delta despawns = %v, want %v", delta.R, want)
+	}
+}
+
+// TestFollow_IncludesFarPlayers verifies followed players are sent at any
+// distance and the list is capped at MaxFollowed.
+func TestFollow_IncludesFarPlayers(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	hub.SetInterestRadius(1000)
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.clients[1] = client
+	gameState := game.NewGameState()
+	gameState.AddPlayer(game.NewPlayer(1, "Self"))
+	friend := game.NewPlayer(2, "Friend")
+	friend.X = 60000
+	gameState.AddPlayer(friend)
+	stranger := game.NewPlayer(3, "Stranger")
+	stranger.X = 40000
+	gameState.AddPlayer(stranger)
+	leader := game.NewPlayer(4, "Leader")
+	leader.X = 95000
+	gameState.AddPlayer(leader)
+
+	followed := make([]int, MaxFollowed+5)
+	followed[0] = 2
+	for i := 1; i < len(followed); i++ {
+		followed[i] = 100 + i
+	}
+
+	// Act
+	hub.Follow(1, followed)
+	hub.BroadcastState(gameState)
+	state := receiveState(t, client)
+
+	// Assert
+	got := make([]int, len(state.P))
+	for i, player := range state.P {
+		got[i] = player.I
+	}
+	if want := []int{1, 2, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("state players = %v, want self, friend and leader %v", got, want)
+	}
+	if len(client.followedPlayers()) != MaxFollowed {
+		t.Errorf("followed %d players, want cap %d", len(client.followedPlayers()), MaxFollowed)
+	}
+}
@@ -0,0 +1,151 @@
+package network
+
+import (
+	"log"
+	"vibe-runner-server/leaderboard"
+)
+
+const (
+	// LiveLeaderboardWindow is the window pushed to clients during play.
+	LiveLeaderboardWindow = leaderboard.Daily
+
+	// LiveLeaderboardSize is the number of top entries pushed to clients.
+	LiveLeaderboardSize = 10
+)
+
+// SetLeaderboard sets the leaderboard this hub pushes to its clients.
+// Call before the hub is used; a nil leaderboard disables the push.
+//
+// Parameters:
+//   - board: The leaderboard to read rankings from
+func (h *ClientHub) SetLeaderboard(board leaderboard.Leaderboard) {
+	h.leaderboardMu.Lock()
+	defer h.leaderboardMu.Unlock()
+	h.leaderboard = board
+	h.lastTop = nil
+}
+
+// RefreshLeaderboard re-reads the top entries and, if they changed since the
+// last push, sends the leaderboard to every client with their own rank.
+// This is called by the game ticker after scores are recorded and
+// periodically, so scores from other rooms and day rollovers also show up.
+//
+// Returns:
+//   - bool: True if the leaderboard was pushed to the clients
+func (h *ClientHub) RefreshLeaderboard() bool {
+	h.leaderboardMu.Lock()
+	defer h.leaderboardMu.Unlock()
+
+	if h.leaderboard == nil {
+		return false
+	}
+
+	top, err := h.leaderboard.Top(LiveLeaderboardWindow, LiveLeaderboardSize)
+	if err != nil {
+		log.Printf("Failed to read leaderboard: %v", err)
+		return false
+	}
+	if sameEntries(top, h.lastTop) {
+		return false
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for playerID, client := range h.clients {
+		messageBytes, err := client.codec().Encode(h.buildLeaderboardMessage(top, client.PlayerName))
+		if err != nil {
+			log.Printf("Failed to marshal leaderboard message for PlayerID=%d: %v", playerID, err)
+			continue
+		}
+
+		select {
+		case client.SendChan <- messageBytes:
+			// Message queued successfully
+		default:
+			log.Printf("Dropped leaderboard update for slow client: PlayerID=%d", playerID)
+		}
+	}
+
+	// Only remembered once pushed, so it isn't mistaken for already sent
+	h.lastTop = top
+	return true
+}
+
+// SendLeaderboard sends the current leaderboard to a single client.
+// This is called after a client joins, and by the game ticker when the
+// player's own score was recorded but the top entries didn't change.
+//
+// Parameters:
+//   - playerID: The ID of the player to send to
+//   - playerName: The player's display name, used to look up their own rank
+func (h *ClientHub) SendLeaderboard(playerID int, playerName string) {
+	h.leaderboardMu.Lock()
+	defer h.leaderboardMu.Unlock()
+
+	if h.leaderboard == nil {
+		return
+	}
+
+	top, err := h.leaderboard.Top(LiveLeaderboardWindow, LiveLeaderboardSize)
+	if err != nil {
+		log.Printf("Failed to read leaderboard: %v", err)
+		return
+	}
+
+	h.sendToClient(playerID, h.buildLeaderboardMessage(top, playerName))
+}
+
+// buildLeaderboardMessage creates a leaderboard message for one player.
+// The caller must hold h.leaderboardMu.
+//
+// Parameters:
+//   - top: The top entries, best first
+//   - playerName: The receiving player's name
+//
+// Returns:
+//   - Message: The leaderboard event including the player's own rank, if any
+func (h *ClientHub) buildLeaderboardMessage(top []leaderboard.Entry, playerName string) Message {
+	data := LeaderboardMessage{
+		W:   string(LiveLeaderboardWindow),
+		Top: make([]LeaderboardEntry, len(top)),
+	}
+	for i, entry := range top {
+		data.Top[i] = toLeaderboardEntry(entry)
+	}
+
+	own, found, err := h.leaderboard.Rank(LiveLeaderboardWindow, playerName)
+	if err != nil {
+		log.Printf("Failed to read leaderboard rank for %s: %v", playerName, err)
+	} else if found {
+		me := toLeaderboardEntry(own)
+		data.Me = &me
+	}
+
+	return Message{
+		E: "leaderboard",
+		D: data,
+	}
+}
+
+// toLeaderboardEntry converts a stored entry to its protocol form.
+func toLeaderboardEntry(entry leaderboard.Entry) LeaderboardEntry {
+	return LeaderboardEntry{
+		R: entry.Rank,
+		N: entry.Name,
+		S: entry.Score,
+	}
+}
+
+// sameEntries reports whether two top lists show the same standings.
+func sameEntries(a, b []leaderboard.Entry) bool {
+	if a == nil || b == nil || len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Rank != b[i].Rank || a[i].Name != b[i].Name || a[i].Score != b[i].Score {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,109 @@
+package network
+
+import (
+	"testing"
+	"time"
+	"vibe-runner-server/leaderboard"
+)
+
+// newLeaderboardHub creates a hub with two clients and an in-memory leaderboard.
+func newLeaderboardHub() (*ClientHub, *leaderboard.Memory) {
+	board := leaderboard.NewMemory()
+	hub := NewClientHub()
+	hub.SetLeaderboard(board)
+
+	hub.mu.Lock()
+	hub.clients[1] = &ClientConnection{PlayerID: 1, PlayerName: "VibeKing", SendChan: make(chan []byte, 10)}
+	hub.clients[2] = &ClientConnection{PlayerID: 2, PlayerName: "Neo", SendChan: make(chan []byte, 10)}
+	hub.mu.Unlock()
+
+	return hub, board
+}
+
+// receive returns the next queued message for a client, or "" if none.
+func receive(client *ClientConnection) string {
+	select {
+	case msg := <-client.SendChan:
+		return string(msg)
+	default:
+		return ""
+	}
+}
+
+// TestRefreshLeaderboard_IncludesOwnRank verifies each client gets the top
+// entries plus their own rank.
+func TestRefreshLeaderboard_IncludesOwnRank(t *testing.T) {
+	// Arrange
+	hub, board := newLeaderboardHub()
+	board.Submit("VibeKing", 4200, time.Now())
+
+	// Act
+	pushed := hub.RefreshLeaderboard()
+
+	// Assert
+	if !pushed {
+		t.Fatal("RefreshLeaderboard() = false, want true on first push")
+	}
+	wantKing := `{"e":"leaderboard","d":{"w":"daily","top":[{"r":1,"n":"VibeKing","s":4200}],"me":{"r":1,"n":"VibeKing","s":4200}}}`
+	if got := receive(hub.clients[1]); got != wantKing {
+		t.Errorf("client 1 leaderboard = %s, want %s", got, wantKing)
+	}
+	wantNeo := `{"e":"leaderboard","d":{"w":"daily","top":[{"r":1,"n":"VibeKing","s":4200}]}}`
+	if got := receive(hub.clients[2]); got != wantNeo {
+		t.Errorf("client 2 leaderboard = %s, want %s", got, wantNeo)
+	}
+}
+
+// TestRefreshLeaderboard_OnlyPushesChanges verifies unchanged standings aren't resent.
+func TestRefreshLeaderboard_OnlyPushesChanges(t *testing.T) {
+	// Arrange
+	hub, board := newLeaderboardHub()
+	board.Submit("VibeKing", 4200, time.Now())
+	hub.RefreshLeaderboard()
+	receive(hub.clients[1])
+	receive(hub.clients[2])
+
+	// Act & Assert: nothing changed
+	if hub.RefreshLeaderboard() {
+		t.Error("RefreshLeaderboard() = true with unchanged standings, want false")
+	}
+	if got := receive(hub.clients[1]); got != "" {
+		t.Errorf("unchanged standings queued %s", got)
+	}
+
+	// Act & Assert: a new entry reaches the top
+	board.Submit("Neo", 900, time.Now())
+	if !hub.RefreshLeaderboard() {
+		t.Error("RefreshLeaderboard() = false after a new score, want true")
+	}
+	if got := receive(hub.clients[2]); got == "" {
+		t.Error("RefreshLeaderboard() did not queue the new standings")
+	}
+}
+
+// TestSendLeaderboard_SingleClient verifies the join-time push reaches only one client.
+func TestSendLeaderboard_SingleClient(t *testing.T) {
+	// Arrange
+	hub, _ := newLeaderboardHub()
+
+	// Act
+	hub.SendLeaderboard(2, "Neo")
+
+	// Assert
+	want := `{"e":"leaderboard","d":{"w":"daily","top":[]}}`
+	if got := receive(hub.clients[2]); got != want {
+		t.Errorf("client 2 leaderboard = %s, want %s", got, want)
+	}
+	if got := receive(hub.clients[1]); got != "" {
+		t.Errorf("client 1 received %s, want nothing", got)
+	}
+}
+
+// TestRefreshLeaderboard_Disabled verifies a hub without a leaderboard sends nothing.
+func TestRefreshLeaderboard_Disabled(t *testing.T) {
+	hub := NewClientHub()
+
+	if hub.RefreshLeaderboard() {
+		t.Error("RefreshLeaderboard() without a leaderboard = true, want false")
+	}
+}
@@ -18,55 +18,247 @@ type Message struct {
 // Sent by client immediately after WebSocket connection is established.
 //
 // Example JSON:
-//   {"e": "join", "d": {"n": "PlayerName"}}
+//   {"e": "join", "d": {"n": "PlayerName", "v": 1, "r": "friday-race"}}
+//   {"e": "join", "d": {"n": "PlayerName", "v": 1, "c": "K7M2QX"}}
+//
+// The same payload is used by the create_room event, which ignores R and C
+// and places the player in a newly created private room:
+//   {"e": "create_room", "d": {"n": "PlayerName"}}
 type JoinMessage struct {
 	// N is the player's chosen display name (max 30 characters).
 	// Will be sanitized server-side to prevent XSS attacks.
 	N string `json:"n"`
+
+	// R is the room to join (optional).
+	// Empty auto-matches the player into a public room.
+	R string `json:"r,omitempty"`
+
+	// C is a private room invite code (optional, case-insensitive).
+	// Takes precedence over R when set.
+	C string `json:"c,omitempty"`
+
+	// V is the protocol version the client speaks (optional, 0 means 1).
+	// Versions the server doesn't support get an unsupported_version error.
+	V int `json:"v,omitempty"`
+}
+
+// RoomCreatedMessage is sent by server in response to create_room,
+// immediately before the welcome message.
+//
+// Example JSON:
+//   {"e": "room_created", "d": {"room": "private-K7M2QX", "code": "K7M2QX"}}
+type RoomCreatedMessage struct {
+	// Room is the ID of the newly created private room.
+	Room string `json:"room"`
+
+	// Code is the 6-character invite code friends send in their join message.
+	Code string `json:"code"`
 }
 
 // WelcomeMessage is sent by server after successful join.
 // It assigns the client a unique player ID and provides game initialization data.
 //
 // Example JSON:
-//   {"e": "welcome", "d": {"id": 1, "seed": "vibe-runner-1", "serverTime": 1700000000000}}
+//   {"e": "welcome", "d": {"id": 1, "room": "public-1", "seed": "vibe-runner-public-1-1700000000", "genVersion": 1, "serverTime": 1700000000000, "tok": "9f86d081884c7d659a2feaa0c55ad015"}}
 type WelcomeMessage struct {
 	// ID is the unique player identifier assigned by the server.
 	// Used to identify this player in all subsequent game state messages.
 	ID int `json:"id"`
 
+	// Room is the ID of the room the player was assigned to.
+	Room string `json:"room"`
+
+	// Code is the invite code of the private room (omitted for public rooms).
+	Code string `json:"code,omitempty"`
+
 	// Seed is the master seed for procedural level generation.
 	// All clients use this seed to generate identical obstacle patterns.
-	// Format: "vibe-runner-{sessionID}"
+	// This is the same seed the server's chunk manager uses.
+	// Format: "vibe-runner-{roomID}-{createdAt}"
 	Seed string `json:"seed"`
 
+	// GeneratorVersion identifies the level generation algorithm.
+	// Clients regenerating chunks locally must support this version.
+	GeneratorVersion int `json:"genVersion"`
+
 	// ServerTime is the current server timestamp in milliseconds since Unix epoch.
-	// Used for clock synchronization and latency calculation.
+	// A rough one-shot sample; clients aligning to server ticks use time_sync.
 	ServerTime int64 `json:"serverTime"`
+
+	// Tok is an opaque resume token. If the connection drops, the client can
+	// reconnect and send it in a resume message to get the same player back.
+	// Each token works once; every welcome carries a new one.
+	Tok string `json:"tok"`
+
+	// Resumed is set when this welcome answers a resume rather than a join.
+	Resumed bool `json:"resumed,omitempty"`
+
+	// V is the protocol version the server speaks (ProtocolVersion).
+	V int `json:"v"`
+}
+
+// ResumeMessage reattaches a reconnecting client to its existing player.
+// Sent instead of join as the first message on a new connection, within
+// the server's resume window (10 seconds by default) of the old one dropping.
+//
+// Example JSON:
+//   {"e": "resume", "d": {"tok": "9f86d081884c7d659a2feaa0c55ad015"}}
+//
+// On success the server replies with a welcome for the same player ID
+// (with resumed set and a new token), then the phase and leaderboard, and
+// resends chunks and a full state. An unknown or expired token gets an
+// unknown_session error and the connection is closed; the client should
+// join as a new player.
+type ResumeMessage struct {
+	// Tok is the resume token from the client's last welcome message.
+	Tok string `json:"tok"`
 }
 
 // JumpMessage represents a client's request to jump.
 // Sent when player presses spacebar or jump button.
 //
 // Example JSON:
-//   {"e": "jump", "d": {"t": 1700000000000}}
+//   {"e": "jump", "d": {"t": 1700000000000, "s": 42}}
 type JumpMessage struct {
 	// T is the client timestamp when jump was initiated (milliseconds since Unix epoch).
 	// Used for input prediction and server reconciliation.
 	T int64 `json:"t"`
+
+	// S is the input sequence number. It must increase with every input the
+	// client sends; the server acknowledges it in state updates.
+	// Inputs without a sequence number (0) are applied but never acknowledged.
+	S uint32 `json:"s,omitempty"`
 }
 
 // StateMessage contains the authoritative game state broadcast by server.
-// Sent at 20Hz (every 50ms) to all connected clients.
+// Sent at 20Hz (every 50ms) to all connected clients. Each client's copy
+// acknowledges that client's own inputs.
 //
-// Example JSON:
-//   {"e": "state", "d": {"t": 1700000000000, "p": [{"i": 1, "x": 100, "y": 440}]}}
+// Each client only receives the players near its own player, the race
+// leader and the players it follows (see FollowMessage).
+//
+// Clients that acknowledge states (see AckMessage) receive deltas against
+// their last acknowledged state; others, and clients whose acknowledged state
+// is too old, receive full snapshots. Positions are snapped to 0.1px.
+//
+// Example JSON (full snapshot, then a delta against tick 1234):
+//   {"e": "state", "d": {"t": 1700000000000, "k": 1234, "a": 42, "p": [{"i": 1, "x": 100, "y": 440}, {"i": 2, "x": 80, "y": 440}]}}
+//   {"e": "state", "d": {"t": 1700000000050, "k": 1235, "a": 42, "b": 1234, "p": [{"i": 1, "x": 115, "y": 440}], "s": [{"i": 3, "x": 100, "y": 440}], "r": [2]}}
 type StateMessage struct {
 	// T is the server timestamp when state was generated (milliseconds since Unix epoch).
 	T int64 `json:"t"`
 
+	// K is the server tick number the state was produced at.
+	K uint64 `json:"k"`
+
+	// A is the sequence number of the receiving client's last applied input.
+	// The client replays its unacknowledged inputs (seq > A) on top of this state.
+	A uint32 `json:"a"`
+
+	// B is the tick of the baseline state this delta applies to
+	// (omitted for a full snapshot).
+	B uint64 `json:"b,omitempty"`
+
 	// P is the array of player states (positions only, alive players only).
+	// In a full snapshot it lists every alive player; in a delta only the
+	// players whose position changed since the baseline.
 	P []PlayerState `json:"p"`
+
+	// S lists players that spawned (joined, respawned or came within the
+	// client's interest radius) since the baseline (deltas only).
+	S []PlayerState `json:"s,omitempty"`
+
+	// R lists the IDs of players that despawned (died, left or moved out of
+	// the client's interest radius) since the baseline (deltas only).
+	R []int `json:"r,omitempty"`
+}
+
+// FollowMessage sets the players a client always receives in state updates,
+// however far away they are (e.g. friends). Other players are only sent
+// while within the interest radius of the client's own player.
+//
+// Example JSON:
+//   {"e": "follow", "d": {"i": [12, 15]}}
+type FollowMessage struct {
+	// I is the followed player IDs (replaces the previous list; at most MaxFollowed are kept).
+	I []int `json:"i"`
+}
+
+// AckMessage acknowledges that a client received a state update.
+// Acknowledged states become the baseline for delta-compressed states.
+//
+// Example JSON:
+//   {"e": "ack", "d": {"k": 1234}}
+type AckMessage struct {
+	// K is the tick of the received state.
+	K uint64 `json:"k"`
+}
+
+// PingMessage is a client's application-level heartbeat.
+// Browsers can't see WebSocket ping frames, so clients that want to measure
+// latency send ping and time the pong that echoes it.
+//
+// Example JSON:
+//   {"e": "ping", "d": {"t": 1700000000000}}
+type PingMessage struct {
+	// T is the client timestamp when the ping was sent (milliseconds since Unix epoch).
+	T int64 `json:"t"`
+}
+
+// PongMessage answers a client's ping.
+//
+// Example JSON:
+//   {"e": "pong", "d": {"t": 1700000000000, "s": 1700000000021, "r": 42, "j": 5}}
+type PongMessage struct {
+	// T echoes the timestamp from the client's ping.
+	T int64 `json:"t"`
+
+	// S is the server timestamp when the pong was sent (milliseconds since Unix epoch).
+	S int64 `json:"s"`
+
+	// R is the server's smoothed round-trip time to this client in
+	// milliseconds, measured with WebSocket pings (0 until measured).
+	R int64 `json:"r"`
+
+	// J is the jitter (mean deviation) of the round-trip time in milliseconds.
+	J int64 `json:"j"`
+}
+
+// TimeSyncRequest starts one NTP-style clock synchronization round trip.
+// Clients send TimeSyncRounds of them (one at a time, each after the
+// previous reply) and combine the replies with EstimateClock.
+//
+// Example JSON:
+//   {"e": "time_sync", "d": {"t0": 1700000000000}}
+type TimeSyncRequest struct {
+	// T0 is the client timestamp when the request was sent (milliseconds since Unix epoch).
+	T0 int64 `json:"t0"`
+}
+
+// TimeSyncReply answers a time_sync request with the server's receive and
+// send times and its current tick, so the client can estimate its clock
+// offset and drift and align its prediction to server ticks.
+//
+// Example JSON:
+//   {"e": "time_sync", "d": {"t0": 1700000000000, "t1": 1700000003021, "t2": 1700000003022, "k": 1234, "kt": 1700000003000, "r": 20}}
+type TimeSyncReply struct {
+	// T0 echoes the client's request timestamp.
+	T0 int64 `json:"t0"`
+
+	// T1 is the server time the request was received (ms since Unix epoch).
+	T1 int64 `json:"t1"`
+
+	// T2 is the server time the reply was sent (ms since Unix epoch).
+	T2 int64 `json:"t2"`
+
+	// K is the server's current tick number.
+	K uint64 `json:"k"`
+
+	// KT is the server time tick K represents (ms since Unix epoch).
+	KT int64 `json:"kt"`
+
+	// R is the server tick rate in Hz.
+	R int `json:"r"`
 }
 
 // PlayerState represents a single player's position in the game world.
@@ -88,15 +280,138 @@ type PlayerState struct {
 // Example JSON:
 //   {"e": "death", "d": {"s": 1234}}
 type DeathMessage struct {
-	// S is the player's final score (distance traveled in pixels).
+	// S is the player's final score (distance traveled in pixels since spawning).
+	// Computed by the server from the player's X position.
+	S int `json:"s"`
+}
+
+// PhaseMessage announces a race lifecycle transition to clients.
+// Broadcast to all clients in a room on every phase change, and sent to
+// a newly joined client right after the welcome message.
+//
+// Example JSON:
+//   {"e": "phase", "d": {"p": "countdown", "ms": 3000}}
+//   {"e": "phase", "d": {"p": "results", "ms": 5000, "r": [{"i": 1, "n": "VibeKing", "s": 4200}]}}
+type PhaseMessage struct {
+	// P is the phase name: "waiting", "countdown", "running" or "results".
+	P string `json:"p"`
+
+	// Ms is the time remaining in this phase in milliseconds (countdown and results only).
+	Ms int64 `json:"ms,omitempty"`
+
+	// F is the finish distance in pixels from spawn (omitted for endless races).
+	F float64 `json:"f,omitempty"`
+
+	// R is the final standings, best first (results phase only).
+	R []ResultEntry `json:"r,omitempty"`
+}
+
+// ResultEntry is one player's final standing in a race.
+type ResultEntry struct {
+	// I is the player ID.
+	I int `json:"i"`
+
+	// N is the player's display name.
+	N string `json:"n"`
+
+	// S is the player's score (distance traveled in pixels).
+	S int `json:"s"`
+
+	// F indicates the player reached the finish distance.
+	F bool `json:"f,omitempty"`
+}
+
+// RespawnMessage represents a dead player's request to rejoin the running race.
+// The client may send either "respawn" or "play_again"; both carry no data.
+//
+// Example JSON:
+//   {"e": "respawn", "d": {"s": 43}}
+//
+// On success the server replies with a spawn message. The new position and
+// score are decided by the server; the client cannot choose them.
+type RespawnMessage struct {
+	// S is the input sequence number (optional, shares the jump sequence).
+	S uint32 `json:"s,omitempty"`
+}
+
+// SpawnMessage tells a client where its player respawned.
+// Sent in response to a successful respawn/play_again request.
+//
+// Example JSON:
+//   {"e": "spawn", "d": {"x": 15200, "y": 440}}
+type SpawnMessage struct {
+	// X is the spawn position in pixels. Scores are measured from here.
+	X float64 `json:"x"`
+
+	// Y is the spawn height in pixels (ground level).
+	Y float64 `json:"y"`
+}
+
+// ServerShutdownMessage tells a client the server is going away.
+// Sent to every client when the server shuts down, followed by a WebSocket
+// close frame (1001 going away). Clients should reconnect after a short delay.
+//
+// Example JSON:
+//   {"e": "server_shutdown", "d": {"r": "server restarting"}}
+type ServerShutdownMessage struct {
+	// R is a human-readable reason for the shutdown.
+	R string `json:"r"`
+}
+
+// ErrorMessage tells a client something it sent was rejected.
+// Errors before join (unsupported version, bad join payload, no room) are
+// followed by a WebSocket close frame whose code and reason say the same;
+// errors after join leave the connection open and the event ignored.
+//
+// Example JSON:
+//   {"e": "error", "d": {"c": "not_joined", "e": "jump", "m": "send join or resume first"}}
+type ErrorMessage struct {
+	// C is the machine-readable error code (see ErrorCode).
+	C ErrorCode `json:"c"`
+
+	// E is the event that was rejected (omitted if the frame couldn't be decoded).
+	E string `json:"e,omitempty"`
+
+	// M is a human-readable description for logs and debugging.
+	M string `json:"m"`
+}
+
+// LeaderboardMessage pushes the live leaderboard to a client.
+// Sent after the welcome message, and to every client in a room whenever the
+// top entries change. Each client receives its own rank in Me.
+//
+// Example JSON:
+//   {"e": "leaderboard", "d": {"w": "daily", "top": [{"r": 1, "n": "VibeKing", "s": 4200}], "me": {"r": 7, "n": "Neo", "s": 900}}}
+type LeaderboardMessage struct {
+	// W is the leaderboard window: "daily", "weekly" or "alltime".
+	W string `json:"w"`
+
+	// Top is the top entries, best first.
+	Top []LeaderboardEntry `json:"top"`
+
+	// Me is the receiving player's own entry (omitted if they have no score yet).
+	Me *LeaderboardEntry `json:"me,omitempty"`
+}
+
+// LeaderboardEntry is one ranked player in a LeaderboardMessage.
+type LeaderboardEntry struct {
+	// R is the 1-based rank (ties share a rank).
+	R int `json:"r"`
+
+	// N is the player's display name.
+	N string `json:"n"`
+
+	// S is the player's best score in the window (distance in pixels).
 	S int `json:"s"`
 }
 
 // ChunkMessage delivers a procedurally generated level chunk to clients.
-// Sent when player approaches a new chunk boundary (within 2 screen widths).
+// Each client is sent the chunks around its own player: one behind its
+// current chunk through two ahead by default. A chunk is sent once, until
+// it is unloaded.
 //
 // Example JSON:
-//   {"e": "chunk", "d": {"id": 10, "obs": [{"t": 1, "x": 15000, "y": 0}]}}
+//   {"e": "chunk", "d": {"id": 10, "obs": [{"t": 1, "x": 15000, "y": 0, "w": 40, "h": 100}]}}
 type ChunkMessage struct {
 	// ID is the chunk identifier (sequential integer starting at 0).
 	// Chunk N covers X range [N*5000, (N+1)*5000)
@@ -106,6 +421,17 @@ type ChunkMessage struct {
 	Obs []ObstacleData `json:"obs"`
 }
 
+// ChunkUnloadMessage tells a client it has passed some chunks and may drop them.
+// Sent once the client's player is more than one chunk past them; if the
+// player comes back (e.g. a new race starts at spawn) the chunks are resent.
+//
+// Example JSON:
+//   {"e": "chunk_unload", "d": {"ids": [3, 4]}}
+type ChunkUnloadMessage struct {
+	// IDs is the chunk IDs to unload, ascending.
+	IDs []int `json:"ids"`
+}
+
 // ObstacleData represents a single obstacle within a level chunk.
 type ObstacleData struct {
 	// T is the obstacle type (1=tall, 2=low, 3=spike).
@@ -116,4 +442,10 @@ type ObstacleData struct {
 
 	// Y is the vertical position (0=ground level, positive=elevated).
 	Y float64 `json:"y"`
+
+	// W is the obstacle's overall width in pixels (from the server geometry registry).
+	W float64 `json:"w"`
+
+	// H is the obstacle's overall height in pixels (from the server geometry registry).
+	H float64 `json:"h"`
 }
@@ -2,9 +2,11 @@ package network
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html"
 	"log"
+	"net"
 	"strings"
 	"sync"
 	"time"
@@ -64,36 +66,326 @@ func sanitizePlayerName(name string) string {
 	return name
 }
 
+// sessionEvents are the events that need a joined session. Sent before
+// join or resume, they get a not_joined error.
+var sessionEvents = map[string]bool{
+	"jump":       true,
+	"respawn":    true,
+	"play_again": true,
+	"ack":        true,
+	"follow":     true,
+	"ping":       true,
+	"time_sync":  true,
+}
+
+// clientSession holds the per-player state established by a successful join.
+// It outlives its connection while the player is held for a resume.
+type clientSession struct {
+	// playerID is the ID of this client's player.
+	playerID int
+
+	// playerName is the sanitized display name.
+	playerName string
+
+	// roomID is the room this client was assigned to.
+	roomID string
+
+	// inviteCode is the private room's invite code (empty for public rooms).
+	inviteCode string
+
+	// world is the assigned room's game world.
+	world *game.World
+
+	// hub is the assigned room's client hub.
+	hub *ClientHub
+
+	// rooms is the provider the room slot was reserved from.
+	rooms RoomProvider
+
+	// token is the current resume token; protected by the session registry's mu.
+	token string
+}
+
+// queueInput hands a player input to the room's game ticker.
+// Inputs are applied at the start of the next tick; if the queue is
+// full the input is dropped.
+//
+// Parameters:
+//   - kind: The input to apply
+//   - seq: The client's input sequence number (0 if not sent)
+//   - pressedAt: When the client pressed the input (zero if not sent)
+func (s *clientSession) queueInput(kind game.InputKind, seq uint32, pressedAt time.Time) {
+	cmd := game.InputCommand{PlayerID: s.playerID, Kind: kind, Seq: seq, At: pressedAt}
+	if !s.world.Inputs.Enqueue(cmd) {
+		log.Printf("Dropped %s input from player %d (%s): queue full", kind, s.playerID, s.playerName)
+	}
+}
+
+// inputTime converts a client's input timestamp to server time for lag
+// compensation. The timestamp is moved onto the server clock with the
+// offset measured from the client's time_sync requests, then only trusted
+// as far back as the input could have travelled: half the round trip plus
+// its jitter (see clampInputTime). Clients that haven't synced their clock
+// or answered a ping yet get no compensation.
+//
+// Parameters:
+//   - clientTime: The input's timestamp on the client's clock (zero if not sent)
+//   - receivedAt: When the server received the input
+//
+// Returns:
+//   - time.Time: When the input was pressed in server time, or zero to apply it on arrival
+func (s *clientSession) inputTime(clientTime, receivedAt time.Time) time.Time {
+	if clientTime.IsZero() {
+		return time.Time{}
+	}
+	offset, ok := s.hub.ClockOffset(s.playerID)
+	if !ok {
+		return time.Time{}
+	}
+	rtt, jitter, _ := s.hub.RTT(s.playerID)
+	return clampInputTime(clientTime.Add(offset), receivedAt, rtt/2+jitter)
+}
+
+// clampInputTime limits a press time to how long the input could have been
+// travelling. An honest press is about half a round trip old, so a forged
+// timestamp gains no more rewind than the client's real latency, and a
+// clock running ahead can't place the input in the future.
+//
+// Parameters:
+//   - pressedAt: When the input was pressed, in server time
+//   - receivedAt: When the server received the input
+//   - maxAge: The longest the input could have been travelling
+//
+// Returns:
+//   - time.Time: The press time clamped to [receivedAt-maxAge, receivedAt]
+func clampInputTime(pressedAt, receivedAt time.Time, maxAge time.Duration) time.Time {
+	if earliest := receivedAt.Add(-maxAge); pressedAt.Before(earliest) {
+		return earliest
+	}
+	if pressedAt.After(receivedAt) {
+		return receivedAt
+	}
+	return pressedAt
+}
+
+// parseInput extracts the sequence number and client timestamp from a jump
+// or respawn message.
+//
+// Parameters:
+//   - msg: The parsed base message
+//
+// Returns:
+//   - uint32: The sequence number, or 0 if missing or malformed
+//   - time.Time: When the client pressed the input on its own clock, or zero
+//     if missing or malformed (see clientSession.inputTime)
+func parseInput(msg Message) (uint32, time.Time) {
+	var input JumpMessage
+	switch data := msg.D.(type) {
+	case JumpMessage:
+		// Decoded from a binary frame
+		input = data
+	case RespawnMessage:
+		input.S = data.S
+	default:
+		dataBytes, err := json.Marshal(msg.D)
+		if err != nil {
+			return 0, time.Time{}
+		}
+		if err := json.Unmarshal(dataBytes, &input); err != nil {
+			return 0, time.Time{}
+		}
+	}
+
+	var pressedAt time.Time
+	if input.T > 0 {
+		pressedAt = time.UnixMilli(input.T)
+	}
+	return input.S, pressedAt
+}
+
+// parseAck extracts the acknowledged tick from an ack message.
+//
+// Parameters:
+//   - msg: The parsed base message
+//
+// Returns:
+//   - uint64: The acknowledged state tick
+//   - bool: False if the payload is missing or malformed
+func parseAck(msg Message) (uint64, bool) {
+	if ack, ok := msg.D.(AckMessage); ok {
+		// Decoded from a binary frame
+		return ack.K, ack.K > 0
+	}
+
+	dataBytes, err := json.Marshal(msg.D)
+	if err != nil {
+		return 0, false
+	}
+
+	var ack AckMessage
+	if err := json.Unmarshal(dataBytes, &ack); err != nil {
+		return 0, false
+	}
+	return ack.K, ack.K > 0
+}
+
+// parseFollow extracts the followed player IDs from a follow message.
+//
+// Parameters:
+//   - msg: The parsed base message
+//
+// Returns:
+//   - []int: The followed player IDs (empty clears the list)
+//   - bool: False if the payload is malformed
+func parseFollow(msg Message) ([]int, bool) {
+	dataBytes, err := json.Marshal(msg.D)
+	if err != nil {
+		return nil, false
+	}
+
+	var follow FollowMessage
+	if err := json.Unmarshal(dataBytes, &follow); err != nil {
+		return nil, false
+	}
+	return follow.I, true
+}
+
+// parsePing extracts the client timestamp from a ping message.
+//
+// Parameters:
+//   - msg: The parsed base message
+//
+// Returns:
+//   - int64: The client timestamp to echo (ms since Unix epoch)
+//   - bool: False if the payload is malformed
+func parsePing(msg Message) (int64, bool) {
+	dataBytes, err := json.Marshal(msg.D)
+	if err != nil {
+		return 0, false
+	}
+
+	var ping PingMessage
+	if err := json.Unmarshal(dataBytes, &ping); err != nil {
+		return 0, false
+	}
+	return ping.T, true
+}
+
+// parseTimeSync extracts the client send time from a time_sync request.
+//
+// Parameters:
+//   - msg: The parsed base message
+//
+// Returns:
+//   - int64: The client's send time (T0, ms since Unix epoch)
+//   - bool: False if the payload is malformed
+func parseTimeSync(msg Message) (int64, bool) {
+	dataBytes, err := json.Marshal(msg.D)
+	if err != nil {
+		return 0, false
+	}
+
+	var request TimeSyncRequest
+	if err := json.Unmarshal(dataBytes, &request); err != nil {
+		return 0, false
+	}
+	return request.T0, true
+}
+
+// buildTimeSyncReply creates the reply to a time_sync request from the
+// world's current tick. T2 is stamped when the reply is queued.
+//
+// Parameters:
+//   - t0: The client's send time from the request
+//   - receivedAt: When the server received the request
+//   - world: The client's game world
+//
+// Returns:
+//   - TimeSyncReply: Reply with every field but T2 set
+func buildTimeSyncReply(t0 int64, receivedAt time.Time, world *game.World) TimeSyncReply {
+	tick, tickAt := world.State.TickTime()
+	reply := TimeSyncReply{
+		T0: t0,
+		T1: receivedAt.UnixMilli(),
+		K:  tick,
+		R:  world.Config.TickRate,
+	}
+	if !tickAt.IsZero() {
+		reply.KT = tickAt.UnixMilli()
+	}
+	return reply
+}
+
+// extendReadDeadline gives a client another heartbeat timeout to be heard
+// from. Before join the room provider's heartbeat applies; afterwards the
+// room hub's. A zero timeout disables the deadline.
+//
+// Parameters:
+//   - conn: The client's WebSocket connection
+//   - session: The client's session (nil before join)
+//   - rooms: The room provider the client joins through
+func extendReadDeadline(conn *websocket.Conn, session *clientSession, rooms RoomProvider) {
+	timeout := rooms.HeartbeatTimeout()
+	if session != nil {
+		timeout = session.hub.HeartbeatTimeout()
+	}
+
+	var deadline time.Time
+	if timeout > 0 {
+		deadline = time.Now().Add(timeout)
+	}
+	conn.SetReadDeadline(deadline)
+}
+
 // HandleClient manages the WebSocket connection lifecycle for a single client.
 // It handles message parsing, event routing, player state management, broadcasting, and cleanup.
 //
 // The function runs in its own goroutine (one per connected client).
 // It blocks until the client disconnects or an error occurs.
 //
+// A client whose connection drops keeps its player for the hub's resume
+// grace window: the player is frozen, and a new connection sending resume
+// with the welcome's token takes it over (see handleResume).
+//
+// Once joined, the client is pinged by its write goroutine; clients that
+// answer neither pings nor send messages for the hub's heartbeat timeout
+// are disconnected, so half-open connections don't leave ghost players.
+//
+// Messages the server can't act on are answered with an error event (see
+// ErrorCode). A failed join or resume is fatal: the error is followed by a
+// close frame with a matching WebSocket close code. Anything else (malformed
+// frames, unknown events, game events before join, messages over the rate
+// limit) is ignored and the connection stays open.
+//
 // Parameters:
 //   - conn: The WebSocket connection to manage
-//   - gameState: The shared game state for adding/removing players
-//   - clientHub: The client hub for registering this connection for broadcasts
+//   - rooms: The room provider that assigns the client to a game world on join
 //
 // The function performs these steps:
-//  1. Waits for join message
-//  2. Creates player and adds to game state
-//  3. Registers client with hub for state broadcasts
-//  4. Assigns player ID and sends welcome
-//  5. Enters message handling loop
-//  6. Removes player from game state and hub on disconnect
-func HandleClient(conn *websocket.Conn, gameState *game.GameState, clientHub *ClientHub, chunkManager game.ChunkManager) {
-	// Player ID will be assigned after join message
-	var playerID int
-	var playerName string
+//  1. Waits for join (or create_room) message, or resume to reattach to an existing player
+//  2. Assigns the client to a room (requested, invite code, auto-matched or newly created private room)
+//  3. Creates player and adds to the room's game state
+//  4. Registers client with the room's hub for state and leaderboard broadcasts
+//  5. Assigns player ID and sends welcome (with a resume token), phase and leaderboard
+//  6. Enters message handling loop
+//  7. On disconnect, removes the client from the hub and holds the player for a
+//     resume; if none arrives in time, removes the player and leaves the room
+func HandleClient(conn *websocket.Conn, rooms RoomProvider) {
+	// Session will be established after join message
+	var session *clientSession
 
 	defer func() {
-		// Remove player from game state and client hub on disconnect
-		if playerID != 0 {
-			gameState.RemovePlayer(playerID)
-			clientHub.RemoveClient(playerID)
-			log.Printf("Player removed from game state: ID=%d, Name=%s, Active players: %d",
-				playerID, playerName, gameState.GetPlayerCount())
+		if session != nil {
+			switch rooms.Sessions().detach(session, conn, session.hub.ResumeGrace()) {
+			case detachHeld:
+				log.Printf("Player held for resume in room %s: ID=%d, Name=%s",
+					session.roomID, session.playerID, session.playerName)
+			case detachReleased:
+				// Remove player from game state
+				session.release()
+			case detachTakenOver:
+				// A resumed connection owns the player now
+			}
 		}
 		conn.Close()
 		log.Printf("Client disconnected: %s", conn.RemoteAddr())
@@ -101,93 +393,250 @@ func HandleClient(conn *websocket.Conn, gameState *game.GameState, clientHub *Cl
 
 	log.Printf("Client connected: %s", conn.RemoteAddr())
 
+	// Every pong proves the client alive and measures its round-trip time.
+	// Pong handlers run inside ReadMessage, on this goroutine.
+	extendReadDeadline(conn, session, rooms)
+	conn.SetPongHandler(func(appData string) error {
+		if session != nil {
+			session.hub.RecordPong(session.playerID, appData, time.Now())
+		}
+		extendReadDeadline(conn, session, rooms)
+		return nil
+	})
+
+	// Each connection gets its own message budget
+	limiter := newMessageLimiter(time.Now())
+
 	// Message handling loop
 	for {
 		// Read message from client
-		_, messageBytes, err := conn.ReadMessage()
+		frameType, messageBytes, err := conn.ReadMessage()
 		if err != nil {
 			// Connection closed or error occurred
-			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				log.Printf("Heartbeat timeout for %s: no pong or message received", conn.RemoteAddr())
+			} else if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
 				log.Printf("WebSocket error from %s: %v", conn.RemoteAddr(), err)
 			}
 			break
 		}
+		receivedAt := time.Now()
+		extendReadDeadline(conn, session, rooms)
+
+		// Drop messages over the rate limit, telling the client now and then
+		if allowed, notify := limiter.allow(receivedAt); !allowed {
+			if notify {
+				log.Printf("Rate limiting %s: over %d messages/s", conn.RemoteAddr(), MessageRate)
+				reportError(conn, session, ErrCodeRateLimited, "", fmt.Sprintf("over %d messages per second; messages dropped", MessageRate))
+			}
+			continue
+		}
 
-		// Parse base message structure
-		var msg Message
-		if err := json.Unmarshal(messageBytes, &msg); err != nil {
+		// Parse base message structure (text frames are JSON, binary frames use the binary codec)
+		msg, err := codecForFrame(frameType).Decode(messageBytes)
+		if err != nil {
 			log.Printf("Failed to parse message from %s: %v", conn.RemoteAddr(), err)
-			// Send error response (optional - could skip to ignore malformed messages)
+			reportError(conn, session, ErrCodeBadPayload, "", err.Error())
+			continue
+		}
+
+		// Game events need a player to act on
+		if session == nil && sessionEvents[msg.E] {
+			log.Printf("Ignoring %s from %s before join", msg.E, conn.RemoteAddr())
+			reportError(conn, session, ErrCodeNotJoined, msg.E, "send join or resume first")
 			continue
 		}
 
 		// Route message based on event type
 		switch msg.E {
-		case "join":
-			// Handle join event
-			playerID, playerName, err = handleJoin(conn, msg, gameState)
+		case "join", "create_room":
+			// Ignore repeated joins on an established session
+			if session != nil {
+				log.Printf("Ignoring duplicate %s from player %d (%s)", msg.E, session.playerID, session.playerName)
+				reportError(conn, session, ErrCodeAlreadyJoined, msg.E, "this connection already has a player")
+				continue
+			}
+
+			// Handle join event (create_room joins a new private room)
+			session, err = handleJoin(conn, msg, rooms)
 			if err != nil {
 				log.Printf("Join failed for %s: %v", conn.RemoteAddr(), err)
+				code, closeCode := joinErrorCode(err)
+				rejectClient(conn, code, msg.E, err.Error(), closeCode)
 				return // Close connection on join failure
 			}
+			extendReadDeadline(conn, session, rooms)
+
+			// Register client with the room's hub for state broadcasts
+			// (a room shutting down closes the connection instead)
+			if !session.hub.AddClient(session.playerID, session.playerName, conn) {
+				return
+			}
+
+			// PHASE 4: Send the chunks around the new player (the ticker keeps them coming)
+			if session.world.Chunks != nil {
+				session.hub.SyncPlayerChunks(session.playerID, game.SpawnX, session.world.Chunks, session.world.Config.ChunksAhead)
+			}
+
+			// Tell the new player where the race is (waiting, countdown, running, results)
+			if session.world.Match != nil {
+				session.hub.SendPhase(session.playerID, session.world.Match.CurrentEvent())
+			}
 
-			// Register client with hub for state broadcasts
-			clientHub.AddClient(playerID, conn)
+			// Show the live leaderboard, including the player's own rank
+			session.hub.SendLeaderboard(session.playerID, session.playerName)
 
-			// PHASE 4: Send initial chunks to new player
-			if chunkManager != nil {
-				// Send chunks 0, 1, 2 (initial visible area)
-				for i := 0; i < 3; i++ {
-					chunk := chunkManager.GetOrGenerateChunkInterface(i)
-					if chunk != nil {
-						clientHub.BroadcastChunk(i, chunk)
-					}
-				}
+			log.Printf("Player joined room %s: ID=%d, Name=%s, Position=(%.1f, %.1f), Active players: %d",
+				session.roomID, session.playerID, session.playerName, 100.0, 440.0, session.world.State.GetPlayerCount())
+
+		case "resume":
+			// Reattach to a player whose connection dropped
+			if session != nil {
+				log.Printf("Ignoring resume from already joined player %d (%s)", session.playerID, session.playerName)
+				reportError(conn, session, ErrCodeAlreadyJoined, msg.E, "this connection already has a player")
+				continue
+			}
+
+			session, err = handleResume(conn, msg, rooms.Sessions())
+			if err != nil {
+				log.Printf("Resume failed for %s: %v", conn.RemoteAddr(), err)
+				code, closeCode := joinErrorCode(err)
+				rejectClient(conn, code, msg.E, err.Error(), closeCode)
+				return // Close connection; the client should join afresh
 			}
+			extendReadDeadline(conn, session, rooms)
 
-			log.Printf("Player joined: ID=%d, Name=%s, Position=(%.1f, %.1f), Active players: %d",
-				playerID, playerName, 100.0, 440.0, gameState.GetPlayerCount())
+			// Register the new connection; its chunks and a full state are
+			// resent from scratch by the next tick
+			if !session.hub.AddClient(session.playerID, session.playerName, conn) {
+				return
+			}
+
+			if session.world.Match != nil {
+				session.hub.SendPhase(session.playerID, session.world.Match.CurrentEvent())
+			}
+			session.hub.SendLeaderboard(session.playerID, session.playerName)
+
+			log.Printf("Player resumed in room %s: ID=%d, Name=%s", session.roomID, session.playerID, session.playerName)
 
 		case "jump":
-			// Handle jump event - apply jump to player in game state
-			if playerID != 0 {
-				player := gameState.GetPlayer(playerID)
-				if player != nil {
-					player.Jump()
-					log.Printf("Player %d (%s) jumped", playerID, playerName)
-				}
+			// Queue the jump for the game ticker, which owns all player state
+			seq, clientTime := parseInput(msg)
+			session.queueInput(game.InputJump, seq, session.inputTime(clientTime, receivedAt))
+
+		case "respawn", "play_again":
+			// Queue a respawn request - the ticker picks the spawn point and replies with spawn
+			seq, _ := parseInput(msg)
+			session.queueInput(game.InputRespawn, seq, time.Time{})
+
+		case "ack":
+			// The acknowledged state becomes the baseline for delta states
+			tick, ok := parseAck(msg)
+			if !ok {
+				reportError(conn, session, ErrCodeBadPayload, msg.E, "expected {\"k\": tick}")
+				continue
+			}
+			session.hub.AckState(session.playerID, tick)
+
+		case "follow":
+			// Players this client sees at any distance (friends)
+			followed, ok := parseFollow(msg)
+			if !ok {
+				reportError(conn, session, ErrCodeBadPayload, msg.E, "expected {\"i\": [player IDs]}")
+				continue
+			}
+			session.hub.Follow(session.playerID, followed)
+
+		case "ping":
+			// Application-level heartbeat; echo the client's timestamp
+			clientTime, ok := parsePing(msg)
+			if !ok {
+				reportError(conn, session, ErrCodeBadPayload, msg.E, "expected {\"t\": client time}")
+				continue
+			}
+			session.hub.SendPong(session.playerID, clientTime)
+
+		case "time_sync":
+			// One round of clock synchronization; the client repeats it
+			t0, ok := parseTimeSync(msg)
+			if !ok {
+				reportError(conn, session, ErrCodeBadPayload, msg.E, "expected {\"t0\": client time}")
+				continue
 			}
+			session.hub.RecordTimeSync(session.playerID, t0, receivedAt)
+			session.hub.SendTimeSync(session.playerID, buildTimeSyncReply(t0, receivedAt, session.world))
 
 		default:
 			// Unknown event type
-			log.Printf("Unknown event '%s' from player %d (%s)", msg.E, playerID, playerName)
+			if session != nil {
+				log.Printf("Unknown event '%s' from player %d (%s)", msg.E, session.playerID, session.playerName)
+			} else {
+				log.Printf("Unknown event '%s' from %s", msg.E, conn.RemoteAddr())
+			}
+			reportError(conn, session, ErrCodeUnknownEvent, msg.E, fmt.Sprintf("unknown event %q", msg.E))
 		}
 	}
 }
 
-// handleJoin processes a join request from a newly connected client.
-// It validates the join message, creates a player entity, adds it to game state,
-// assigns a player ID, and sends the welcome response.
+// handleJoin processes a join or create_room request from a newly connected client.
+// It validates the join message, assigns the client to a room, creates a player
+// entity, adds it to the room's game state, and sends the welcome response.
+//
+// For create_room, a new private room is created and a room_created message
+// carrying the shareable invite code is sent before the welcome.
 //
 // Parameters:
 //   - conn: The WebSocket connection to send welcome message on
 //   - msg: The parsed base message containing join data
-//   - gameState: The game state to add the new player to
+//   - rooms: The room provider to join a room through
 //
 // Returns:
-//   - int: Assigned player ID
-//   - string: Sanitized player name
-//   - error: Non-nil if join processing failed
-func handleJoin(conn *websocket.Conn, msg Message, gameState *game.GameState) (int, string, error) {
+//   - *clientSession: The established session (player, room, world, hub, resume token)
+//   - error: Non-nil if join processing failed (no room slot is held); wraps
+//     ErrBadPayload or ErrUnsupportedVersion if the client sent a bad join
+func handleJoin(conn *websocket.Conn, msg Message, rooms RoomProvider) (*clientSession, error) {
 	// Parse join-specific data
 	joinDataBytes, err := json.Marshal(msg.D)
 	if err != nil {
-		return 0, "", fmt.Errorf("failed to marshal join data: %w", err)
+		return nil, fmt.Errorf("failed to marshal join data: %w", err)
 	}
 
 	var joinMsg JoinMessage
 	if err := json.Unmarshal(joinDataBytes, &joinMsg); err != nil {
-		return 0, "", fmt.Errorf("failed to parse join message: %w", err)
+		return nil, fmt.Errorf("%w: failed to parse join message: %v", ErrBadPayload, err)
+	}
+
+	// Refuse clients speaking a protocol this server doesn't
+	if err := checkProtocolVersion(joinMsg.V); err != nil {
+		return nil, err
+	}
+
+	// Assign the client to the requested room, or auto-match
+	request := RoomRequest{
+		RoomID:        joinMsg.R,
+		InviteCode:    joinMsg.C,
+		CreatePrivate: msg.E == "create_room",
+	}
+	assignment, err := rooms.JoinRoom(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to join room: %w", err)
+	}
+	roomID, world := assignment.RoomID, assignment.World
+
+	// Tell the creator of a private room which code to share
+	if request.CreatePrivate {
+		createdMsg := Message{
+			E: "room_created",
+			D: RoomCreatedMessage{
+				Room: roomID,
+				Code: assignment.InviteCode,
+			},
+		}
+		if err := sendMessage(conn, createdMsg); err != nil {
+			rooms.LeaveRoom(roomID)
+			return nil, fmt.Errorf("failed to send room_created message: %w", err)
+		}
 	}
 
 	// Sanitize player name
@@ -199,53 +648,104 @@ func handleJoin(conn *websocket.Conn, msg Message, gameState *game.GameState) (i
 	// Create new player entity at spawn position (100, 440)
 	player := game.NewPlayer(playerID, playerName)
 
-	// Add player to game state
-	gameState.AddPlayer(player)
-
-	// Generate master seed (for now, simple seed - will be improved in Chunk 4)
-	seed := fmt.Sprintf("vibe-runner-%d", playerID)
-
-	// Get current server time in milliseconds
-	serverTime := time.Now().UnixMilli()
+	// Add player to the room's game state
+	world.State.AddPlayer(player)
+
+	session := &clientSession{
+		playerID:   playerID,
+		playerName: playerName,
+		roomID:     roomID,
+		inviteCode: assignment.InviteCode,
+		world:      world,
+		hub:        assignment.Hub,
+		rooms:      rooms,
+	}
 
-	// Create welcome message
-	welcomeData := WelcomeMessage{
-		ID:         playerID,
-		Seed:       seed,
-		ServerTime: serverTime,
+	// Issue the token the client can resume this player with
+	if err := rooms.Sessions().register(session, conn); err != nil {
+		session.release()
+		return nil, err
 	}
 
+	// Create welcome message carrying the world's real seed and generator version
+	welcome := buildWelcomeMessage(playerID, assignment)
+	welcome.Tok = session.token
 	welcomeMsg := Message{
 		E: "welcome",
-		D: welcomeData,
+		D: welcome,
 	}
 
 	// Send welcome message
 	if err := sendMessage(conn, welcomeMsg); err != nil {
-		return 0, "", fmt.Errorf("failed to send welcome message: %w", err)
+		rooms.Sessions().remove(session)
+		session.release()
+		return nil, fmt.Errorf("failed to send welcome message: %w", err)
+	}
+
+	return session, nil
+}
+
+// checkProtocolVersion checks a client's protocol version is supported.
+//
+// Parameters:
+//   - version: The version from the join message (0 if not sent)
+//
+// Returns:
+//   - error: Wraps ErrUnsupportedVersion if the server can't speak it
+func checkProtocolVersion(version int) error {
+	if version == 0 {
+		// Clients from before versioning speak version 1
+		version = 1
+	}
+	if version < MinProtocolVersion || version > ProtocolVersion {
+		return fmt.Errorf("%w: client speaks v%d, server supports v%d to v%d",
+			ErrUnsupportedVersion, version, MinProtocolVersion, ProtocolVersion)
 	}
+	return nil
+}
 
-	return playerID, playerName, nil
+// buildWelcomeMessage creates the welcome payload for a newly joined player.
+// The seed and generator version are taken from the world so clients can
+// regenerate exactly the chunks the server generates.
+//
+// Parameters:
+//   - playerID: The ID assigned to the joining player
+//   - assignment: The room the player was assigned to
+//
+// Returns:
+//   - WelcomeMessage: Welcome data with the current server time
+func buildWelcomeMessage(playerID int, assignment *RoomAssignment) WelcomeMessage {
+	return WelcomeMessage{
+		ID:               playerID,
+		Room:             assignment.RoomID,
+		Code:             assignment.InviteCode,
+		Seed:             assignment.World.Seed,
+		GeneratorVersion: assignment.World.GeneratorVersion,
+		ServerTime:       time.Now().UnixMilli(),
+		V:                ProtocolVersion,
+	}
 }
 
 // sendMessage sends a message to a client over the WebSocket connection.
-// It marshals the message to JSON and writes it to the connection.
+// It encodes the message with the connection's negotiated codec and writes
+// it to the connection.
 //
 // Parameters:
 //   - conn: The WebSocket connection to send on
-//   - msg: The message to send (will be JSON-encoded)
+//   - msg: The message to send
 //
 // Returns:
 //   - error: Non-nil if sending failed
 func sendMessage(conn *websocket.Conn, msg Message) error {
-	// Marshal message to JSON
-	messageBytes, err := json.Marshal(msg)
+	// Encode with the codec picked by the subprotocol (JSON by default)
+	codec := CodecFor(conn.Subprotocol())
+	messageBytes, err := codec.Encode(msg)
 	if err != nil {
 		return fmt.Errorf("failed to marshal message: %w", err)
 	}
 
 	// Write to WebSocket connection
-	if err := conn.WriteMessage(websocket.TextMessage, messageBytes); err != nil {
+	if err := conn.WriteMessage(codec.FrameType(), messageBytes); err != nil {
 		return fmt.Errorf("failed to write message: %w", err)
 	}
 
@@ -4,6 +4,9 @@ import (
 	"html"
 	"strings"
 	"testing"
+	"time"
+	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
 )
 
 // TestSanitizePlayerName_ValidName_ReturnsTrimmedName tests that
@@ -345,3 +348,185 @@ func TestSanitizePlayerName_XSSAttempts(t *testing.T) {
 		})
 	}
 }
+
+// TestBuildWelcomeMessage_UsesWorldSeed tests that the welcome handshake
+// carries the same seed and generator version the chunk manager uses.
+func TestBuildWelcomeMessage_UsesWorldSeed(t *testing.T) {
+	// Arrange
+	chunkManager := generation.NewChunkManager("vibe-runner-1700000000")
+	world := game.NewWorld(chunkManager)
+
+	// Act
+	welcome := buildWelcomeMessage(42, &RoomAssignment{RoomID: "public-1", World: world})
+
+	// Assert
+	if welcome.ID != 42 {
+		t.Errorf("welcome ID = %d, want 42", welcome.ID)
+	}
+	if welcome.Room != "public-1" {
+		t.Errorf("welcome Room = %q, want %q", welcome.Room, "public-1")
+	}
+	if welcome.Seed != chunkManager.Seed() {
+		t.Errorf("welcome Seed = %q, want chunk manager seed %q", welcome.Seed, chunkManager.Seed())
+	}
+	if welcome.GeneratorVersion != generation.GeneratorVersion {
+		t.Errorf("welcome GeneratorVersion = %d, want %d", welcome.GeneratorVersion, generation.GeneratorVersion)
+	}
+	if welcome.ServerTime <= 0 {
+		t.Errorf("welcome ServerTime = %d, want positive", welcome.ServerTime)
+	}
+
+	// A client regenerating from the welcome seed must get the server's chunk
+	local := generation.GenerateChunk(welcome.Seed, 3)
+	server := chunkManager.GetOrGenerateChunk(3)
+	if len(local.Obstacles) != len(server.Obstacles) {
+		t.Fatalf("regenerated chunk has %d obstacles, server has %d", len(local.Obstacles), len(server.Obstacles))
+	}
+	for i := range local.Obstacles {
+		if local.Obstacles[i] != server.Obstacles[i] {
+			t.Errorf("regenerated obstacle %d = %v, server has %v", i, local.Obstacles[i], server.Obstacles[i])
+		}
+	}
+}
+
+// TestClampInputTime verifies press times are limited to how long the input
+// could have been travelling, and never placed in the future.
+func TestClampInputTime(t *testing.T) {
+	receivedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	maxAge := 50 * time.Millisecond
+
+	tests := []struct {
+		name      string
+		pressedAt time.Time
+		want      time.Time
+	}{
+		{name: "in flight", pressedAt: receivedAt.Add(-30 * time.Millisecond), want: receivedAt.Add(-30 * time.Millisecond)},
+		{name: "too old", pressedAt: receivedAt.Add(-2 * time.Second), want: receivedAt.Add(-maxAge)},
+		{name: "in the future", pressedAt: receivedAt.Add(2 * time.Second), want: receivedAt},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampInputTime(tt.pressedAt, receivedAt, maxAge); !got.Equal(tt.want) {
+				t.Errorf("clampInputTime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// newSyncedSession returns a session for player 1 whose client measured an
+// 80ms round trip with no jitter to speak of, and whose clock runs 5s behind
+// the server's with requests taking 40ms to arrive.
+func newSyncedSession(t *testing.T, receivedAt time.Time) *clientSession {
+	t.Helper()
+
+	hub := NewClientHub()
+	hub.mu.Lock()
+	hub.clients[1] = &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.mu.Unlock()
+	for i := 0; i < 20; i++ {
+		hub.RecordPong(1, string(pingPayload(receivedAt.Add(-80*time.Millisecond))), receivedAt)
+	}
+	sentAt := receivedAt.Add(-5*time.Second - 40*time.Millisecond)
+	hub.RecordTimeSync(1, sentAt.UnixMilli(), receivedAt)
+	return &clientSession{playerID: 1, hub: hub}
+}
+
+// TestInputTime_NeedsClockSync verifies inputs aren't compensated until the
+// client has synced its clock and its round-trip time has been measured.
+func TestInputTime_NeedsClockSync(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	hub.mu.Lock()
+	hub.clients[1] = &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.mu.Unlock()
+	session := &clientSession{playerID: 1, hub: hub}
+	receivedAt := time.Now()
+	clientTime := receivedAt.Add(-40 * time.Millisecond)
+
+	// Act
+	unsynced := session.inputTime(clientTime, receivedAt)
+	hub.RecordTimeSync(1, receivedAt.Add(-40*time.Millisecond).UnixMilli(), receivedAt)
+	noRTT := session.inputTime(clientTime, receivedAt)
+
+	// Assert
+	if !unsynced.IsZero() {
+		t.Errorf("inputTime() without time_sync = %v, want zero", unsynced)
+	}
+	if !noRTT.IsZero() {
+		t.Errorf("inputTime() without an RTT sample = %v, want zero", noRTT)
+	}
+}
+
+// TestInputTime_ConvertsClientClock verifies an honest timestamp is moved
+// onto the server clock with the offset from time_sync.
+func TestInputTime_ConvertsClientClock(t *testing.T) {
+	// Arrange: pressed 40ms (one trip) before arrival, on a clock 5s behind
+	receivedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	session := newSyncedSession(t, receivedAt)
+	clientTime := receivedAt.Add(-5*time.Second - 40*time.Millisecond)
+
+	// Act
+	got := session.inputTime(clientTime, receivedAt)
+
+	// Assert
+	if want := receivedAt.Add(-40 * time.Millisecond); !got.Equal(want) {
+		t.Errorf("inputTime() = %v, want %v", got, want)
+	}
+}
+
+// TestInputTime_LimitsForgedEarlyTimestamp verifies a timestamp far in the
+// past gains about one trip of rewind (what an honest press gets), not a
+// full round trip.
+func TestInputTime_LimitsForgedEarlyTimestamp(t *testing.T) {
+	// Arrange
+	receivedAt := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+	session := newSyncedSession(t, receivedAt)
+	_, jitter, _ := session.hub.RTT(1)
+
+	// Act
+	got := session.inputTime(time.UnixMilli(1), receivedAt)
+
+	// Assert
+	if want := receivedAt.Add(-40*time.Millisecond - jitter); !got.Equal(want) {
+		t.Errorf("inputTime() = %v, want half the round trip plus jitter (%s) before arrival, %v",
+			got, jitter, want)
+	}
+	if jitter >= 10*time.Millisecond {
+		t.Errorf("test setup: jitter = %s, want it settled", jitter)
+	}
+}
+
+// TestParseInput verifies sequence numbers and timestamps are read from input payloads.
+func TestParseInput(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    interface{}
+		wantSeq uint32
+		wantAt  int64
+	}{
+		{name: "jump with seq", data: map[string]interface{}{"t": 1700000000000.0, "s": 42.0}, wantSeq: 42, wantAt: 1700000000000},
+		{name: "jump without seq", data: map[string]interface{}{"t": 1700000000000.0}, wantSeq: 0, wantAt: 1700000000000},
+		{name: "empty respawn", data: map[string]interface{}{}, wantSeq: 0},
+		{name: "negative seq", data: map[string]interface{}{"s": -1.0}, wantSeq: 0},
+		{name: "no payload", data: nil, wantSeq: 0},
+		{name: "binary jump", data: JumpMessage{T: 1700000000000, S: 7}, wantSeq: 7, wantAt: 1700000000000},
+		{name: "binary respawn", data: RespawnMessage{S: 8}, wantSeq: 8},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seq, pressedAt := parseInput(Message{E: "jump", D: tt.data})
+
+			if seq != tt.wantSeq {
+				t.Errorf("parseInput() seq = %d, want %d", seq, tt.wantSeq)
+			}
+			if tt.wantAt == 0 && !pressedAt.IsZero() {
+				t.Errorf("parseInput() time = %v, want zero", pressedAt)
+			}
+			if tt.wantAt != 0 && pressedAt.UnixMilli() != tt.wantAt {
+				t.Errorf("parseInput() time = %d, want %d", pressedAt.UnixMilli(), tt.wantAt)
+			}
+		})
+	}
+}
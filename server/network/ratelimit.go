@@ -0,0 +1,71 @@
+package network
+
+import "time"
+
+// Per-connection message rate limits. A client may send MessageBurst
+// messages at once, refilled at MessageRate per second; messages beyond
+// that are dropped.
+const (
+	// MessageRate is the sustained number of messages per second a client may send.
+	MessageRate = 100
+
+	// MessageBurst is the number of messages a client may send at once.
+	MessageBurst = 200
+
+	// rateLimitNoticeInterval is the minimum time between rate_limited
+	// errors to one client, so a flood doesn't get a reply per message.
+	rateLimitNoticeInterval = time.Second
+)
+
+// messageLimiter is a token bucket limiting one connection's incoming
+// messages. Only used by the connection's HandleClient goroutine.
+type messageLimiter struct {
+	// tokens is the number of messages the client may send right now.
+	tokens float64
+
+	// last is when tokens was last refilled.
+	last time.Time
+
+	// noticed is when the client was last told it is rate limited.
+	noticed time.Time
+}
+
+// newMessageLimiter creates a limiter with a full bucket.
+//
+// Parameters:
+//   - now: The current time
+//
+// Returns:
+//   - *messageLimiter: The limiter
+func newMessageLimiter(now time.Time) *messageLimiter {
+	return &messageLimiter{tokens: MessageBurst, last: now}
+}
+
+// allow takes a token for a message received at now.
+//
+// Parameters:
+//   - now: When the message was received
+//
+// Returns:
+//   - bool: True if the message may be handled
+//   - bool: True if a dropped message should be answered with rate_limited
+func (l *messageLimiter) allow(now time.Time) (bool, bool) {
+	if elapsed := now.Sub(l.last); elapsed > 0 {
+		l.tokens += elapsed.Seconds() * MessageRate
+		if l.tokens > MessageBurst {
+			l.tokens = MessageBurst
+		}
+		l.last = now
+	}
+
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, false
+	}
+
+	if now.Sub(l.noticed) < rateLimitNoticeInterval {
+		return false, false
+	}
+	l.noticed = now
+	return false, true
+}
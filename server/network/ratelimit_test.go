@@ -0,0 +1,35 @@
+package network
+
+import (
+	"testing"
+	"time"
+)
+
+// TestMessageLimiter_DropsOverBurst verifies messages beyond the burst are
+// dropped until the bucket refills, with one rate_limited notice per interval.
+func TestMessageLimiter_DropsOverBurst(t *testing.T) {
+	// Arrange
+	start := time.Unix(1700000000, 0)
+	limiter := newMessageLimiter(start)
+	for i := 0; i < MessageBurst; i++ {
+		if allowed, _ := limiter.allow(start); !allowed {
+			t.Fatalf("message %d of the burst dropped", i+1)
+		}
+	}
+
+	// Act
+	firstAllowed, firstNotify := limiter.allow(start)
+	secondAllowed, secondNotify := limiter.allow(start.Add(time.Millisecond))
+	refilledAllowed, _ := limiter.allow(start.Add(time.Millisecond + time.Second/MessageRate))
+
+	// Assert
+	if firstAllowed || !firstNotify {
+		t.Errorf("first message over the burst: allowed %v, notify %v; want dropped with a notice", firstAllowed, firstNotify)
+	}
+	if secondAllowed || secondNotify {
+		t.Errorf("second message over the burst: allowed %v, notify %v; want dropped silently", secondAllowed, secondNotify)
+	}
+	if !refilledAllowed {
+		t.Errorf("message after a refill interval dropped")
+	}
+}
@@ -0,0 +1,59 @@
+package network
+
+import (
+	"errors"
+	"time"
+	"vibe-runner-server/game"
+)
+
+// ErrServerShuttingDown is returned by a RoomProvider that no longer accepts
+// joins because the server is shutting down. Clients are told to come back
+// later (close code 1001) rather than that their join was refused.
+var ErrServerShuttingDown = errors.New(ShutdownReason)
+
+// RoomRequest describes which room a joining client wants to be placed in.
+type RoomRequest struct {
+	// RoomID selects (or creates) a public room by name. Empty auto-matches.
+	RoomID string
+
+	// InviteCode selects an existing private room. Takes precedence over RoomID.
+	InviteCode string
+
+	// CreatePrivate creates a new private room with a fresh invite code.
+	CreatePrivate bool
+}
+
+// RoomAssignment describes the room a client was placed in.
+type RoomAssignment struct {
+	// RoomID is the assigned room's unique ID.
+	RoomID string
+
+	// InviteCode is the private room's shareable code (empty for public rooms).
+	InviteCode string
+
+	// World is the room's game world.
+	World *game.World
+
+	// Hub is the room's client hub.
+	Hub *ClientHub
+}
+
+// RoomProvider assigns joining clients to game rooms.
+// This interface prevents circular dependencies between network and room packages.
+type RoomProvider interface {
+	// JoinRoom reserves a slot in the requested room, creating the room if needed.
+	// Returns an error wrapping ErrServerShuttingDown once the server is shutting down.
+	JoinRoom(request RoomRequest) (*RoomAssignment, error)
+
+	// LeaveRoom releases a slot reserved by JoinRoom.
+	// Empty rooms may be torn down.
+	LeaveRoom(roomID string)
+
+	// HeartbeatTimeout is how long a connection that hasn't joined yet may
+	// stay silent, matching the room hubs' heartbeat (0 for no limit).
+	HeartbeatTimeout() time.Duration
+
+	// Sessions is the registry resume tokens are issued from and redeemed
+	// against, shared by every room.
+	Sessions() *SessionRegistry
+}
@@ -0,0 +1,298 @@
+package network
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"vibe-runner-server/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// DefaultResumeGrace is how long a disconnected player is held for its
+// client to resume before it is removed.
+const DefaultResumeGrace = 10 * time.Second
+
+// ErrUnknownSession is returned when resuming with a token that doesn't
+// match a live or held session (never issued, already used, or expired).
+var ErrUnknownSession = errors.New("unknown or expired session")
+
+// detachResult is what happened to a session when its connection closed.
+type detachResult int
+
+const (
+	// detachReleased means the session ended; the player should be removed.
+	detachReleased detachResult = iota
+
+	// detachHeld means the player is held for a resume until the grace window ends.
+	detachHeld
+
+	// detachTakenOver means a resumed connection already owns the session.
+	detachTakenOver
+)
+
+// resumableSession is a session in the registry, attached to a connection
+// or held waiting for one.
+type resumableSession struct {
+	// session is the client session being kept.
+	session *clientSession
+
+	// conn is the connection currently serving the session (nil while held).
+	conn *websocket.Conn
+
+	// expiry releases the session when the grace window ends (nil while attached).
+	expiry *time.Timer
+}
+
+// SessionRegistry maps resume tokens to sessions. Each token resumes a
+// session once; resuming issues a new token. A RoomProvider owns one
+// registry for all its rooms, so a client can resume into any of them.
+type SessionRegistry struct {
+	// byToken maps each session's current token to it.
+	byToken map[string]*resumableSession
+
+	// mu protects byToken and each session's token, and orders each
+	// session's hub removal and disconnect input against resumes.
+	mu sync.Mutex
+}
+
+// NewSessionRegistry creates an empty session registry.
+//
+// Returns:
+//   - *SessionRegistry: Registry with no sessions
+func NewSessionRegistry() *SessionRegistry {
+	return &SessionRegistry{byToken: make(map[string]*resumableSession)}
+}
+
+// newResumeToken returns a random opaque token.
+func newResumeToken() (string, error) {
+	var token [16]byte
+	if _, err := rand.Read(token[:]); err != nil {
+		return "", fmt.Errorf("failed to generate resume token: %w", err)
+	}
+	return hex.EncodeToString(token[:]), nil
+}
+
+// register adds a newly joined session attached to its connection, and
+// sets the session's token.
+//
+// Parameters:
+//   - session: The joined session
+//   - conn: The connection serving it
+//
+// Returns:
+//   - error: Non-nil if no token could be generated
+func (r *SessionRegistry) register(session *clientSession, conn *websocket.Conn) error {
+	token, err := newResumeToken()
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session.token = token
+	r.byToken[token] = &resumableSession{session: session, conn: conn}
+	return nil
+}
+
+// remove drops a session from the registry, e.g. after a failed welcome.
+func (r *SessionRegistry) remove(session *clientSession) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byToken, session.token)
+}
+
+// detach handles a session's connection closing. A connection that no
+// longer owns the session (it was resumed elsewhere) changes nothing.
+// Otherwise the client is removed from the hub and the session is held for
+// grace, with the player frozen, after which it is released; or it is
+// released straight away if grace is 0.
+//
+// The hub removal and the disconnect input happen under the registry lock,
+// so a resume can't slip in between and have its new connection removed or
+// its resume input overtaken.
+//
+// Parameters:
+//   - session: The connection's session
+//   - conn: The closing connection
+//   - grace: How long to hold the session for a resume
+//
+// Returns:
+//   - detachResult: What the caller should do with the player
+func (r *SessionRegistry) detach(session *clientSession, conn *websocket.Conn, grace time.Duration) detachResult {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.byToken[session.token]
+	if !exists || entry.conn != conn {
+		return detachTakenOver
+	}
+	session.hub.RemoveClient(session.playerID)
+	if grace <= 0 {
+		delete(r.byToken, session.token)
+		return detachReleased
+	}
+
+	// Freeze the player until the client resumes or the window ends
+	session.queueInput(game.InputDisconnect, 0, time.Time{})
+	token := session.token
+	entry.conn = nil
+	entry.expiry = time.AfterFunc(grace, func() {
+		if r.expire(token, entry) {
+			log.Printf("Resume window expired for player %d (%s)", session.playerID, session.playerName)
+			session.release()
+		}
+	})
+	return detachHeld
+}
+
+// expire removes a held session whose grace window ended.
+//
+// Returns:
+//   - bool: False if the session was resumed in the meantime
+func (r *SessionRegistry) expire(token string, entry *resumableSession) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.byToken[token] != entry || entry.conn != nil {
+		return false
+	}
+	delete(r.byToken, token)
+	return true
+}
+
+// resume attaches a session to a new connection and issues it a new token.
+// The session may be held, or still attached to a connection the server
+// hasn't noticed is dead; that connection is removed from the hub and
+// returned for closing.
+//
+// Parameters:
+//   - token: The token from the client's last welcome
+//   - conn: The resuming connection
+//
+// Returns:
+//   - *clientSession: The resumed session (with its new token)
+//   - *websocket.Conn: The connection previously attached (nil if held)
+//   - error: ErrUnknownSession if the token isn't live
+func (r *SessionRegistry) resume(token string, conn *websocket.Conn) (*clientSession, *websocket.Conn, error) {
+	newToken, err := newResumeToken()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry, exists := r.byToken[token]
+	if !exists {
+		return nil, nil, ErrUnknownSession
+	}
+	if entry.expiry != nil {
+		entry.expiry.Stop()
+		entry.expiry = nil
+	}
+
+	previous := entry.conn
+	if previous != nil {
+		entry.session.hub.RemoveClient(entry.session.playerID)
+	}
+	entry.conn = conn
+	delete(r.byToken, token)
+	entry.session.token = newToken
+	r.byToken[newToken] = entry
+	return entry.session, previous, nil
+}
+
+// release removes the session's player from its world and leaves the room.
+// Called when a session ends without being resumed.
+func (s *clientSession) release() {
+	s.world.State.RemovePlayer(s.playerID)
+	log.Printf("Player removed from room %s: ID=%d, Name=%s, Active players: %d",
+		s.roomID, s.playerID, s.playerName, s.world.State.GetPlayerCount())
+	s.rooms.LeaveRoom(s.roomID)
+}
+
+// handleResume processes a resume request from a newly connected client.
+// It rebinds the session named by the token to this connection and sends
+// a welcome carrying the same player ID and a new token. A connection
+// still attached to the session is closed.
+//
+// Parameters:
+//   - conn: The resuming WebSocket connection
+//   - msg: The parsed base message containing the resume token
+//   - sessions: The registry the token was issued from
+//
+// Returns:
+//   - *clientSession: The resumed session
+//   - error: ErrUnknownSession if the token is unknown, wraps ErrBadPayload
+//     if the payload is malformed, or non-nil if the welcome couldn't be sent
+func handleResume(conn *websocket.Conn, msg Message, sessions *SessionRegistry) (*clientSession, error) {
+	resumeDataBytes, err := json.Marshal(msg.D)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal resume data: %w", err)
+	}
+
+	var resumeMsg ResumeMessage
+	if err := json.Unmarshal(resumeDataBytes, &resumeMsg); err != nil {
+		return nil, fmt.Errorf("%w: failed to parse resume message: %v", ErrBadPayload, err)
+	}
+
+	session, previous, err := sessions.resume(resumeMsg.Tok, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// The old connection's HandleClient sees it no longer owns the session
+	if previous != nil {
+		previous.Close()
+	}
+
+	welcome := buildWelcomeMessage(session.playerID, &RoomAssignment{
+		RoomID:     session.roomID,
+		InviteCode: session.inviteCode,
+		World:      session.world,
+		Hub:        session.hub,
+	})
+	welcome.Tok = session.token
+	welcome.Resumed = true
+	if err := sendMessage(conn, Message{E: "welcome", D: welcome}); err != nil {
+		// Keep holding the player for another attempt
+		if sessions.detach(session, conn, session.hub.ResumeGrace()) == detachReleased {
+			session.release()
+		}
+		return nil, fmt.Errorf("failed to send welcome message: %w", err)
+	}
+
+	session.queueInput(game.InputResume, 0, time.Time{})
+	return session, nil
+}
+
+// SetResumeGrace sets how long a disconnected client's player is held for
+// the client to resume.
+//
+// Parameters:
+//   - grace: The hold window (0 removes players as soon as they disconnect)
+func (h *ClientHub) SetResumeGrace(grace time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.resumeGrace = grace
+}
+
+// ResumeGrace returns how long a disconnected client's player is held.
+// Nothing is held once the hub has shut down.
+//
+// Returns:
+//   - time.Duration: The hold window (0 for none)
+func (h *ClientHub) ResumeGrace() time.Duration {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	if h.shutdown {
+		return 0
+	}
+	return h.resumeGrace
+}
@@ -0,0 +1,280 @@
+package network
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+	"vibe-runner-server/game"
+
+	"github.com/gorilla/websocket"
+)
+
+// readWelcome reads messages until the welcome arrives.
+func readWelcome(t *testing.T, client *websocket.Conn) WelcomeMessage {
+	t.Helper()
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		_, data, err := client.ReadMessage()
+		if err != nil {
+			t.Fatalf("ReadMessage() error = %v, want welcome", err)
+		}
+		var msg struct {
+			E string         `json:"e"`
+			D WelcomeMessage `json:"d"`
+		}
+		if err := json.Unmarshal(data, &msg); err == nil && msg.E == "welcome" {
+			return msg.D
+		}
+	}
+}
+
+// expectClosed verifies the server closes a client's connection.
+func expectClosed(t *testing.T, client *websocket.Conn, why string) {
+	t.Helper()
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := client.ReadMessage(); err != nil {
+			if netErr, ok := err.(interface{ Timeout() bool }); ok && netErr.Timeout() {
+				t.Errorf("%s: connection still open", why)
+			}
+			return
+		}
+	}
+}
+
+// waitFor polls cond until it holds or a second passes.
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if cond() {
+			return
+		}
+	}
+	t.Fatalf("timed out waiting for %s", what)
+}
+
+// newResumeTestRoom returns a room whose hub holds players for grace.
+func newResumeTestRoom(grace time.Duration) *singleRoom {
+	hub := NewClientHub()
+	hub.SetResumeGrace(grace)
+	return newSingleRoom(game.NewWorld(nil), hub)
+}
+
+// TestResume_RebindsHeldPlayer verifies a client reconnecting with its
+// token gets the same player back, and the token can't be reused.
+func TestResume_RebindsHeldPlayer(t *testing.T) {
+	// Arrange: join, then drop the connection
+	rooms := newResumeTestRoom(time.Minute)
+	url := newClientServer(t, rooms)
+	first := dialSending(t, url, Message{E: "join", D: JoinMessage{N: "Runner"}}, true)
+	joined := readWelcome(t, first)
+	first.Close()
+	waitFor(t, "the dropped client to leave the hub", func() bool {
+		rooms.hub.mu.RLock()
+		defer rooms.hub.mu.RUnlock()
+		return len(rooms.hub.clients) == 0
+	})
+
+	// Act
+	second := dialSending(t, url, Message{E: "resume", D: ResumeMessage{Tok: joined.Tok}}, true)
+	resumed := readWelcome(t, second)
+	reused := dialSending(t, url, Message{E: "resume", D: ResumeMessage{Tok: joined.Tok}}, true)
+
+	// Assert
+	if joined.Tok == "" {
+		t.Fatal("welcome carried no resume token")
+	}
+	if resumed.ID != joined.ID || !resumed.Resumed {
+		t.Errorf("resume welcome = %+v, want resumed player %d", resumed, joined.ID)
+	}
+	if resumed.Tok == "" || resumed.Tok == joined.Tok {
+		t.Errorf("resume welcome token = %q, want a new token", resumed.Tok)
+	}
+	if count := rooms.world.State.GetPlayerCount(); count != 1 {
+		t.Errorf("players = %d, want the one resumed player", count)
+	}
+	expectClosed(t, reused, "resume with a used token")
+}
+
+// TestResume_TakesOverLiveConnection verifies a resume while the old
+// connection still looks alive closes the old one and keeps one client.
+func TestResume_TakesOverLiveConnection(t *testing.T) {
+	// Arrange
+	rooms := newResumeTestRoom(time.Minute)
+	url := newClientServer(t, rooms)
+	first := dialSending(t, url, Message{E: "join", D: JoinMessage{N: "Runner"}}, true)
+	joined := readWelcome(t, first)
+
+	// Act
+	second := dialSending(t, url, Message{E: "resume", D: ResumeMessage{Tok: joined.Tok}}, true)
+	resumed := readWelcome(t, second)
+
+	// Assert
+	expectClosed(t, first, "connection replaced by a resume")
+	if resumed.ID != joined.ID {
+		t.Errorf("resumed player = %d, want %d", resumed.ID, joined.ID)
+	}
+	rooms.hub.mu.RLock()
+	client := rooms.hub.clients[joined.ID]
+	clients := len(rooms.hub.clients)
+	rooms.hub.mu.RUnlock()
+	if clients != 1 || client == nil {
+		t.Errorf("hub clients = %d, want only the resumed connection", clients)
+	}
+	if count := rooms.world.State.GetPlayerCount(); count != 1 {
+		t.Errorf("players = %d, want 1", count)
+	}
+}
+
+// TestResume_ExpiresAfterGrace verifies a held player is removed once the
+// grace window passes, after which its token no longer works.
+func TestResume_ExpiresAfterGrace(t *testing.T) {
+	// Arrange
+	rooms := newResumeTestRoom(30 * time.Millisecond)
+	url := newClientServer(t, rooms)
+	first := dialSending(t, url, Message{E: "join", D: JoinMessage{N: "Runner"}}, true)
+	joined := readWelcome(t, first)
+
+	// Act
+	first.Close()
+	waitFor(t, "the held player to be removed", func() bool {
+		return rooms.world.State.GetPlayerCount() == 0
+	})
+	late := dialSending(t, url, Message{E: "resume", D: ResumeMessage{Tok: joined.Tok}}, true)
+
+	// Assert
+	expectClosed(t, late, "resume after the grace window")
+}
+
+// TestDisconnect_HoldsPlayerFrozen verifies a dropped client's player stays
+// in the world and is frozen by the ticker.
+func TestDisconnect_HoldsPlayerFrozen(t *testing.T) {
+	// Arrange
+	rooms := newResumeTestRoom(time.Minute)
+	url := newClientServer(t, rooms)
+	first := dialSending(t, url, Message{E: "join", D: JoinMessage{N: "Runner"}}, true)
+	joined := readWelcome(t, first)
+
+	// Act
+	first.Close()
+	waitFor(t, "the disconnect input", func() bool {
+		rooms.world.Step(1, nil)
+		return rooms.world.State.GetPlayer(joined.ID).Disconnected
+	})
+
+	// Assert
+	if count := rooms.world.State.GetPlayerCount(); count != 1 {
+		t.Errorf("players = %d, want the held player", count)
+	}
+}
+
+// newAttachedSession joins player 1 to the room and registers its session
+// as served by conn, with a stand-in hub client for it (AddClient needs a
+// live connection).
+func newAttachedSession(t *testing.T, rooms *singleRoom, conn *websocket.Conn) *clientSession {
+	t.Helper()
+
+	rooms.world.State.AddPlayer(game.NewPlayer(1, "Runner"))
+	session := &clientSession{playerID: 1, playerName: "Runner", world: rooms.world, hub: rooms.hub, rooms: rooms}
+	if err := rooms.sessions.register(session, conn); err != nil {
+		t.Fatalf("register() error = %v", err)
+	}
+	attachClient(rooms.hub, conn)
+	return session
+}
+
+// attachClient adds a stand-in hub client for player 1 served by conn.
+func attachClient(hub *ClientHub, conn *websocket.Conn) {
+	hub.mu.Lock()
+	defer hub.mu.Unlock()
+	hub.clients[1] = &ClientConnection{PlayerID: 1, Conn: conn, SendChan: make(chan []byte, 10)}
+}
+
+// resumeOn resumes a session on conn the way handleResume and HandleClient
+// do, minus the welcome: the new connection joins the hub and unfreezes
+// the player.
+func resumeOn(t *testing.T, rooms *singleRoom, token string, conn *websocket.Conn) {
+	t.Helper()
+
+	session, _, err := rooms.sessions.resume(token, conn)
+	if err != nil {
+		t.Errorf("resume() error = %v", err)
+		return
+	}
+	attachClient(rooms.hub, conn)
+	session.queueInput(game.InputResume, 0, time.Time{})
+}
+
+// TestDetach_InterleavedWithResume verifies a resume racing the old
+// connection's cleanup always ends with the new connection in the hub and
+// the player unfrozen, whichever runs first.
+func TestDetach_InterleavedWithResume(t *testing.T) {
+	for round := 0; round < 200; round++ {
+		// Arrange
+		rooms := newResumeTestRoom(time.Minute)
+		old, resumed := new(websocket.Conn), new(websocket.Conn)
+		session := newAttachedSession(t, rooms, old)
+		token := session.token
+
+		// Act: the old connection closes while the client resumes
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			<-start
+			rooms.sessions.detach(session, old, rooms.hub.ResumeGrace())
+		}()
+		go func() {
+			defer wg.Done()
+			<-start
+			resumeOn(t, rooms, token, resumed)
+		}()
+		close(start)
+		wg.Wait()
+		rooms.world.Step(1, nil)
+
+		// Assert
+		rooms.hub.mu.RLock()
+		client := rooms.hub.clients[1]
+		rooms.hub.mu.RUnlock()
+		if client == nil || client.Conn != resumed {
+			t.Fatalf("round %d: hub client = %+v, want the resumed connection", round, client)
+		}
+		if rooms.world.State.GetPlayer(1).Disconnected {
+			t.Fatalf("round %d: resumed player left frozen", round)
+		}
+	}
+}
+
+// TestDetach_ThenResume verifies a resume arriving just after the old
+// connection was detached keeps its hub entry and unfreezes the player.
+func TestDetach_ThenResume(t *testing.T) {
+	// Arrange
+	rooms := newResumeTestRoom(time.Minute)
+	old, resumed := new(websocket.Conn), new(websocket.Conn)
+	session := newAttachedSession(t, rooms, old)
+
+	// Act
+	result := rooms.sessions.detach(session, old, rooms.hub.ResumeGrace())
+	resumeOn(t, rooms, session.token, resumed)
+	rooms.world.Step(1, nil)
+
+	// Assert
+	if result != detachHeld {
+		t.Errorf("detach() = %d, want detachHeld", result)
+	}
+	rooms.hub.mu.RLock()
+	client := rooms.hub.clients[1]
+	rooms.hub.mu.RUnlock()
+	if client == nil || client.Conn != resumed {
+		t.Errorf("hub client = %+v, want the resumed connection", client)
+	}
+	if rooms.world.State.GetPlayer(1).Disconnected {
+		t.Error("resumed player left frozen")
+	}
+}
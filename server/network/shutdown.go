@@ -0,0 +1,80 @@
+package network
+
+import (
+	"context"
+	"log"
+
+	"github.com/gorilla/websocket"
+)
+
+// ShutdownReason is the reason given to clients in the server_shutdown event.
+const ShutdownReason = "server shutting down"
+
+// shutdownCloseFrame is the WebSocket close frame sent after server_shutdown.
+var shutdownCloseFrame = websocket.FormatCloseMessage(websocket.CloseGoingAway, ShutdownReason)
+
+// Shutdown says goodbye to every client in the hub.
+// Each client is sent a server_shutdown event, then its write goroutine
+// flushes the queued messages and sends a close frame (1001 going away).
+// Clients are unregistered immediately, so no further broadcasts reach them,
+// and clients added afterwards are rejected and closed straight away.
+//
+// The reading side of each connection is left to HandleClient, which sees
+// the client's close reply and cleans up the player as on any disconnect.
+//
+// Parameters:
+//   - ctx: Bounds how long to wait for the goodbyes to be written
+//
+// Returns:
+//   - error: ctx.Err() if some clients weren't flushed before ctx ended
+func (h *ClientHub) Shutdown(ctx context.Context) error {
+	encoder := newMessageEncoder(Message{
+		E: "server_shutdown",
+		D: ServerShutdownMessage{R: ShutdownReason},
+	})
+
+	h.mu.Lock()
+	h.shutdown = true
+	clients := make([]*ClientConnection, 0, len(h.clients))
+	for playerID, client := range h.clients {
+		clients = append(clients, client)
+		delete(h.clients, playerID)
+	}
+	h.mu.Unlock()
+
+	// Unregistered clients can't be closed by RemoveClient, so their
+	// channels are still open here
+	for _, client := range clients {
+		messageBytes, err := encoder.encodeFor(client)
+		if err != nil {
+			log.Printf("Failed to marshal server_shutdown message: %v", err)
+		} else {
+			// Wait for room in the queue rather than drop the goodbye
+			select {
+			case client.SendChan <- messageBytes:
+			case <-ctx.Done():
+			}
+		}
+
+		client.mu.Lock()
+		client.closed = true
+		client.closeFrame = shutdownCloseFrame
+		close(client.SendChan)
+		client.mu.Unlock()
+	}
+
+	for _, client := range clients {
+		if client.done == nil {
+			continue // No write goroutine was started
+		}
+		select {
+		case <-client.done:
+		case <-ctx.Done():
+			log.Printf("Shutdown deadline reached with clients still flushing: %v", ctx.Err())
+			return ctx.Err()
+		}
+	}
+
+	log.Printf("Hub shut down, %d clients notified", len(clients))
+	return nil
+}
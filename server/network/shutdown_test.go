@@ -0,0 +1,142 @@
+package network
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// TestShutdown_NotifiesAndCloses connects a real WebSocket client and verifies
+// it receives server_shutdown followed by a going-away close frame.
+func TestShutdown_NotifiesAndCloses(t *testing.T) {
+	// Arrange: a server that registers every connection with the hub
+	hub := NewClientHub()
+	added := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		hub.AddClient(1, "Runner", conn)
+		close(added)
+		// Keep reading so the close handshake completes
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+	<-added
+
+	// Act
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := hub.Shutdown(ctx); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+
+	// Assert
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, data, err := client.ReadMessage()
+	if err != nil {
+		t.Fatalf("ReadMessage() error = %v, want server_shutdown", err)
+	}
+	var msg Message
+	if err := json.Unmarshal(data, &msg); err != nil || msg.E != "server_shutdown" {
+		t.Errorf("first message = %s, want server_shutdown event", data)
+	}
+
+	_, _, err = client.ReadMessage()
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Errorf("after server_shutdown: error = %v, want close 1001", err)
+	}
+}
+
+// TestShutdown_UnregistersClients verifies no broadcast reaches a client
+// after shutdown, and the goodbye is queued even without a write goroutine.
+func TestShutdown_UnregistersClients(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.clients[1] = client
+
+	// Act
+	err := hub.Shutdown(context.Background())
+	hub.SendDeath(1, 500)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	if len(hub.clients) != 0 {
+		t.Errorf("clients after Shutdown() = %d, want 0", len(hub.clients))
+	}
+	var events []string
+	for data := range client.SendChan {
+		var msg Message
+		json.Unmarshal(data, &msg)
+		events = append(events, msg.E)
+	}
+	if len(events) != 1 || events[0] != "server_shutdown" {
+		t.Errorf("queued events = %v, want [server_shutdown]", events)
+	}
+}
+
+// TestAddClient_RejectedAfterShutdown verifies a client added after shutdown
+// isn't registered and is sent a going-away close frame.
+func TestAddClient_RejectedAfterShutdown(t *testing.T) {
+	// Arrange: a shut down hub, and a server that adds every connection to it
+	hub := NewClientHub()
+	if err := hub.Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown() error = %v", err)
+	}
+	added := make(chan bool, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := (&websocket.Upgrader{}).Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		added <- hub.AddClient(1, "Late", conn)
+		conn.ReadMessage() // Wait for the close reply
+	}))
+	defer server.Close()
+
+	// Act
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer client.Close()
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	_, _, err = client.ReadMessage()
+
+	// Assert
+	var closeErr *websocket.CloseError
+	if !errors.As(err, &closeErr) || closeErr.Code != websocket.CloseGoingAway {
+		t.Errorf("ReadMessage() error = %v, want close 1001", err)
+	}
+	if <-added {
+		t.Error("AddClient() = true after shutdown, want false")
+	}
+	hub.mu.RLock()
+	defer hub.mu.RUnlock()
+	if len(hub.clients) != 0 {
+		t.Errorf("clients = %d, want the late client rejected", len(hub.clients))
+	}
+}
@@ -0,0 +1,171 @@
+package network
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"vibe-runner-server/game"
+)
+
+const (
+	// PositionScale is the number of position steps per pixel. Snapshot
+	// positions are snapped to this grid (0.1px), so players that haven't
+	// moved compare equal, and the binary codec sends them as int32 steps.
+	PositionScale = 10
+
+	// SnapshotHistorySize is the number of state snapshots remembered per
+	// client as delta baselines. A client whose last acknowledged snapshot
+	// is older than this gets a full snapshot instead of a delta.
+	SnapshotHistorySize = 32
+)
+
+// quantize snaps a position to the PositionScale grid.
+func quantize(position float64) float64 {
+	return math.Round(position*PositionScale) / PositionScale
+}
+
+// snapshot is the racing players' quantized positions at one tick.
+// A snapshot is shared by every client's history and never modified.
+type snapshot struct {
+	// tick is the server tick the snapshot was taken at.
+	tick uint64
+
+	// players holds the racing players, sorted by ID.
+	players []PlayerState
+
+	// byID indexes players by player ID.
+	byID map[int]PlayerState
+
+	// byX holds the players sorted by X position (then ID), for interest
+	// filtering. Only set on snapshots built by newSnapshot.
+	byX []PlayerState
+}
+
+// newSnapshot captures the racing players of a game state: the alive ones,
+// and those whose death is still pending, since a late jump may undo it.
+//
+// Parameters:
+//   - tick: The tick the state was produced at
+//   - players: All players in the world (dead players are left out)
+//
+// Returns:
+//   - *snapshot: Quantized positions of the racing players
+func newSnapshot(tick uint64, players []*game.Player) *snapshot {
+	snap := &snapshot{
+		tick:    tick,
+		players: make([]PlayerState, 0, len(players)),
+		byID:    make(map[int]PlayerState, len(players)),
+	}
+	for _, player := range players {
+		if !player.IsAlive && !player.DeathPending() {
+			continue
+		}
+		state := PlayerState{
+			I: player.ID,
+			X: quantize(player.X),
+			Y: quantize(player.Y),
+		}
+		snap.players = append(snap.players, state)
+		snap.byID[player.ID] = state
+	}
+	sort.Slice(snap.players, func(i, j int) bool { return snap.players[i].I < snap.players[j].I })
+
+	snap.byX = append([]PlayerState(nil), snap.players...)
+	sort.SliceStable(snap.byX, func(i, j int) bool { return snap.byX[i].X < snap.byX[j].X })
+	return snap
+}
+
+// snapshotHistory remembers the snapshots recently sent to one client and
+// which of them the client has acknowledged.
+// The zero value is an empty history, which produces full snapshots.
+type snapshotHistory struct {
+	// sent is a ring of the last SnapshotHistorySize snapshots queued for the client.
+	sent [SnapshotHistorySize]*snapshot
+
+	// next is the ring index the next snapshot is written to.
+	next int
+
+	// acked is the newest tick the client has acknowledged (0 if none).
+	acked uint64
+
+	// mu protects all fields; acks arrive from the client's read goroutine
+	// while the ticker builds deltas.
+	mu sync.Mutex
+}
+
+// record remembers a snapshot queued for the client.
+func (h *snapshotHistory) record(snap *snapshot) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sent[h.next] = snap
+	h.next = (h.next + 1) % SnapshotHistorySize
+}
+
+// ack records that the client has received the snapshot of a tick.
+// Acks older than the newest one are ignored.
+func (h *snapshotHistory) ack(tick uint64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if tick > h.acked {
+		h.acked = tick
+	}
+}
+
+// baseline returns the acknowledged snapshot to encode a delta against.
+//
+// Returns:
+//   - *snapshot: The acknowledged snapshot, or nil if there is no ack or it
+//     has fallen out of the history (the client needs a full snapshot)
+func (h *snapshotHistory) baseline() *snapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.acked == 0 {
+		return nil
+	}
+	for _, snap := range h.sent {
+		if snap != nil && snap.tick == h.acked {
+			return snap
+		}
+	}
+	return nil
+}
+
+// buildState builds a client's state message for a snapshot: a delta
+// against its baseline if it has one, or a full snapshot otherwise.
+//
+// A delta (B set to the baseline tick) lists in P only players whose
+// position changed, in S players that appeared since the baseline, and in
+// R the IDs of players that left or died. A full snapshot (B = 0) lists every
+// alive player in P.
+//
+// Parameters:
+//   - current: The snapshot to send
+//   - base: The client's acknowledged snapshot, or nil
+//
+// Returns:
+//   - StateMessage: State with tick and players set (T and A are left to the caller)
+func buildState(current, base *snapshot) StateMessage {
+	state := StateMessage{K: current.tick}
+	if base == nil {
+		state.P = current.players
+		return state
+	}
+
+	state.B = base.tick
+	state.P = []PlayerState{}
+	for _, player := range current.players {
+		previous, existed := base.byID[player.I]
+		switch {
+		case !existed:
+			state.S = append(state.S, player)
+		case previous != player:
+			state.P = append(state.P, player)
+		}
+	}
+	for _, player := range base.players {
+		if _, exists := current.byID[player.I]; !exists {
+			state.R = append(state.R, player.I)
+		}
+	}
+	return state
+}
@@ -0,0 +1,222 @@
+package network
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
+)
+
+// newTestSnapshot builds a snapshot at a tick from player positions.
+func newTestSnapshot(tick uint64, players ...PlayerState) *snapshot {
+	gamePlayers := make([]*game.Player, len(players))
+	for i, state := range players {
+		gamePlayers[i] = game.NewPlayer(state.I, "Runner")
+		gamePlayers[i].X = state.X
+		gamePlayers[i].Y = state.Y
+	}
+	return newSnapshot(tick, gamePlayers)
+}
+
+// TestNewSnapshot_QuantizesAndSkipsDead verifies snapshots hold alive
+// players only, sorted by ID, with positions snapped to the 0.1px grid.
+func TestNewSnapshot_QuantizesAndSkipsDead(t *testing.T) {
+	// Arrange
+	runner := game.NewPlayer(2, "Runner")
+	runner.X = 1234.5678
+	runner.Y = 439.96
+	dead := game.NewPlayer(3, "Dead")
+	dead.Kill()
+	first := game.NewPlayer(1, "First")
+
+	// Act
+	snap := newSnapshot(7, []*game.Player{runner, dead, first})
+
+	// Assert
+	want := []PlayerState{
+		{I: 1, X: first.X, Y: first.Y},
+		{I: 2, X: 1234.6, Y: 440},
+	}
+	if !reflect.DeepEqual(snap.players, want) {
+		t.Errorf("newSnapshot() players = %+v, want %+v", snap.players, want)
+	}
+}
+
+// TestNewSnapshot_KeepsPendingDeaths verifies a player who just hit an
+// obstacle stays in snapshots while a late jump may still save them, so
+// clients don't see them despawn and respawn.
+func TestNewSnapshot_KeepsPendingDeaths(t *testing.T) {
+	// Arrange: run a player who never jumps into the first obstacle
+	world := game.NewWorld(generation.NewChunkManager("pending-seed"))
+	runner := game.NewPlayer(1, "Runner")
+	world.State.AddPlayer(runner)
+	for runner.IsAlive {
+		if world.Step(1, nil) > 2000 {
+			t.Fatal("test setup: runner never hit an obstacle")
+		}
+	}
+	if !runner.DeathPending() {
+		t.Fatal("test setup: death confirmed on the collision tick, want it pending")
+	}
+
+	// Act
+	snap := newSnapshot(world.State.Tick(), world.State.GetAllPlayers())
+
+	// Assert
+	if _, ok := snap.byID[runner.ID]; !ok {
+		t.Error("newSnapshot() left out a player whose death is pending")
+	}
+}
+
+// TestBuildState_FullSnapshotWithoutBaseline verifies clients without an
+// acknowledged baseline get every alive player.
+func TestBuildState_FullSnapshotWithoutBaseline(t *testing.T) {
+	// Arrange
+	current := newTestSnapshot(10, PlayerState{I: 1, X: 100, Y: 440}, PlayerState{I: 2, X: 200, Y: 440})
+
+	// Act
+	state := buildState(current, nil)
+
+	// Assert
+	if state.K != 10 || state.B != 0 {
+		t.Errorf("buildState() K=%d B=%d, want K=10 B=0", state.K, state.B)
+	}
+	if len(state.P) != 2 || state.S != nil || state.R != nil {
+		t.Errorf("buildState() = %+v, want both players in P only", state)
+	}
+}
+
+// TestBuildState_DeltaAgainstBaseline verifies a delta lists moved players,
+// spawns and despawns, and leaves out players that didn't move.
+func TestBuildState_DeltaAgainstBaseline(t *testing.T) {
+	// Arrange
+	base := newTestSnapshot(10,
+		PlayerState{I: 1, X: 100, Y: 440},
+		PlayerState{I: 2, X: 200, Y: 440},
+		PlayerState{I: 3, X: 300, Y: 440},
+	)
+	current := newTestSnapshot(11,
+		PlayerState{I: 1, X: 115, Y: 440},    // moved
+		PlayerState{I: 2, X: 200.01, Y: 440}, // moved less than the grid
+		PlayerState{I: 4, X: 5000, Y: 440},   // spawned
+	)
+
+	// Act
+	state := buildState(current, base)
+
+	// Assert
+	if state.B != 10 {
+		t.Errorf("buildState() B = %d, want baseline tick 10", state.B)
+	}
+	if want := []PlayerState{{I: 1, X: 115, Y: 440}}; !reflect.DeepEqual(state.P, want) {
+		t.Errorf("buildState() P = %+v, want %+v", state.P, want)
+	}
+	if want := []PlayerState{{I: 4, X: 5000, Y: 440}}; !reflect.DeepEqual(state.S, want) {
+		t.Errorf("buildState() S = %+v, want %+v", state.S, want)
+	}
+	if want := []int{3}; !reflect.DeepEqual(state.R, want) {
+		t.Errorf("buildState() R = %v, want %v", state.R, want)
+	}
+}
+
+// receiveState reads one queued state message from a client.
+func receiveState(t *testing.T, client *ClientConnection) StateMessage {
+	t.Helper()
+
+	var msg struct {
+		E string       `json:"e"`
+		D StateMessage `json:"d"`
+	}
+	if err := json.Unmarshal(<-client.SendChan, &msg); err != nil || msg.E != "state" {
+		t.Fatalf("queued message is not a state event (error %v)", err)
+	}
+	return msg.D
+}
+
+// TestBroadcastState_DeltaAfterAck verifies a client gets full snapshots
+// until it acknowledges one, then deltas against it.
+func TestBroadcastState_DeltaAfterAck(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.clients[1] = client
+	gameState := game.NewGameState()
+	runner := game.NewPlayer(1, "Runner")
+	idle := game.NewPlayer(2, "Idle")
+	gameState.AddPlayer(runner)
+	gameState.AddPlayer(idle)
+
+	// Act
+	gameState.AdvanceTick()
+	hub.BroadcastState(gameState)
+	first := receiveState(t, client)
+	gameState.AdvanceTick()
+	hub.BroadcastState(gameState)
+	unacked := receiveState(t, client)
+
+	hub.AckState(1, first.K)
+	runner.X += 15
+	gameState.AdvanceTick()
+	hub.BroadcastState(gameState)
+	delta := receiveState(t, client)
+
+	// Assert
+	if first.B != 0 || len(first.P) != 2 || unacked.B != 0 || len(unacked.P) != 2 {
+		t.Errorf("states before ack = %+v, %+v, want full snapshots", first, unacked)
+	}
+	if delta.B != first.K {
+		t.Errorf("state after ack B = %d, want baseline %d", delta.B, first.K)
+	}
+	if len(delta.P) != 1 || delta.P[0].I != 1 {
+		t.Errorf("state after ack P = %+v, want only the runner", delta.P)
+	}
+}
+
+// TestBroadcastState_FullSnapshotWhenBaselineTooOld verifies a client whose
+// acknowledged state left the history gets a full snapshot again.
+func TestBroadcastState_FullSnapshotWhenBaselineTooOld(t *testing.T) {
+	// Arrange
+	hub := NewClientHub()
+	client := &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 1)}
+	hub.clients[1] = client
+	gameState := game.NewGameState()
+	gameState.AddPlayer(game.NewPlayer(1, "Runner"))
+
+	gameState.AdvanceTick()
+	hub.BroadcastState(gameState)
+	hub.AckState(1, receiveState(t, client).K)
+
+	// Act: the client stops acknowledging
+	for i := 0; i < SnapshotHistorySize; i++ {
+		gameState.AdvanceTick()
+		hub.BroadcastState(gameState)
+		receiveState(t, client)
+	}
+	gameState.AdvanceTick()
+	hub.BroadcastState(gameState)
+	state := receiveState(t, client)
+
+	// Assert
+	if state.B != 0 || len(state.P) != 1 {
+		t.Errorf("state with stale baseline = %+v, want full snapshot", state)
+	}
+}
+
+// TestAckState_IgnoresOlderAcks verifies out-of-order acks don't move the
+// baseline backwards.
+func TestAckState_IgnoresOlderAcks(t *testing.T) {
+	// Arrange
+	var history snapshotHistory
+	history.record(newTestSnapshot(5))
+	history.record(newTestSnapshot(6))
+
+	// Act
+	history.ack(6)
+	history.ack(5)
+
+	// Assert
+	if base := history.baseline(); base == nil || base.tick != 6 {
+		t.Errorf("baseline() = %+v, want tick 6", base)
+	}
+}
@@ -0,0 +1,245 @@
+package network
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// TimeSyncRounds is the number of time_sync round trips a client should
+// make before trusting its clock estimate. More rounds let EstimateClock
+// discard the ones delayed by queueing and measure drift.
+const TimeSyncRounds = 8
+
+// ErrNoTimeSyncSamples is returned when estimating a clock from no samples.
+var ErrNoTimeSyncSamples = errors.New("no time sync samples")
+
+// TimeSyncSample is one completed time_sync round trip, in the NTP naming:
+// the client sent the request at T0 (client clock), the server received it
+// at T1 and replied at T2 (server clock), and the client received the reply
+// at T3 (client clock). All times are milliseconds since the Unix epoch.
+type TimeSyncSample struct {
+	T0, T1, T2, T3 int64
+}
+
+// Offset returns how far the server clock is ahead of the client clock,
+// assuming the request and reply took equally long.
+func (s TimeSyncSample) Offset() time.Duration {
+	return time.Duration((s.T1-s.T0)+(s.T2-s.T3)) * time.Millisecond / 2
+}
+
+// Delay returns the round-trip network delay, excluding the time the
+// server held the request.
+func (s TimeSyncSample) Delay() time.Duration {
+	return time.Duration((s.T3-s.T0)-(s.T2-s.T1)) * time.Millisecond
+}
+
+// ClockEstimate maps a client clock onto the server clock.
+// The server clock is Offset ahead of the client clock at client time Ref,
+// and gains Drift on it per unit of time (e.g. 0.0001 is 100ppm fast).
+type ClockEstimate struct {
+	// Offset is the server clock minus the client clock at Ref.
+	Offset time.Duration
+
+	// Drift is the rate the offset changes, in seconds per second.
+	Drift float64
+
+	// Ref is the client time (ms since Unix epoch) Offset was measured at.
+	Ref int64
+
+	// Delay is the smallest round-trip delay among the samples used.
+	Delay time.Duration
+}
+
+// EstimateClock estimates the server clock from several time_sync round trips.
+// Samples with a long round trip were probably queued on one leg, which
+// skews their offset, so only the fastest half is used. The offset is their
+// average and the drift the least-squares slope of offset against time.
+//
+// Parameters:
+//   - samples: Completed round trips, in any order
+//
+// Returns:
+//   - ClockEstimate: Offset and drift, referenced to the latest sample used
+//   - error: ErrNoTimeSyncSamples if samples is empty
+func EstimateClock(samples []TimeSyncSample) (ClockEstimate, error) {
+	if len(samples) == 0 {
+		return ClockEstimate{}, ErrNoTimeSyncSamples
+	}
+
+	best := append([]TimeSyncSample(nil), samples...)
+	sort.Slice(best, func(i, j int) bool { return best[i].Delay() < best[j].Delay() })
+	best = best[:(len(best)+1)/2]
+
+	// Reference the estimate to the newest sample's midpoint (client clock)
+	ref := best[0].midpoint()
+	for _, sample := range best[1:] {
+		ref = math.Max(ref, sample.midpoint())
+	}
+
+	// Least-squares fit of offset (ms) against client time (ms since ref)
+	var sumT, sumO, sumTT, sumTO float64
+	for _, sample := range best {
+		t := sample.midpoint() - ref
+		o := float64(sample.Offset()) / float64(time.Millisecond)
+		sumT += t
+		sumO += o
+		sumTT += t * t
+		sumTO += t * o
+	}
+	n := float64(len(best))
+	meanT, meanO := sumT/n, sumO/n
+
+	drift := 0.0
+	if variance := sumTT/n - meanT*meanT; variance > 0 {
+		drift = (sumTO/n - meanT*meanO) / variance
+	}
+	offset := meanO - drift*meanT // The fitted offset at ref
+
+	return ClockEstimate{
+		Offset: time.Duration(offset * float64(time.Millisecond)),
+		Drift:  drift,
+		Ref:    int64(math.Round(ref)),
+		Delay:  best[0].Delay(),
+	}, nil
+}
+
+// midpoint returns the client time halfway through the round trip, when
+// the offset measurement applies.
+func (s TimeSyncSample) midpoint() float64 {
+	return float64(s.T0+s.T3) / 2
+}
+
+// ServerTime converts a client time to server time.
+//
+// Parameters:
+//   - clientTime: Client clock reading (ms since Unix epoch)
+//
+// Returns:
+//   - float64: The estimated server clock reading (ms since Unix epoch)
+func (e ClockEstimate) ServerTime(clientTime int64) float64 {
+	offset := float64(e.Offset)/float64(time.Millisecond) + e.Drift*float64(clientTime-e.Ref)
+	return float64(clientTime) + offset
+}
+
+// Sample completes a round trip with the time the reply arrived.
+//
+// Parameters:
+//   - t3: When the client received the reply (client clock, ms since Unix epoch)
+//
+// Returns:
+//   - TimeSyncSample: The round trip
+func (r TimeSyncReply) Sample(t3 int64) TimeSyncSample {
+	return TimeSyncSample{T0: r.T0, T1: r.T1, T2: r.T2, T3: t3}
+}
+
+// TickAt returns the server tick in progress at a server time, with the
+// fraction of the tick elapsed, extrapolated from the reply's tick K.
+//
+// Parameters:
+//   - serverTime: Server clock reading (ms since Unix epoch)
+//
+// Returns:
+//   - float64: The fractional tick number
+func (r TimeSyncReply) TickAt(serverTime float64) float64 {
+	return float64(r.K) + (serverTime-float64(r.KT))*float64(r.R)/1000
+}
+
+// clockEstimator estimates how far the server clock is ahead of a client's
+// from the time_sync requests the server receives. Each request's lead (the
+// server receive time minus the client send time) is that offset plus the
+// request's travel time. The smallest of the last TimeSyncRounds leads was
+// queued least; taking half the round trip off it leaves the offset.
+type clockEstimator struct {
+	// leads is a ring of the most recent request leads.
+	leads [TimeSyncRounds]time.Duration
+
+	// next is the ring index the next lead is written to.
+	next int
+
+	// count is the number of leads recorded, up to TimeSyncRounds.
+	count int
+}
+
+// add records the lead of one time_sync request.
+func (e *clockEstimator) add(lead time.Duration) {
+	e.leads[e.next] = lead
+	e.next = (e.next + 1) % TimeSyncRounds
+	if e.count < TimeSyncRounds {
+		e.count++
+	}
+}
+
+// minLead returns the smallest recorded lead, or false if there is none.
+func (e *clockEstimator) minLead() (time.Duration, bool) {
+	if e.count == 0 {
+		return 0, false
+	}
+	lead := e.leads[0]
+	for _, l := range e.leads[1:e.count] {
+		if l < lead {
+			lead = l
+		}
+	}
+	return lead, true
+}
+
+// RecordTimeSync takes a clock sample from a client's time_sync request.
+//
+// Parameters:
+//   - playerID: The client's player ID
+//   - t0: When the client sent the request (client clock, ms since Unix epoch)
+//   - receivedAt: When the server received it
+func (h *ClientHub) RecordTimeSync(playerID int, t0 int64, receivedAt time.Time) {
+	lead := receivedAt.Sub(time.UnixMilli(t0))
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if client, exists := h.clients[playerID]; exists {
+		client.mu.Lock()
+		client.clock.add(lead)
+		client.mu.Unlock()
+	}
+}
+
+// ClockOffset returns how far the server clock is ahead of a client's,
+// from its time_sync requests and its smoothed round-trip time.
+//
+// Parameters:
+//   - playerID: The client's player ID
+//
+// Returns:
+//   - time.Duration: The server clock minus the client clock
+//   - bool: False if the client isn't connected, hasn't sent time_sync
+//     or hasn't answered a ping yet
+func (h *ClientHub) ClockOffset(playerID int) (time.Duration, bool) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	client, exists := h.clients[playerID]
+	if !exists {
+		return 0, false
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	lead, ok := client.clock.minLead()
+	if !ok || client.rtt.samples == 0 {
+		return 0, false
+	}
+	return lead - client.rtt.rtt/2, true
+}
+
+// SendTimeSync answers a client's time_sync request. The reply is stamped
+// as sent (T2) when it is queued; any wait in the send queue counts as
+// network delay.
+//
+// Parameters:
+//   - playerID: The client's player ID
+//   - reply: The reply with T0, T1 and the tick fields set
+func (h *ClientHub) SendTimeSync(playerID int, reply TimeSyncReply) {
+	reply.T2 = time.Now().UnixMilli()
+	h.sendToClient(playerID, Message{E: "time_sync", D: reply})
+}
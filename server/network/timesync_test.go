@@ -0,0 +1,152 @@
+package network
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+	"vibe-runner-server/game"
+)
+
+// TestTimeSyncSample_OffsetAndDelay verifies the NTP offset and delay of a
+// round trip with equal 40ms legs and a server 1s ahead.
+func TestTimeSyncSample_OffsetAndDelay(t *testing.T) {
+	// Arrange: the server holds the request for 2ms
+	sample := TimeSyncSample{T0: 0, T1: 1040, T2: 1042, T3: 82}
+
+	// Act
+	offset, delay := sample.Offset(), sample.Delay()
+
+	// Assert
+	if offset != time.Second {
+		t.Errorf("Offset() = %s, want 1s", offset)
+	}
+	if delay != 80*time.Millisecond {
+		t.Errorf("Delay() = %s, want 80ms", delay)
+	}
+}
+
+// TestEstimateClock_IgnoresQueuedSamples verifies round trips slowed down on
+// one leg don't skew the offset.
+func TestEstimateClock_IgnoresQueuedSamples(t *testing.T) {
+	// Arrange: the server is 1s ahead; two replies waited 300ms in a queue
+	var samples []TimeSyncSample
+	for i := int64(0); i < 6; i++ {
+		t0 := i * 100
+		replyLeg := int64(20)
+		if i == 2 || i == 4 {
+			replyLeg = 320
+		}
+		samples = append(samples, TimeSyncSample{T0: t0, T1: t0 + 1020, T2: t0 + 1020, T3: t0 + 20 + replyLeg})
+	}
+
+	// Act
+	estimate, err := EstimateClock(samples)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("EstimateClock() error = %v", err)
+	}
+	if estimate.Offset != time.Second {
+		t.Errorf("Offset = %s, want 1s", estimate.Offset)
+	}
+	if estimate.Delay != 40*time.Millisecond {
+		t.Errorf("Delay = %s, want 40ms", estimate.Delay)
+	}
+}
+
+// TestEstimateClock_MeasuresDrift verifies a server clock running fast is
+// extrapolated beyond the samples.
+func TestEstimateClock_MeasuresDrift(t *testing.T) {
+	// Arrange: the server clock is 500ms ahead at client time 0 and gains 2ms per second
+	const drift = 0.002
+	serverAt := func(clientTime int64) int64 {
+		return int64(math.Round(float64(clientTime) + 500 + drift*float64(clientTime)))
+	}
+	var samples []TimeSyncSample
+	for t0 := int64(0); t0 <= 10000; t0 += 1000 {
+		received := serverAt(t0 + 10)
+		samples = append(samples, TimeSyncSample{T0: t0, T1: received, T2: received, T3: t0 + 20})
+	}
+
+	// Act
+	estimate, err := EstimateClock(samples)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("EstimateClock() error = %v", err)
+	}
+	if math.Abs(estimate.Drift-drift) > 0.0002 {
+		t.Errorf("Drift = %g, want %g", estimate.Drift, drift)
+	}
+	if got, want := estimate.ServerTime(30000), float64(serverAt(30000)); math.Abs(got-want) > 5 {
+		t.Errorf("ServerTime(30000) = %.1f, want %.0f", got, want)
+	}
+}
+
+// TestEstimateClock_NoSamples verifies an empty sample set is an error.
+func TestEstimateClock_NoSamples(t *testing.T) {
+	if _, err := EstimateClock(nil); !errors.Is(err, ErrNoTimeSyncSamples) {
+		t.Errorf("EstimateClock(nil) error = %v, want ErrNoTimeSyncSamples", err)
+	}
+}
+
+// TestTimeSyncReply_TickAt verifies ticks are extrapolated from the reply's
+// tick time at the tick rate.
+func TestTimeSyncReply_TickAt(t *testing.T) {
+	reply := TimeSyncReply{K: 100, KT: 1000, R: 20}
+
+	if got := reply.TickAt(1075); got != 101.5 {
+		t.Errorf("TickAt(1075) = %g, want 101.5", got)
+	}
+}
+
+// TestBuildTimeSyncReply_ReportsTick verifies the reply carries the receive
+// time, the world's tick and its tick time and rate.
+func TestBuildTimeSyncReply_ReportsTick(t *testing.T) {
+	// Arrange
+	world := game.NewWorld(nil)
+	tickAt := time.UnixMilli(1700000000950)
+	world.State.AdvanceTickAt(tickAt)
+	receivedAt := time.UnixMilli(1700000001000)
+
+	// Act
+	reply := buildTimeSyncReply(1699999999000, receivedAt, world)
+
+	// Assert
+	want := TimeSyncReply{T0: 1699999999000, T1: 1700000001000, K: 1, KT: 1700000000950, R: world.Config.TickRate}
+	if reply != want {
+		t.Errorf("buildTimeSyncReply() = %+v, want %+v", reply, want)
+	}
+}
+
+// TestClockOffset_UsesFastestRequest verifies the server's estimate of a
+// client clock ignores requests delayed in a queue.
+func TestClockOffset_UsesFastestRequest(t *testing.T) {
+	// Arrange: the server is 1s ahead with a 60ms round trip; two requests
+	// waited 200ms in a queue
+	hub := NewClientHub()
+	hub.mu.Lock()
+	hub.clients[1] = &ClientConnection{PlayerID: 1, SendChan: make(chan []byte, 10)}
+	hub.mu.Unlock()
+	receivedAt := time.UnixMilli(1700000001000)
+	hub.RecordPong(1, string(pingPayload(receivedAt.Add(-60*time.Millisecond))), receivedAt)
+	for i := 0; i < TimeSyncRounds; i++ {
+		travel := 30 * time.Millisecond
+		if i == 2 || i == 5 {
+			travel += 200 * time.Millisecond
+		}
+		hub.RecordTimeSync(1, receivedAt.Add(-time.Second-travel).UnixMilli(), receivedAt)
+	}
+
+	// Act
+	offset, ok := hub.ClockOffset(1)
+
+	// Assert
+	if !ok {
+		t.Fatal("ClockOffset() ok = false, want true")
+	}
+	if offset != time.Second {
+		t.Errorf("ClockOffset() = %s, want 1s", offset)
+	}
+}
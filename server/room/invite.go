@@ -0,0 +1,59 @@
+package room
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+)
+
+const (
+	// InviteCodeLength is the number of characters in a private room invite code.
+	InviteCodeLength = 6
+
+	// inviteCodeAlphabet excludes look-alike characters (0/O, 1/I) so codes
+	// can be read aloud or copied by hand. 32 symbols keeps sampling unbiased.
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+	// privateRoomPrefix prefixes the IDs of private rooms ("private-K7M2QX").
+	privateRoomPrefix = "private-"
+)
+
+// newInviteCode generates a random invite code using crypto/rand.
+//
+// Returns:
+//   - string: InviteCodeLength characters from inviteCodeAlphabet
+//   - error: Non-nil if the system random source fails
+func newInviteCode() (string, error) {
+	buf := make([]byte, InviteCodeLength)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate invite code: %w", err)
+	}
+
+	for i, b := range buf {
+		buf[i] = inviteCodeAlphabet[int(b)%len(inviteCodeAlphabet)]
+	}
+	return string(buf), nil
+}
+
+// normalizeInviteCode trims and upper-cases a client-supplied invite code.
+//
+// Returns:
+//   - string: The normalized code
+//   - bool: False if the code is not a well-formed invite code
+func normalizeInviteCode(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	if len(code) != InviteCodeLength {
+		return "", false
+	}
+	for _, c := range code {
+		if !strings.ContainsRune(inviteCodeAlphabet, c) {
+			return "", false
+		}
+	}
+	return code, true
+}
+
+// privateRoomID returns the room ID of the private room with an invite code.
+func privateRoomID(code string) string {
+	return privateRoomPrefix + code
+}
@@ -0,0 +1,352 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"vibe-runner-server/leaderboard"
+	"vibe-runner-server/network"
+)
+
+const (
+	// DefaultRoomCapacity is the maximum number of clients in one room.
+	DefaultRoomCapacity = 500
+
+	// MaxRoomIDLength is the maximum length of a client-chosen room ID.
+	MaxRoomIDLength = 32
+)
+
+var (
+	// ErrRoomFull is returned when a client asks for a room at capacity.
+	ErrRoomFull = errors.New("room is full")
+
+	// ErrInvalidRoomID is returned when a client-chosen room ID is malformed.
+	ErrInvalidRoomID = errors.New("invalid room id")
+
+	// ErrRoomNotFound is returned when an invite code doesn't match a private room.
+	ErrRoomNotFound = errors.New("room not found")
+
+	// ErrShuttingDown is returned when a client joins after Shutdown.
+	// It is network.ErrServerShuttingDown, so HandleClient can tell clients.
+	ErrShuttingDown = network.ErrServerShuttingDown
+)
+
+// Manager must satisfy network.RoomProvider so HandleClient can use it.
+var _ network.RoomProvider = (*Manager)(nil)
+
+// Manager creates rooms on demand and tears down rooms once they are empty.
+// It implements network.RoomProvider.
+//
+// The manager is thread-safe and can be accessed from multiple client goroutines.
+type Manager struct {
+	// rooms maps room ID to running room.
+	rooms map[string]*Room
+
+	// capacity is the maximum number of clients per room.
+	capacity int
+
+	// config is the world and hub settings of every new room.
+	config Config
+
+	// scores records final scores from every room and feeds the live leaderboard (nil to disable).
+	scores leaderboard.Leaderboard
+
+	// sessions holds the resumable sessions of every room's clients.
+	sessions *network.SessionRegistry
+
+	// ctx is the parent context of every room's ticker.
+	ctx context.Context
+
+	// nextAutoID is used to name auto-matched rooms ("public-1", "public-2", ...).
+	nextAutoID int
+
+	// shuttingDown rejects new joins once Shutdown has been called.
+	shuttingDown bool
+
+	// mu protects rooms, nextAutoID, shuttingDown and each room's member count.
+	mu sync.Mutex
+}
+
+// NewManager creates an empty room manager.
+//
+// Parameters:
+//   - ctx: Parent context for room tickers; cancelling it stops every room's game loop
+//   - config: Settings for every room's world and hub (see DefaultConfig)
+//   - scores: Leaderboard shared by all rooms (nil to disable)
+//
+// Returns:
+//   - *Manager: Manager with no rooms, using DefaultRoomCapacity
+func NewManager(ctx context.Context, config Config, scores leaderboard.Leaderboard) *Manager {
+	return &Manager{
+		rooms:    make(map[string]*Room),
+		capacity: DefaultRoomCapacity,
+		config:   config,
+		scores:   scores,
+		sessions: network.NewSessionRegistry(),
+		ctx:      ctx,
+	}
+}
+
+// JoinRoom assigns a client to a room and returns that room's world and hub.
+//
+// The room is chosen from the request in this order:
+//  1. CreatePrivate: a new private room with a fresh invite code
+//  2. InviteCode: the existing private room with that code
+//  3. RoomID: that public room, created if it doesn't exist
+//  4. Otherwise: auto-match into the fullest public room with space,
+//     creating a new one if all are full
+//
+// Parameters:
+//   - request: Which room the client wants
+//
+// Returns:
+//   - *network.RoomAssignment: The assigned room's ID, invite code, world and hub
+//   - error: ErrInvalidRoomID, ErrRoomNotFound, ErrRoomFull or ErrShuttingDown if the client cannot join
+func (m *Manager) JoinRoom(request network.RoomRequest) (*network.RoomAssignment, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.shuttingDown {
+		return nil, ErrShuttingDown
+	}
+
+	var room *Room
+	switch {
+	case request.CreatePrivate:
+		var err error
+		room, err = m.createPrivateLocked()
+		if err != nil {
+			return nil, err
+		}
+
+	case request.InviteCode != "":
+		code, ok := normalizeInviteCode(request.InviteCode)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrRoomNotFound, request.InviteCode)
+		}
+		room = m.rooms[privateRoomID(code)]
+		if room == nil {
+			return nil, fmt.Errorf("%w: %s", ErrRoomNotFound, code)
+		}
+
+	case request.RoomID != "":
+		if !validRoomID(request.RoomID) {
+			return nil, ErrInvalidRoomID
+		}
+		room = m.rooms[request.RoomID]
+		if room == nil {
+			room = m.createLocked(request.RoomID)
+		}
+
+	default:
+		room = m.autoMatchLocked()
+	}
+
+	if room.members >= m.capacity {
+		return nil, fmt.Errorf("%w: %s", ErrRoomFull, room.ID)
+	}
+
+	room.members++
+	return &network.RoomAssignment{
+		RoomID:     room.ID,
+		InviteCode: room.InviteCode,
+		World:      room.World,
+		Hub:        room.Hub,
+	}, nil
+}
+
+// LeaveRoom releases a client's slot in a room.
+// When the last client leaves, the room's ticker is stopped and the room is removed.
+//
+// Parameters:
+//   - roomID: The room the client was assigned to by JoinRoom
+func (m *Manager) LeaveRoom(roomID string) {
+	m.mu.Lock()
+	room, exists := m.rooms[roomID]
+	if !exists {
+		m.mu.Unlock()
+		return
+	}
+
+	room.members--
+	if room.members > 0 {
+		m.mu.Unlock()
+		return
+	}
+	delete(m.rooms, roomID)
+	activeRooms := len(m.rooms)
+	m.mu.Unlock()
+
+	// Waiting for the ticker to exit mustn't block other joins
+	room.stop()
+	log.Printf("Room %s torn down (empty), Active rooms: %d", roomID, activeRooms)
+}
+
+// HeartbeatTimeout returns how long a client may stay silent before its
+// connection is dropped, from the rooms' heartbeat settings. It applies to
+// connections that haven't joined a room yet.
+//
+// Returns:
+//   - time.Duration: The timeout, or 0 if heartbeats are disabled
+func (m *Manager) HeartbeatTimeout() time.Duration {
+	return m.config.PingInterval * time.Duration(m.config.MaxMissedPings)
+}
+
+// Sessions returns the registry of every room's resumable sessions, so a
+// client can resume its player whichever room it is in.
+//
+// Returns:
+//   - *network.SessionRegistry: The manager's session registry
+func (m *Manager) Sessions() *network.SessionRegistry {
+	return m.sessions
+}
+
+// Shutdown stops every room for a server shutdown.
+// New joins are rejected from now on. Each room's ticker is stopped, the
+// scores of players still racing are recorded, and every client is sent
+// server_shutdown and a close frame. Rooms are removed as their clients
+// disconnect, as usual.
+//
+// Parameters:
+//   - ctx: Bounds how long to wait for clients to be notified
+//
+// Returns:
+//   - error: Non-nil if some rooms' clients weren't notified before ctx ended
+func (m *Manager) Shutdown(ctx context.Context) error {
+	m.mu.Lock()
+	m.shuttingDown = true
+	rooms := make([]*Room, 0, len(m.rooms))
+	for _, room := range m.rooms {
+		rooms = append(rooms, room)
+	}
+	m.mu.Unlock()
+
+	for _, room := range rooms {
+		room.stop()
+	}
+
+	var errs []error
+	for _, room := range rooms {
+		if err := room.shutdown(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("room %s: %w", room.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Room returns a running room by ID, or nil if it doesn't exist.
+func (m *Manager) Room(roomID string) *Room {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.rooms[roomID]
+}
+
+// RoomIDs returns the IDs of all running rooms, sorted.
+func (m *Manager) RoomIDs() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ids := make([]string, 0, len(m.rooms))
+	for id := range m.rooms {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// RoomCount returns the number of running rooms.
+func (m *Manager) RoomCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.rooms)
+}
+
+// autoMatchLocked returns the fullest auto-matched public room that still has space,
+// creating a new one if none do. Filling rooms first keeps races lively.
+// The caller must hold m.mu.
+func (m *Manager) autoMatchLocked() *Room {
+	var best *Room
+	for id, room := range m.rooms {
+		if !isPublicRoomID(id) || room.members >= m.capacity {
+			continue
+		}
+		if best == nil || room.members > best.members {
+			best = room
+		}
+	}
+
+	if best != nil {
+		return best
+	}
+
+	// Skip IDs already taken (a client may have named a room "public-N")
+	for {
+		m.nextAutoID++
+		roomID := fmt.Sprintf("%s%d", publicRoomPrefix, m.nextAutoID)
+		if _, taken := m.rooms[roomID]; !taken {
+			return m.createLocked(roomID)
+		}
+	}
+}
+
+// createPrivateLocked creates a private room under a fresh, unused invite code.
+// The caller must hold m.mu.
+func (m *Manager) createPrivateLocked() (*Room, error) {
+	for {
+		code, err := newInviteCode()
+		if err != nil {
+			return nil, err
+		}
+		roomID := privateRoomID(code)
+		if _, taken := m.rooms[roomID]; taken {
+			continue
+		}
+
+		room := m.createLocked(roomID)
+		room.InviteCode = code
+		return room, nil
+	}
+}
+
+// createLocked creates, registers and starts a new room.
+// The caller must hold m.mu.
+func (m *Manager) createLocked(roomID string) *Room {
+	room := newRoom(roomID, m.config, m.scores)
+	room.start(m.ctx)
+	m.rooms[roomID] = room
+	log.Printf("Room %s created, Active rooms: %d", roomID, len(m.rooms))
+	return room
+}
+
+// publicRoomPrefix prefixes the IDs of auto-matched rooms.
+const publicRoomPrefix = "public-"
+
+// isPublicRoomID reports whether a room was created by auto-matching.
+func isPublicRoomID(roomID string) bool {
+	return strings.HasPrefix(roomID, publicRoomPrefix)
+}
+
+// validRoomID reports whether a client-chosen room ID is acceptable.
+// IDs must be 1-32 characters of letters, digits, '-' or '_', and must not
+// use the private room prefix (private rooms are only reachable by invite code).
+func validRoomID(roomID string) bool {
+	if len(roomID) == 0 || len(roomID) > MaxRoomIDLength {
+		return false
+	}
+	if strings.HasPrefix(roomID, privateRoomPrefix) {
+		return false
+	}
+	for _, c := range roomID {
+		isLetter := (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+		isDigit := c >= '0' && c <= '9'
+		if !isLetter && !isDigit && c != '-' && c != '_' {
+			return false
+		}
+	}
+	return true
+}
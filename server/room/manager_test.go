@@ -0,0 +1,394 @@
+package room
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"vibe-runner-server/generation"
+	"vibe-runner-server/network"
+)
+
+// TestJoinRoom_AutoMatch_CreatesAndReusesPublicRoom verifies that clients
+// without a room are placed together in one public room.
+func TestJoinRoom_AutoMatch_CreatesAndReusesPublicRoom(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+
+	// Act
+	first, err := manager.JoinRoom(network.RoomRequest{})
+	if err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+	defer manager.LeaveRoom(first.RoomID)
+	second, err := manager.JoinRoom(network.RoomRequest{})
+	if err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+	defer manager.LeaveRoom(second.RoomID)
+
+	// Assert
+	if first.RoomID != second.RoomID {
+		t.Errorf("auto-matched rooms = %q and %q, want same room", first.RoomID, second.RoomID)
+	}
+	if first.World != second.World || first.Hub != second.Hub {
+		t.Error("auto-matched clients received different worlds or hubs")
+	}
+	if manager.RoomCount() != 1 {
+		t.Errorf("RoomCount() = %d, want 1", manager.RoomCount())
+	}
+}
+
+// TestJoinRoom_NamedRooms_AreIndependent verifies that separate rooms have
+// separate state, hubs and seeds.
+func TestJoinRoom_NamedRooms_AreIndependent(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+
+	// Act
+	roomA, err := manager.JoinRoom(network.RoomRequest{RoomID: "race-a"})
+	if err != nil {
+		t.Fatalf("JoinRoom(race-a) error = %v", err)
+	}
+	defer manager.LeaveRoom("race-a")
+	roomB, err := manager.JoinRoom(network.RoomRequest{RoomID: "race-b"})
+	if err != nil {
+		t.Fatalf("JoinRoom(race-b) error = %v", err)
+	}
+	defer manager.LeaveRoom("race-b")
+
+	// Assert
+	if roomA.World == roomB.World || roomA.World.State == roomB.World.State {
+		t.Error("named rooms share a world")
+	}
+	if roomA.Hub == roomB.Hub {
+		t.Error("named rooms share a hub")
+	}
+	if roomA.World.Seed == roomB.World.Seed {
+		t.Errorf("named rooms share seed %q", roomA.World.Seed)
+	}
+	if roomA.World.Seed != roomA.World.Chunks.Seed() {
+		t.Errorf("room world seed %q does not match chunk manager seed %q", roomA.World.Seed, roomA.World.Chunks.Seed())
+	}
+	if roomA.InviteCode != "" {
+		t.Errorf("public room has invite code %q", roomA.InviteCode)
+	}
+}
+
+// TestLeaveRoom_LastMember_TearsDownRoom verifies empty rooms are removed.
+func TestLeaveRoom_LastMember_TearsDownRoom(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+	manager.JoinRoom(network.RoomRequest{RoomID: "race"})
+	manager.JoinRoom(network.RoomRequest{RoomID: "race"})
+
+	// Act & Assert
+	manager.LeaveRoom("race")
+	if manager.Room("race") == nil {
+		t.Fatal("LeaveRoom() tore down room with a remaining member")
+	}
+
+	manager.LeaveRoom("race")
+	if manager.Room("race") != nil {
+		t.Error("LeaveRoom() did not tear down empty room")
+	}
+	if manager.RoomCount() != 0 {
+		t.Errorf("RoomCount() = %d, want 0", manager.RoomCount())
+	}
+}
+
+// TestLeaveRoom_SlowStop_DoesNotBlockJoins verifies a room waiting for its
+// ticker to exit doesn't hold up joins to other rooms.
+func TestLeaveRoom_SlowStop_DoesNotBlockJoins(t *testing.T) {
+	// Arrange: the room's ticker takes until release to exit
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+	manager.JoinRoom(network.RoomRequest{RoomID: "race"})
+	room := manager.Room("race")
+	room.stop()
+	release := make(chan struct{})
+	stopping := make(chan struct{})
+	room.stopTicker = func() {
+		close(stopping)
+		<-release
+	}
+	left := make(chan struct{})
+	go func() {
+		manager.LeaveRoom("race")
+		close(left)
+	}()
+	<-stopping
+
+	// Act
+	joined := make(chan error, 1)
+	go func() {
+		_, err := manager.JoinRoom(network.RoomRequest{RoomID: "other"})
+		joined <- err
+	}()
+
+	// Assert
+	select {
+	case err := <-joined:
+		if err != nil {
+			t.Errorf("JoinRoom() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("JoinRoom() blocked while another room was stopping")
+	}
+	if manager.Room("race") != nil {
+		t.Error("stopping room still registered")
+	}
+	close(release)
+	<-left
+}
+
+// TestJoinRoom_Full_ReturnsErrRoomFull verifies room capacity is enforced
+// and that auto-matching overflows into a new public room.
+func TestJoinRoom_Full_ReturnsErrRoomFull(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+	manager.capacity = 1
+	first, _ := manager.JoinRoom(network.RoomRequest{})
+	defer manager.LeaveRoom(first.RoomID)
+	manager.JoinRoom(network.RoomRequest{RoomID: "tiny"})
+	defer manager.LeaveRoom("tiny")
+
+	// Act
+	_, err := manager.JoinRoom(network.RoomRequest{RoomID: "tiny"})
+	overflow, overflowErr := manager.JoinRoom(network.RoomRequest{})
+
+	// Assert
+	if !errors.Is(err, ErrRoomFull) {
+		t.Errorf("JoinRoom(full) error = %v, want ErrRoomFull", err)
+	}
+	if overflowErr != nil {
+		t.Fatalf("JoinRoom() overflow error = %v", overflowErr)
+	}
+	defer manager.LeaveRoom(overflow.RoomID)
+	if overflow.RoomID == first.RoomID {
+		t.Errorf("auto-match placed client in full room %q", first.RoomID)
+	}
+}
+
+// TestJoinRoom_InvalidID verifies malformed room IDs are rejected.
+func TestJoinRoom_InvalidID(t *testing.T) {
+	tests := []struct {
+		name   string
+		roomID string
+	}{
+		{name: "spaces", roomID: "my room"},
+		{name: "markup", roomID: "<script>"},
+		{name: "too long", roomID: "abcdefghijabcdefghijabcdefghijabc"},
+		{name: "private prefix", roomID: "private-ABCDEF"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(context.Background(), DefaultConfig(), nil)
+
+			_, err := manager.JoinRoom(network.RoomRequest{RoomID: tt.roomID})
+
+			if !errors.Is(err, ErrInvalidRoomID) {
+				t.Errorf("JoinRoom(%q) error = %v, want ErrInvalidRoomID", tt.roomID, err)
+			}
+			if manager.RoomCount() != 0 {
+				t.Errorf("JoinRoom(%q) created a room", tt.roomID)
+			}
+		})
+	}
+}
+
+// TestJoinRoom_AutoMatch_SkipsClientNamedPublicID verifies auto-matching
+// never reuses an ID a client already claimed.
+func TestJoinRoom_AutoMatch_SkipsClientNamedPublicID(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+	manager.capacity = 1
+	manager.JoinRoom(network.RoomRequest{RoomID: "public-1"})
+	defer manager.LeaveRoom("public-1")
+
+	// Act
+	assignment, err := manager.JoinRoom(network.RoomRequest{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+	defer manager.LeaveRoom(assignment.RoomID)
+	if assignment.RoomID == "public-1" {
+		t.Error("auto-match reused client-claimed room ID")
+	}
+}
+
+// TestJoinRoom_CreatePrivate_FriendsJoinByCode verifies that a private room
+// is reachable by its invite code (case-insensitive) and shares one world.
+func TestJoinRoom_CreatePrivate_FriendsJoinByCode(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+
+	// Act
+	host, err := manager.JoinRoom(network.RoomRequest{CreatePrivate: true})
+	if err != nil {
+		t.Fatalf("JoinRoom(CreatePrivate) error = %v", err)
+	}
+	defer manager.LeaveRoom(host.RoomID)
+	friend, err := manager.JoinRoom(network.RoomRequest{InviteCode: " " + strings.ToLower(host.InviteCode) + " "})
+	if err != nil {
+		t.Fatalf("JoinRoom(InviteCode) error = %v", err)
+	}
+	defer manager.LeaveRoom(friend.RoomID)
+
+	// Assert
+	if len(host.InviteCode) != InviteCodeLength {
+		t.Errorf("invite code %q length = %d, want %d", host.InviteCode, len(host.InviteCode), InviteCodeLength)
+	}
+	if friend.RoomID != host.RoomID || friend.World != host.World {
+		t.Errorf("friend joined %q, want host room %q", friend.RoomID, host.RoomID)
+	}
+	if friend.World.Seed != host.World.Seed {
+		t.Errorf("friend seed = %q, want %q", friend.World.Seed, host.World.Seed)
+	}
+	if friend.InviteCode != host.InviteCode {
+		t.Errorf("friend invite code = %q, want %q", friend.InviteCode, host.InviteCode)
+	}
+}
+
+// TestJoinRoom_PrivateRoom_NotAutoMatched verifies strangers are never
+// auto-matched into a private room.
+func TestJoinRoom_PrivateRoom_NotAutoMatched(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+	host, _ := manager.JoinRoom(network.RoomRequest{CreatePrivate: true})
+	defer manager.LeaveRoom(host.RoomID)
+
+	// Act
+	stranger, err := manager.JoinRoom(network.RoomRequest{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+	defer manager.LeaveRoom(stranger.RoomID)
+	if stranger.RoomID == host.RoomID {
+		t.Error("auto-match placed stranger in private room")
+	}
+}
+
+// TestJoinRoom_UnknownInviteCode verifies unknown or malformed codes fail
+// without creating a room.
+func TestJoinRoom_UnknownInviteCode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{name: "well formed but unused", code: "ABCDEF"},
+		{name: "too short", code: "ABC"},
+		{name: "ambiguous characters", code: "O0I1AB"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(context.Background(), DefaultConfig(), nil)
+
+			_, err := manager.JoinRoom(network.RoomRequest{InviteCode: tt.code})
+
+			if !errors.Is(err, ErrRoomNotFound) {
+				t.Errorf("JoinRoom(code %q) error = %v, want ErrRoomNotFound", tt.code, err)
+			}
+			if manager.RoomCount() != 0 {
+				t.Errorf("JoinRoom(code %q) created a room", tt.code)
+			}
+		})
+	}
+}
+
+// TestNewInviteCode_UsesAlphabet verifies generated codes are well formed.
+func TestNewInviteCode_UsesAlphabet(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		code, err := newInviteCode()
+		if err != nil {
+			t.Fatalf("newInviteCode() error = %v", err)
+		}
+		if _, ok := normalizeInviteCode(code); !ok {
+			t.Fatalf("newInviteCode() = %q, not a valid invite code", code)
+		}
+	}
+}
+
+// TestShutdown_StopsRoomsAndRejectsJoins verifies a shut-down manager stops
+// its rooms' tickers and turns new clients away.
+func TestShutdown_StopsRoomsAndRejectsJoins(t *testing.T) {
+	// Arrange
+	manager := NewManager(context.Background(), DefaultConfig(), nil)
+	assignment, err := manager.JoinRoom(network.RoomRequest{RoomID: "friday-race"})
+	if err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+	defer manager.LeaveRoom(assignment.RoomID)
+
+	// Act
+	shutdownErr := manager.Shutdown(context.Background())
+	_, joinErr := manager.JoinRoom(network.RoomRequest{})
+
+	// Assert
+	if shutdownErr != nil {
+		t.Errorf("Shutdown() error = %v", shutdownErr)
+	}
+	if !errors.Is(joinErr, ErrShuttingDown) {
+		t.Errorf("JoinRoom() after Shutdown() error = %v, want ErrShuttingDown", joinErr)
+	}
+	if manager.Room("friday-race").stopTicker != nil {
+		t.Error("room ticker still running after Shutdown()")
+	}
+}
+
+// TestJoinRoom_AppliesConfig verifies new rooms are built with the manager's settings.
+func TestJoinRoom_AppliesConfig(t *testing.T) {
+	// Arrange
+	config := DefaultConfig()
+	config.World.TickRate = 30
+	config.World.ChunksAhead = 4
+	manager := NewManager(context.Background(), config, nil)
+
+	// Act
+	assignment, err := manager.JoinRoom(network.RoomRequest{})
+
+	// Assert
+	if err != nil {
+		t.Fatalf("JoinRoom() error = %v", err)
+	}
+	defer manager.LeaveRoom(assignment.RoomID)
+	if assignment.World.Config != config.World {
+		t.Errorf("world config = %+v, want %+v", assignment.World.Config, config.World)
+	}
+	chunks := assignment.World.Chunks.(*generation.ChunkManager)
+	if got := len(chunks.GetAllChunks()); got != config.World.ChunksAhead+1 {
+		t.Errorf("pre-generated %d chunks, want %d", got, config.World.ChunksAhead+1)
+	}
+}
+
+// TestHeartbeatTimeout_FollowsConfig verifies connections that haven't
+// joined get the configured heartbeat timeout, and none when disabled.
+func TestHeartbeatTimeout_FollowsConfig(t *testing.T) {
+	tests := []struct {
+		name         string
+		pingInterval time.Duration
+		want         time.Duration
+	}{
+		{name: "configured heartbeat", pingInterval: 2 * time.Second, want: 6 * time.Second},
+		{name: "heartbeats disabled", pingInterval: 0, want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := DefaultConfig()
+			config.PingInterval = tt.pingInterval
+			config.MaxMissedPings = 3
+			manager := NewManager(context.Background(), config, nil)
+
+			if got := manager.HeartbeatTimeout(); got != tt.want {
+				t.Errorf("HeartbeatTimeout() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+// Package room hosts independent game worlds ("rooms") in one server process.
+// Each room owns its own game state, client hub, chunk manager, seed and ticker,
+// so separate races never share players or level data.
+package room
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+	"vibe-runner-server/game"
+	"vibe-runner-server/generation"
+	"vibe-runner-server/leaderboard"
+	"vibe-runner-server/network"
+)
+
+// Room is a single running game world with its own ticker goroutine.
+type Room struct {
+	// ID is the unique room identifier clients use in the join message.
+	ID string
+
+	// InviteCode is the shareable code of a private room (empty for public rooms).
+	// Private rooms are never auto-matched and can only be joined with this code.
+	InviteCode string
+
+	// World holds the room's players, chunk manager, seed and generator version.
+	World *game.World
+
+	// Hub broadcasts state and chunks to the clients in this room only.
+	Hub *network.ClientHub
+
+	// members is the number of clients assigned to this room.
+	// Protected by the owning Manager's mutex.
+	members int
+
+	// stopTicker stops the room's game loop goroutine.
+	// Protected by stopMu.
+	stopTicker func()
+
+	// stopMu serializes stop, which runs outside the Manager's mutex.
+	stopMu sync.Mutex
+}
+
+// Config holds the settings every room is created with.
+type Config struct {
+	// World is the simulation config of each room's world.
+	World game.Config
+
+	// SendBuffer is the outgoing message queue size per client.
+	SendBuffer int
+
+	// InterestRadius is how far from its own player each client sees other
+	// players, in pixels (0 sends every player to every client).
+	InterestRadius float64
+
+	// PingInterval is how often each client is pinged (0 disables heartbeats).
+	PingInterval time.Duration
+
+	// MaxMissedPings is how many ping intervals a client may stay silent
+	// before it is disconnected.
+	MaxMissedPings int
+
+	// ResumeGrace is how long a disconnected client's player is held for the
+	// client to resume (0 removes players as soon as they disconnect).
+	ResumeGrace time.Duration
+}
+
+// DefaultConfig returns the default world settings, send buffer, interest radius, heartbeat and resume window.
+//
+// Returns:
+//   - Config: Default room configuration
+func DefaultConfig() Config {
+	return Config{
+		World:          game.DefaultConfig(),
+		SendBuffer:     network.DefaultSendBuffer,
+		InterestRadius: network.DefaultInterestRadius,
+		PingInterval:   network.DefaultPingInterval,
+		MaxMissedPings: network.DefaultMaxMissedPings,
+		ResumeGrace:    network.DefaultResumeGrace,
+	}
+}
+
+// newRoom creates a room with a fresh seed and pre-generated initial chunks.
+// The room's ticker is not started until start is called.
+//
+// Parameters:
+//   - id: Unique room identifier
+//   - config: World and hub settings
+//   - scores: Leaderboard for deaths, finishes and the live push (nil to disable)
+//
+// Returns:
+//   - *Room: New room ready to start
+func newRoom(id string, config Config, scores leaderboard.Leaderboard) *Room {
+	// Each room gets its own seed so separate races have separate levels
+	seed := fmt.Sprintf("vibe-runner-%s-%d", id, time.Now().UnixNano())
+
+	chunkManager := generation.NewChunkManager(seed)
+
+	// Pre-generate the chunks new players are sent (0, 1, 2 by default) so they're ready immediately
+	chunkManager.GenerateAheadForPlayer(game.SpawnX, config.World.ChunksAhead)
+
+	world := game.NewWorldWithConfig(chunkManager, config.World)
+	hub := network.NewClientHub()
+	hub.SetSendBuffer(config.SendBuffer)
+	hub.SetInterestRadius(config.InterestRadius)
+	hub.SetHeartbeat(config.PingInterval, config.MaxMissedPings)
+	hub.SetResumeGrace(config.ResumeGrace)
+	if scores != nil {
+		world.Scores = scores
+		hub.SetLeaderboard(scores)
+	}
+
+	return &Room{
+		ID:    id,
+		World: world,
+		Hub:   hub,
+	}
+}
+
+// start launches the room's game ticker.
+//
+// Parameters:
+//   - ctx: Stops the ticker when cancelled
+func (r *Room) start(ctx context.Context) {
+	r.stopTicker = game.StartGameTicker(ctx, r.World, r.Hub)
+	log.Printf("Room %s started (seed=%s)", r.ID, r.World.Seed)
+}
+
+// shutdown finishes a room whose ticker has been stopped: the scores of
+// players still racing are recorded, and every client is told the server
+// is going away.
+//
+// Parameters:
+//   - ctx: Bounds how long to wait for clients to be notified
+//
+// Returns:
+//   - error: Non-nil if some clients weren't notified before ctx ended
+func (r *Room) shutdown(ctx context.Context) error {
+	if recorded := r.World.FlushScores(); recorded > 0 {
+		log.Printf("Room %s recorded %d in-progress scores", r.ID, recorded)
+	}
+	return r.Hub.Shutdown(ctx)
+}
+
+// stop halts the room's game ticker. Safe to call on a room that never started.
+// Blocks until the ticker goroutine has exited, so callers shouldn't hold
+// the Manager's mutex. Concurrent calls wait for the first to finish.
+func (r *Room) stop() {
+	r.stopMu.Lock()
+	defer r.stopMu.Unlock()
+
+	if r.stopTicker != nil {
+		r.stopTicker()
+		r.stopTicker = nil
+		log.Printf("Room %s stopped", r.ID)
+	}
+}